@@ -9,16 +9,33 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+	// the runtime image has no zoneinfo, and profile time zones are validated against it
+	_ "time/tzdata"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/citadel-corp/paimon-bank/internal/audit"
+	"github.com/citadel-corp/paimon-bank/internal/backoffice"
 	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	"github.com/citadel-corp/paimon-bank/internal/common/jwt"
+	"github.com/citadel-corp/paimon-bank/internal/common/keystore"
 	"github.com/citadel-corp/paimon-bank/internal/common/middleware"
+	"github.com/citadel-corp/paimon-bank/internal/common/rbac"
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
 	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/citadel-corp/paimon-bank/internal/common/revocation"
+	"github.com/citadel-corp/paimon-bank/internal/common/secretbox"
+	"github.com/citadel-corp/paimon-bank/internal/currency"
+	"github.com/citadel-corp/paimon-bank/internal/escrow"
 	"github.com/citadel-corp/paimon-bank/internal/image"
+	"github.com/citadel-corp/paimon-bank/internal/mailer"
+	paymentrequest "github.com/citadel-corp/paimon-bank/internal/payment_request"
+	"github.com/citadel-corp/paimon-bank/internal/payout"
+	splitbill "github.com/citadel-corp/paimon-bank/internal/split_bill"
 	"github.com/citadel-corp/paimon-bank/internal/user"
 	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
 	"github.com/gorilla/mux"
@@ -57,16 +74,165 @@ func main() {
 	// 	os.Exit(1)
 	// }
 
+	// access tokens are signed with asymmetric keys kept in the database and rotated on a schedule
+	signingAlgorithm := os.Getenv("JWT_ALGORITHM")
+	if signingAlgorithm == "" {
+		signingAlgorithm = jwt.AlgorithmEdDSA
+	}
+	keyRotationPeriod, err := time.ParseDuration(os.Getenv("JWT_KEY_ROTATION_PERIOD"))
+	if err != nil {
+		keyRotationPeriod = 30 * 24 * time.Hour
+	}
+	keyRotator, err := keystore.NewRotator(db, signingAlgorithm, keyRotationPeriod, os.Getenv("JWT_KEY_ENCRYPTION_SECRET"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot create signing key rotator: %v", err))
+		os.Exit(1)
+	}
+	err = keyRotator.Rotate(context.Background())
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot load signing keys: %v", err))
+		os.Exit(1)
+	}
+
+	// revoked access tokens are cached in memory and checked on every authorized request
+	revocationStore := revocation.NewStore(db)
+	err = revocationStore.Load(context.Background())
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot load token revocations: %v", err))
+		os.Exit(1)
+	}
+	middleware.UseRevocationChecker(revocationStore)
+
+	// initialize currency domain
+	currencyRepository := currency.NewRepository(db)
+	currencyService := currency.NewService(currencyRepository)
+	currencyHandler := currency.NewHandler(currencyService)
+
 	// initialize user domain
+	err = userbalance.UseChainKey(os.Getenv("CHAIN_HMAC_SECRET"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("CHAIN_HMAC_SECRET: %v", err))
+		os.Exit(1)
+	}
+	userBalanceRepository := userbalance.NewRepository(db)
+	mfaSecrets, err := secretbox.New(os.Getenv("MFA_ENCRYPTION_SECRET"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("MFA_ENCRYPTION_SECRET: %v", err))
+		os.Exit(1)
+	}
+	var userMailer mailer.Mailer
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "Paimon Bank <no-reply@paimon-bank.local>"
+	}
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		userMailer, err = mailer.NewSMTPMailer(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), mailFrom)
+		if err != nil {
+			slog.Error(fmt.Sprintf("MAIL_FROM: %v", err))
+			os.Exit(1)
+		}
+	case "file":
+		mailDir := os.Getenv("MAILER_DIR")
+		if mailDir == "" {
+			mailDir = "mail"
+		}
+		userMailer, err = mailer.NewFileMailer(mailDir, mailFrom)
+		if err != nil {
+			slog.Error(fmt.Sprintf("MAILER_DIR: %v", err))
+			os.Exit(1)
+		}
+	case "log":
+		userMailer = mailer.NewLogMailer()
+	default:
+		// there is no default: the log mailer would quietly write one-time tokens to production logs
+		slog.Error(fmt.Sprintf("MAILER: must be smtp, file or log, got %q", os.Getenv("MAILER")))
+		os.Exit(1)
+	}
 	userRepository := user.NewRepository(db)
-	userService := user.NewService(userRepository)
+	userService := user.NewService(userRepository, revocationStore, mfaSecrets, userMailer, currencyService, userBalanceRepository)
 	userHandler := user.NewHandler(userService)
 
+	// the first admin comes from the environment, further roles are assigned through the admin API
+	adminEmail := os.Getenv("ADMIN_EMAIL")
+	if adminEmail != "" {
+		granted, err := userService.GrantFirstAdmin(context.Background(), adminEmail)
+		if errors.Is(err, user.ErrUserNotFound) {
+			slog.Warn(fmt.Sprintf("ADMIN_EMAIL: no user registered as %s yet", adminEmail))
+		} else if err != nil {
+			slog.Error(fmt.Sprintf("Cannot grant the admin role: %v", err))
+			os.Exit(1)
+		} else if granted {
+			slog.Info(fmt.Sprintf("Granted the admin role to %s", adminEmail))
+		}
+	}
+
 	// initialize user balance domain
-	userBalanceRepository := userbalance.NewRepository(db)
-	userBalanceService := userbalance.NewService(userBalanceRepository)
+	err = userbalance.UseVirtualAccountPrefix(os.Getenv("VIRTUAL_ACCOUNT_PREFIX"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("VIRTUAL_ACCOUNT_PREFIX: %v", err))
+		os.Exit(1)
+	}
+	stepUpThresholds, err := userbalance.ParseStepUpThresholds(os.Getenv("STEP_UP_THRESHOLDS"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("STEP_UP_THRESHOLDS: %v", err))
+		os.Exit(1)
+	}
+	stepUp := userbalance.StepUpPolicy{
+		Thresholds: stepUpThresholds,
+		Verifier:   userService,
+	}
+	userBalanceService := userbalance.NewService(userBalanceRepository, currencyService, stepUp, userService)
 	userBalanceHandler := userbalance.NewHandler(userBalanceService)
 
+	// initialize payment request domain
+	paymentRequestRepository := paymentrequest.NewRepository(db)
+	paymentRequestService := paymentrequest.NewService(paymentRequestRepository, currencyService, userService, stepUp)
+	paymentRequestHandler := paymentrequest.NewHandler(paymentRequestService)
+
+	// initialize split bill domain
+	splitBillRepository := splitbill.NewRepository(db)
+	splitBillService := splitbill.NewService(splitBillRepository, currencyService, userService, stepUp)
+	splitBillHandler := splitbill.NewHandler(splitBillService)
+
+	// initialize escrow domain
+	escrowRepository := escrow.NewRepository(db)
+	escrowService := escrow.NewService(escrowRepository, currencyService, userService, stepUp)
+	escrowHandler := escrow.NewHandler(escrowService)
+
+	// process outgoing transfers in the background, unless they are paid out through pain.001 exports
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	if os.Getenv("PAYOUT_GATEWAY") != "pain001" {
+		settleAfter, err := time.ParseDuration(os.Getenv("PAYOUT_SIMULATOR_SETTLE_AFTER"))
+		if err != nil {
+			settleAfter = 10 * time.Second
+		}
+		failureRate, _ := strconv.ParseFloat(os.Getenv("PAYOUT_SIMULATOR_FAILURE_RATE"), 64)
+		payoutGateway := payout.NewSimulatorGateway(settleAfter, failureRate)
+		transferProcessor := userbalance.NewTransferProcessor(userBalanceRepository, payoutGateway, 5*time.Second)
+		go transferProcessor.Run(workerCtx)
+	}
+	escrowReleaser := escrow.NewReleaser(escrowService, time.Minute)
+	go escrowReleaser.Run(workerCtx)
+	go revocationStore.Run(workerCtx, 10*time.Second)
+	go keyRotator.Run(workerCtx, time.Minute)
+
+	// initialize audit domain
+	err = request.UseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("TRUSTED_PROXIES: %v", err))
+		os.Exit(1)
+	}
+	auditRepository := audit.NewRepository(db)
+	auditService := audit.NewService(auditRepository)
+	auditHandler := audit.NewHandler(auditService)
+
+	// initialize back office domain
+	backOfficeRepository := backoffice.NewRepository(db)
+	backOfficeService := backoffice.NewService(backOfficeRepository, userBalanceRepository)
+	backOfficeHandler := backoffice.NewHandler(backOfficeService)
+
 	// initialize image domain
 	sess, err := session.NewSession(&aws.Config{
 		Region:      aws.String("ap-southeast-1"),
@@ -81,10 +247,12 @@ func main() {
 
 	r := mux.NewRouter()
 	r.Use(middleware.Logging)
+	r.Use(audit.Recorder(auditService))
 	r.Use(middleware.PanicRecoverer)
 	r.Handle("/metrics", promhttp.Handler())
 	v1 := r.PathPrefix("/v1").Subrouter()
 
+	r.HandleFunc("/.well-known/jwks.json", keystore.JWKS).Methods(http.MethodGet)
 	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Content-Type", "text")
 		io.WriteString(w, "Service ready")
@@ -94,21 +262,105 @@ func main() {
 	ur := v1.PathPrefix("/user").Subrouter()
 	ur.HandleFunc("/register", userHandler.CreateUser).Methods(http.MethodPost)
 	ur.HandleFunc("/login", userHandler.Login).Methods(http.MethodPost)
+	ur.HandleFunc("/login/mfa", userHandler.LoginMFA).Methods(http.MethodPost)
+	ur.HandleFunc("/token/refresh", userHandler.RefreshToken).Methods(http.MethodPost)
+	ur.HandleFunc("/password/forgot", userHandler.ForgotPassword).Methods(http.MethodPost)
+	ur.HandleFunc("/password/reset", userHandler.ResetPassword).Methods(http.MethodPost)
+	ur.HandleFunc("/email/verify", userHandler.VerifyEmail).Methods(http.MethodPost)
+	ur.HandleFunc("/email/verify/resend", middleware.Authorized(userHandler.ResendEmailVerification)).Methods(http.MethodPost)
+	ur.HandleFunc("/email", middleware.Authorized(userHandler.ChangeEmail)).Methods(http.MethodPut)
+	ur.HandleFunc("/me", middleware.Authorized(userHandler.GetProfile)).Methods(http.MethodGet)
+	ur.HandleFunc("/me", middleware.Authorized(userHandler.UpdateProfile)).Methods(http.MethodPatch)
+	ur.HandleFunc("/me/close", middleware.Authorized(userHandler.CloseAccount)).Methods(http.MethodPost)
+	ur.HandleFunc("/me/export", middleware.Authorized(userHandler.ExportData)).Methods(http.MethodGet)
+	ur.HandleFunc("/password", middleware.Authorized(userHandler.ChangePassword)).Methods(http.MethodPut)
+	ur.HandleFunc("/logout", middleware.Authorized(userHandler.Logout)).Methods(http.MethodPost)
+	ur.HandleFunc("/mfa/totp", middleware.Authorized(userHandler.EnrollTOTP)).Methods(http.MethodPost)
+	ur.HandleFunc("/mfa/totp", middleware.Authorized(userHandler.DisableTOTP)).Methods(http.MethodDelete)
+	ur.HandleFunc("/mfa/totp/confirm", middleware.Authorized(userHandler.ConfirmTOTP)).Methods(http.MethodPost)
+	ur.HandleFunc("/pin", middleware.Authorized(userHandler.SetPIN)).Methods(http.MethodPost)
+	ur.HandleFunc("/pin", middleware.Authorized(userHandler.ChangePIN)).Methods(http.MethodPut)
+	ur.HandleFunc("/pin/reset", middleware.Authorized(userHandler.ResetPIN)).Methods(http.MethodPost)
 
 	// user balance routes
 	ubr := v1.PathPrefix("/balance").Subrouter()
 	ubr.HandleFunc("", middleware.Authorized(userBalanceHandler.Create)).Methods(http.MethodPost)
 	ubr.HandleFunc("", middleware.Authorized(userBalanceHandler.List)).Methods(http.MethodGet)
 	ubr.HandleFunc("/history", middleware.Authorized(userBalanceHandler.ListTransaction)).Methods(http.MethodGet)
+	ubr.HandleFunc("/history/verify", middleware.Authorized(userBalanceHandler.VerifyChain)).Methods(http.MethodGet)
+	ubr.HandleFunc("/deposits", middleware.Authorized(userBalanceHandler.CreateDeposit)).Methods(http.MethodPost)
+	ubr.HandleFunc("/deposits", middleware.Authorized(userBalanceHandler.ListDeposit)).Methods(http.MethodGet)
+	ubr.HandleFunc("/virtual-accounts", middleware.Authorized(userBalanceHandler.CreateVirtualAccount)).Methods(http.MethodPost)
+	ubr.HandleFunc("/virtual-accounts", middleware.Authorized(userBalanceHandler.ListVirtualAccount)).Methods(http.MethodGet)
+	ubr.HandleFunc("/overdrafts", middleware.Authorized(userBalanceHandler.ListOverdraft)).Methods(http.MethodGet)
+
+	// QR payment routes
+	qr := v1.PathPrefix("/qr").Subrouter()
+	qr.HandleFunc("", middleware.Authorized(userBalanceHandler.GenerateQR)).Methods(http.MethodGet)
+	qr.HandleFunc("/image", middleware.Authorized(userBalanceHandler.GenerateQRImage)).Methods(http.MethodGet)
+	qr.HandleFunc("/pay", middleware.Authorized(userBalanceHandler.PayQR)).Methods(http.MethodPost)
+
+	// payment request routes
+	prr := v1.PathPrefix("/payment-requests").Subrouter()
+	prr.HandleFunc("", middleware.Authorized(paymentRequestHandler.Create)).Methods(http.MethodPost)
+	prr.HandleFunc("", middleware.Authorized(paymentRequestHandler.List)).Methods(http.MethodGet)
+	prr.HandleFunc("/{id}/accept", middleware.Authorized(paymentRequestHandler.Accept)).Methods(http.MethodPost)
+	prr.HandleFunc("/{id}/decline", middleware.Authorized(paymentRequestHandler.Decline)).Methods(http.MethodPost)
+
+	// split bill routes
+	sr := v1.PathPrefix("/splits").Subrouter()
+	sr.HandleFunc("", middleware.Authorized(splitBillHandler.Create)).Methods(http.MethodPost)
+	sr.HandleFunc("", middleware.Authorized(splitBillHandler.List)).Methods(http.MethodGet)
+	sr.HandleFunc("/{id}", middleware.Authorized(splitBillHandler.Get)).Methods(http.MethodGet)
+	sr.HandleFunc("/{id}/settle", middleware.Authorized(splitBillHandler.Settle)).Methods(http.MethodPost)
+
+	// escrow routes
+	er := v1.PathPrefix("/escrows").Subrouter()
+	er.HandleFunc("", middleware.Authorized(escrowHandler.Create)).Methods(http.MethodPost)
+	er.HandleFunc("", middleware.Authorized(escrowHandler.List)).Methods(http.MethodGet)
+	er.HandleFunc("/{id}", middleware.Authorized(escrowHandler.Get)).Methods(http.MethodGet)
+	er.HandleFunc("/{id}/confirm", middleware.Authorized(escrowHandler.Confirm)).Methods(http.MethodPost)
+	er.HandleFunc("/{id}/cancel", middleware.Authorized(escrowHandler.Cancel)).Methods(http.MethodPost)
 
 	// transaction routes
 	txr := v1.PathPrefix("/transaction").Subrouter()
 	txr.HandleFunc("", middleware.Authorized(userBalanceHandler.Transaction)).Methods(http.MethodPost)
+	txr.HandleFunc("/challenges/{challengeId}/confirm", middleware.Authorized(userBalanceHandler.ConfirmTransaction)).Methods(http.MethodPost)
+
+	// currency routes
+	cr := v1.PathPrefix("/currency").Subrouter()
+	cr.HandleFunc("", currencyHandler.List).Methods(http.MethodGet)
 
 	// image routes
 	ir := v1.PathPrefix("/image").Subrouter()
 	ir.HandleFunc("", middleware.Authorized(imageHandler.UploadToS3)).Methods(http.MethodPost)
 
+	// admin routes, where reads are audited as well, as most of them serve customer or payment data
+	ar := r.PathPrefix("/admin").Subrouter()
+	ar.Use(audit.ReadRecorder(auditService))
+	ar.HandleFunc("/audit", middleware.RequirePermission(rbac.AuditRead)(auditHandler.List)).Methods(http.MethodGet)
+	ar.HandleFunc("/balance/overdraft", middleware.RequirePermission(rbac.BalancesManage)(userBalanceHandler.SetOverdraftLimit)).Methods(http.MethodPut)
+	ar.HandleFunc("/transfers/exports", middleware.RequirePermission(rbac.TransfersManage)(userBalanceHandler.ExportTransfers)).Methods(http.MethodPost)
+	ar.HandleFunc("/transfers/exports/{messageId}", middleware.RequirePermission(rbac.TransfersManage)(userBalanceHandler.GetPaymentExport)).Methods(http.MethodGet)
+	ar.HandleFunc("/transfers/{transactionId}/status", middleware.RequirePermission(rbac.TransfersManage)(userBalanceHandler.UpdateTransferStatus)).Methods(http.MethodPut)
+	ar.HandleFunc("/statements", middleware.RequirePermission(rbac.StatementsManage)(userBalanceHandler.ImportStatement)).Methods(http.MethodPost)
+	ar.HandleFunc("/statements/exceptions", middleware.RequirePermission(rbac.StatementsManage)(userBalanceHandler.ListStatementException)).Methods(http.MethodGet)
+	ar.HandleFunc("/statements/exceptions/{lineId}", middleware.RequirePermission(rbac.StatementsManage)(userBalanceHandler.ResolveStatementException)).Methods(http.MethodPut)
+	ar.HandleFunc("/simulator/inbound-transfers", middleware.RequirePermission(rbac.DepositsSimulate)(userBalanceHandler.InboundTransfer)).Methods(http.MethodPost)
+	ar.HandleFunc("/escrows/{id}/cancel", middleware.RequirePermission(rbac.EscrowsCancel)(escrowHandler.CancelByOperator)).Methods(http.MethodPost)
+	ar.HandleFunc("/currencies", middleware.RequirePermission(rbac.CurrenciesManage)(currencyHandler.ListAll)).Methods(http.MethodGet)
+	ar.HandleFunc("/currencies/{code}", middleware.RequirePermission(rbac.CurrenciesManage)(currencyHandler.Upsert)).Methods(http.MethodPut)
+	ar.HandleFunc("/users/{id}/roles", middleware.RequirePermission(rbac.RolesManage)(userHandler.GetRoles)).Methods(http.MethodGet)
+	ar.HandleFunc("/users/{id}/roles", middleware.RequirePermission(rbac.RolesManage)(userHandler.SetRoles)).Methods(http.MethodPut)
+
+	// back office routes
+	acr := ar.PathPrefix("/customers").Subrouter()
+	acr.HandleFunc("", middleware.RequirePermission(rbac.CustomersRead)(backOfficeHandler.Search)).Methods(http.MethodGet)
+	acr.HandleFunc("/{id}", middleware.RequirePermission(rbac.CustomersRead)(backOfficeHandler.Get)).Methods(http.MethodGet)
+	acr.HandleFunc("/{id}/transactions", middleware.RequirePermission(rbac.CustomersRead)(backOfficeHandler.ListTransactions)).Methods(http.MethodGet)
+	acr.HandleFunc("/{id}/notes", middleware.RequirePermission(rbac.CustomersRead)(backOfficeHandler.ListNotes)).Methods(http.MethodGet)
+	acr.HandleFunc("/{id}/notes", middleware.RequirePermission(rbac.NotesWrite)(backOfficeHandler.AddNote)).Methods(http.MethodPost)
+
 	// healthcheck endpoint
 	r.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		dbStatus := "ok"
@@ -149,9 +401,10 @@ func main() {
 	shutdownCtx, shutdownRelease := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownRelease()
 
+	stopWorkers()
 	slog.Info(fmt.Sprintf("Shutting down HTTP server listening on %s", httpServer.Addr))
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		slog.Error("HTTP server shutdown error: %v", err)
+		slog.Error(fmt.Sprintf("HTTP server shutdown error: %v", err))
 	}
 	slog.Info("Shutdown complete.")
 }
@@ -0,0 +1,105 @@
+// Command verifychain walks the transaction hash chain of every user and reports
+// the first broken link per user. It exits with status 1 if any chain is broken.
+// With -rekey it rehashes chains written before the chain was keyed instead.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	"github.com/lmittmann/tint"
+)
+
+func main() {
+	rekey := flag.Bool("rekey", false, "rehash chains written before the chain was keyed")
+	flag.Parse()
+
+	slog.SetDefault(slog.New(tint.NewHandler(os.Stdout, &tint.Options{
+		Level:      slog.LevelInfo,
+		TimeFormat: time.RFC3339,
+	})))
+
+	connStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?%s",
+		os.Getenv("DB_USERNAME"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_NAME"), os.Getenv("DB_PARAMS"))
+	db, err := db.Connect(connStr)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot connect to database: %v", err))
+		os.Exit(1)
+	}
+
+	err = userbalance.UseChainKey(os.Getenv("CHAIN_HMAC_SECRET"))
+	if err != nil {
+		slog.Error(fmt.Sprintf("CHAIN_HMAC_SECRET: %v", err))
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	repository := userbalance.NewRepository(db)
+
+	userIDs, err := repository.ListChainUserIDs(ctx)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot list users: %v", err))
+		os.Exit(1)
+	}
+
+	broken := 0
+	for _, userID := range userIDs {
+		if *rekey {
+			brokenLink, err := repository.RekeyChain(ctx, userID)
+			if err != nil {
+				slog.Error(fmt.Sprintf("Cannot rekey transactions of user %s: %v", userID, err))
+				os.Exit(1)
+			}
+			if brokenLink != nil {
+				broken++
+				logBrokenLink(userID, brokenLink)
+			}
+			continue
+		}
+
+		transactions, head, err := repository.ListChain(ctx, userID)
+		if err != nil {
+			slog.Error(fmt.Sprintf("Cannot list transactions of user %s: %v", userID, err))
+			os.Exit(1)
+		}
+		result := userbalance.VerifyTransactionChain(userID, transactions, head)
+		if result.Valid {
+			slog.Debug(fmt.Sprintf("user %s: %d transactions verified", userID, result.Checked))
+			if head != nil {
+				// logged so a chain head restored from an older copy of the database can be caught
+				slog.Info("chain head",
+					slog.String("userID", userID),
+					slog.Int64("chainSeq", head.ChainSeq),
+					slog.String("hash", head.Hash),
+				)
+			}
+			continue
+		}
+		broken++
+		logBrokenLink(userID, result.BrokenLink)
+	}
+
+	if *rekey {
+		slog.Info(fmt.Sprintf("Rekeyed %d users, %d broken chains left as they are.", len(userIDs)-broken, broken))
+	} else {
+		slog.Info(fmt.Sprintf("Verified %d users, %d broken chains.", len(userIDs), broken))
+	}
+	if broken > 0 {
+		os.Exit(1)
+	}
+}
+
+func logBrokenLink(userID string, brokenLink *userbalance.BrokenLinkResponse) {
+	slog.Error("broken transaction chain",
+		slog.String("userID", userID),
+		slog.String("transactionID", brokenLink.TransactionID),
+		slog.Int64("chainSeq", brokenLink.ChainSeq),
+		slog.String("reason", brokenLink.Reason),
+	)
+}
@@ -0,0 +1,5 @@
+ALTER TABLE user_transactions DROP CONSTRAINT IF EXISTS user_transactions_user_id_chain_seq_unique;
+
+ALTER TABLE user_transactions DROP COLUMN IF EXISTS hash;
+ALTER TABLE user_transactions DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE user_transactions DROP COLUMN IF EXISTS chain_seq;
@@ -0,0 +1,6 @@
+ALTER TABLE user_transactions ADD COLUMN IF NOT EXISTS chain_seq BIGINT NULL;
+ALTER TABLE user_transactions ADD COLUMN IF NOT EXISTS prev_hash CHAR(64) NULL;
+ALTER TABLE user_transactions ADD COLUMN IF NOT EXISTS hash CHAR(64) NULL;
+
+ALTER TABLE user_transactions ADD CONSTRAINT
+	user_transactions_user_id_chain_seq_unique UNIQUE (user_id, chain_seq);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs;
+DROP TRIGGER IF EXISTS audit_logs_no_update_delete ON audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_append_only;
+
+DROP INDEX IF EXISTS audit_logs_actor_user_id_created_at;
+DROP INDEX IF EXISTS audit_logs_created_at;
+
+DROP TABLE IF EXISTS audit_logs;
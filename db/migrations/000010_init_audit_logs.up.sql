@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS
+	audit_logs (
+		id BIGSERIAL PRIMARY KEY,
+		request_id VARCHAR(32) NOT NULL,
+		actor_user_id INT NULL,
+		method VARCHAR(10) NOT NULL,
+		route TEXT NOT NULL,
+		path TEXT NOT NULL,
+		payload JSONB NULL,
+		status_code INT NOT NULL,
+		ip VARCHAR(64) NOT NULL,
+		user_agent TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+	);
+
+CREATE INDEX IF NOT EXISTS audit_logs_created_at
+	ON audit_logs (created_at);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_user_id_created_at
+	ON audit_logs (actor_user_id, created_at);
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_no_update_delete
+	BEFORE UPDATE OR DELETE ON audit_logs
+	FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+CREATE TRIGGER audit_logs_no_truncate
+	BEFORE TRUNCATE ON audit_logs
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
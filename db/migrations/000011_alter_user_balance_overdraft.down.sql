@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS user_balance_overdrafts_open_unique;
+DROP INDEX IF EXISTS user_balance_overdrafts_user_id_started_at;
+
+DROP TABLE IF EXISTS user_balance_overdrafts;
+
+ALTER TABLE user_balance DROP CONSTRAINT IF EXISTS balance_within_overdraft_limit;
+ALTER TABLE user_balance ADD CONSTRAINT balance_non_negative check (balance >= 0);
+
+ALTER TABLE user_balance DROP CONSTRAINT IF EXISTS overdraft_limit_non_negative;
+ALTER TABLE user_balance DROP COLUMN IF EXISTS overdraft_limit;
@@ -0,0 +1,24 @@
+ALTER TABLE user_balance ADD COLUMN IF NOT EXISTS overdraft_limit NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE user_balance ADD CONSTRAINT overdraft_limit_non_negative check (overdraft_limit >= 0);
+
+ALTER TABLE user_balance DROP CONSTRAINT IF EXISTS balance_non_negative;
+ALTER TABLE user_balance ADD CONSTRAINT balance_within_overdraft_limit check (balance >= -overdraft_limit);
+
+CREATE TABLE IF NOT EXISTS
+	user_balance_overdrafts (
+		id SERIAL PRIMARY KEY,
+		user_balance_id INT NOT NULL,
+		user_id INT NOT NULL,
+		currency VARCHAR(60) NOT NULL,
+		lowest_balance NUMERIC NOT NULL,
+		started_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		ended_at TIMESTAMP NULL
+	);
+
+ALTER TABLE user_balance_overdrafts
+	ADD CONSTRAINT fk_user_balance_id FOREIGN KEY (user_balance_id) REFERENCES user_balance(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS user_balance_overdrafts_user_id_started_at
+	ON user_balance_overdrafts (user_id, started_at);
+-- at most one open overdraft period per balance
+CREATE UNIQUE INDEX IF NOT EXISTS user_balance_overdrafts_open_unique
+	ON user_balance_overdrafts (user_balance_id) WHERE ended_at IS NULL;
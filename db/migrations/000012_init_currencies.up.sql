@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS
+	currencies (
+		code CHAR(3) PRIMARY KEY,
+		enabled BOOLEAN NOT NULL DEFAULT false,
+		min_amount NUMERIC NOT NULL DEFAULT 1,
+		max_amount NUMERIC NULL,
+		symbol VARCHAR(8) NOT NULL,
+		decimal_places INT NOT NULL DEFAULT 2,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		updated_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE currencies ADD CONSTRAINT currencies_amount_range check (min_amount >= 0 AND (max_amount IS NULL OR max_amount >= min_amount));
+ALTER TABLE currencies ADD CONSTRAINT currencies_decimal_places_range check (decimal_places BETWEEN 0 AND 4);
+
+INSERT INTO currencies (code, enabled, symbol, decimal_places) VALUES
+	('IDR', true, 'Rp', 0),
+	('USD', true, '$', 2),
+	('SGD', true, 'S$', 2)
+ON CONFLICT DO NOTHING;
+
+-- keep currencies that already hold balances usable
+INSERT INTO currencies (code, enabled, symbol)
+	SELECT DISTINCT currency, true, currency FROM user_balance WHERE length(currency) = 3
+ON CONFLICT DO NOTHING;
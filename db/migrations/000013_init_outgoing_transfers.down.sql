@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS outgoing_transfers_status_created_at;
+
+DROP TABLE IF EXISTS outgoing_transfers;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS
+	outgoing_transfers (
+		transaction_id CHAR(16) PRIMARY KEY,
+		user_id INT NOT NULL,
+		amount NUMERIC NOT NULL,
+		currency VARCHAR(60) NOT NULL,
+		bank_account_number VARCHAR(30) NOT NULL,
+		bank_name VARCHAR(30) NOT NULL,
+		status VARCHAR(16) NOT NULL DEFAULT 'pending',
+		gateway_reference VARCHAR(64) NULL,
+		failure_reason TEXT NULL,
+		refund_transaction_id CHAR(16) NULL,
+		locked_until TIMESTAMP NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		updated_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE outgoing_transfers
+	ADD CONSTRAINT fk_transaction_id FOREIGN KEY (transaction_id) REFERENCES user_transactions(id) ON DELETE CASCADE;
+ALTER TABLE outgoing_transfers
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE outgoing_transfers ADD CONSTRAINT
+	outgoing_transfers_status_valid check (status IN ('pending', 'submitted', 'settled', 'failed'));
+
+CREATE INDEX IF NOT EXISTS outgoing_transfers_status_created_at
+	ON outgoing_transfers (status, created_at);
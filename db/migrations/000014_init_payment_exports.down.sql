@@ -0,0 +1,4 @@
+ALTER TABLE outgoing_transfers DROP CONSTRAINT IF EXISTS fk_export_id;
+ALTER TABLE outgoing_transfers DROP COLUMN IF EXISTS export_id;
+
+DROP TABLE IF EXISTS payment_exports;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS
+	payment_exports (
+		id SERIAL PRIMARY KEY,
+		message_id VARCHAR(35) NOT NULL,
+		number_of_transactions INT NOT NULL,
+		control_sum NUMERIC NOT NULL,
+		document TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE payment_exports ADD CONSTRAINT payment_exports_message_id_unique UNIQUE (message_id);
+
+ALTER TABLE outgoing_transfers ADD COLUMN IF NOT EXISTS export_id INT NULL;
+ALTER TABLE outgoing_transfers
+	ADD CONSTRAINT fk_export_id FOREIGN KEY (export_id) REFERENCES payment_exports(id);
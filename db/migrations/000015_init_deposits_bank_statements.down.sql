@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS bank_statement_lines_status_created_at;
+DROP TABLE IF EXISTS bank_statement_lines;
+
+DROP TABLE IF EXISTS bank_statements;
+
+DROP INDEX IF EXISTS deposits_user_id_created_at;
+DROP INDEX IF EXISTS deposits_status_amount_currency;
+DROP TABLE IF EXISTS deposits;
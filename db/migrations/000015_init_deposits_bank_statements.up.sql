@@ -0,0 +1,64 @@
+CREATE TABLE IF NOT EXISTS
+	deposits (
+		id CHAR(16) PRIMARY KEY,
+		reference VARCHAR(16) NOT NULL,
+		user_id INT NOT NULL,
+		amount NUMERIC NOT NULL,
+		currency VARCHAR(60) NOT NULL,
+		sender_bank_account_number VARCHAR(30) NOT NULL,
+		sender_bank_name VARCHAR(30) NOT NULL,
+		status VARCHAR(16) NOT NULL DEFAULT 'pending',
+		transaction_id CHAR(16) NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		confirmed_at TIMESTAMP NULL
+	);
+
+ALTER TABLE deposits
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE deposits ADD CONSTRAINT deposits_reference_unique UNIQUE (reference);
+ALTER TABLE deposits ADD CONSTRAINT
+	deposits_status_valid check (status IN ('pending', 'confirmed'));
+CREATE INDEX IF NOT EXISTS deposits_status_amount_currency
+	ON deposits (status, amount, currency);
+CREATE INDEX IF NOT EXISTS deposits_user_id_created_at
+	ON deposits (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS
+	bank_statements (
+		id SERIAL PRIMARY KEY,
+		format VARCHAR(16) NOT NULL,
+		statement_id VARCHAR(64) NOT NULL,
+		account VARCHAR(64) NOT NULL,
+		imported_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE bank_statements ADD CONSTRAINT
+	bank_statements_account_statement_id_unique UNIQUE (account, statement_id);
+
+CREATE TABLE IF NOT EXISTS
+	bank_statement_lines (
+		id SERIAL PRIMARY KEY,
+		statement_id INT NOT NULL,
+		entry_reference VARCHAR(64) NOT NULL,
+		booking_date DATE NULL,
+		amount NUMERIC NOT NULL,
+		amount_valid BOOLEAN NOT NULL,
+		currency VARCHAR(60) NOT NULL,
+		reference TEXT NOT NULL,
+		sender_account VARCHAR(64) NOT NULL,
+		sender_name VARCHAR(140) NOT NULL,
+		status VARCHAR(16) NOT NULL DEFAULT 'unmatched',
+		deposit_id CHAR(16) NULL,
+		note TEXT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		resolved_at TIMESTAMP NULL
+	);
+
+ALTER TABLE bank_statement_lines
+	ADD CONSTRAINT fk_statement_id FOREIGN KEY (statement_id) REFERENCES bank_statements(id) ON DELETE CASCADE;
+ALTER TABLE bank_statement_lines
+	ADD CONSTRAINT fk_deposit_id FOREIGN KEY (deposit_id) REFERENCES deposits(id);
+ALTER TABLE bank_statement_lines ADD CONSTRAINT
+	bank_statement_lines_status_valid check (status IN ('unmatched', 'matched', 'dismissed'));
+CREATE INDEX IF NOT EXISTS bank_statement_lines_status_created_at
+	ON bank_statement_lines (status, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS virtual_accounts_user_id_currency;
+
+DROP TABLE IF EXISTS virtual_accounts;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS
+	virtual_accounts (
+		account_number VARCHAR(20) PRIMARY KEY,
+		user_id INT NOT NULL,
+		currency VARCHAR(60) NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE virtual_accounts
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS virtual_accounts_user_id_currency
+	ON virtual_accounts (user_id, currency);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS payment_requests_requester_id_created_at;
+DROP INDEX IF EXISTS payment_requests_payer_id_created_at;
+
+DROP TABLE IF EXISTS payment_requests;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS
+	payment_requests (
+		id CHAR(16) PRIMARY KEY,
+		requester_id INT NOT NULL,
+		payer_id INT NOT NULL,
+		amount NUMERIC NOT NULL,
+		currency VARCHAR(60) NOT NULL,
+		memo VARCHAR(140) NOT NULL DEFAULT '',
+		status VARCHAR(16) NOT NULL DEFAULT 'pending',
+		transaction_id CHAR(16) NULL,
+		expires_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		responded_at TIMESTAMP NULL
+	);
+
+ALTER TABLE payment_requests
+	ADD CONSTRAINT fk_requester_id FOREIGN KEY (requester_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE payment_requests
+	ADD CONSTRAINT fk_payer_id FOREIGN KEY (payer_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE payment_requests ADD CONSTRAINT
+	payment_requests_payer_not_requester check (payer_id <> requester_id);
+ALTER TABLE payment_requests ADD CONSTRAINT
+	payment_requests_status_valid check (status IN ('pending', 'accepted', 'declined'));
+CREATE INDEX IF NOT EXISTS payment_requests_payer_id_created_at
+	ON payment_requests (payer_id, created_at);
+CREATE INDEX IF NOT EXISTS payment_requests_requester_id_created_at
+	ON payment_requests (requester_id, created_at);
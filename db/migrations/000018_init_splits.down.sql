@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS split_participants_user_id;
+DROP TABLE IF EXISTS split_participants;
+
+DROP INDEX IF EXISTS splits_creator_id_created_at;
+DROP TABLE IF EXISTS splits;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS
+	splits (
+		id CHAR(16) PRIMARY KEY,
+		creator_id INT NOT NULL,
+		title VARCHAR(100) NOT NULL,
+		total NUMERIC NOT NULL,
+		currency VARCHAR(60) NOT NULL,
+		share_type VARCHAR(16) NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE splits
+	ADD CONSTRAINT fk_creator_id FOREIGN KEY (creator_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE splits ADD CONSTRAINT
+	splits_share_type_valid check (share_type IN ('equal', 'fixed', 'percentage'));
+CREATE INDEX IF NOT EXISTS splits_creator_id_created_at
+	ON splits (creator_id, created_at);
+
+CREATE TABLE IF NOT EXISTS
+	split_participants (
+		split_id CHAR(16) NOT NULL,
+		user_id INT NOT NULL,
+		position INT NOT NULL,
+		amount NUMERIC NOT NULL,
+		status VARCHAR(16) NOT NULL DEFAULT 'pending',
+		transaction_id CHAR(16) NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		paid_at TIMESTAMP NULL,
+		PRIMARY KEY (split_id, user_id)
+	);
+
+ALTER TABLE split_participants
+	ADD CONSTRAINT fk_split_id FOREIGN KEY (split_id) REFERENCES splits(id) ON DELETE CASCADE;
+ALTER TABLE split_participants
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE split_participants ADD CONSTRAINT
+	split_participants_status_valid check (status IN ('pending', 'paid'));
+CREATE INDEX IF NOT EXISTS split_participants_user_id
+	ON split_participants (user_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS escrows_held_release_after;
+DROP INDEX IF EXISTS escrows_seller_id_created_at;
+DROP INDEX IF EXISTS escrows_buyer_id_created_at;
+
+DROP TABLE IF EXISTS escrows;
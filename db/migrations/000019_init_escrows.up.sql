@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS
+	escrows (
+		id CHAR(16) PRIMARY KEY,
+		buyer_id INT NOT NULL,
+		seller_id INT NOT NULL,
+		amount NUMERIC NOT NULL,
+		currency VARCHAR(60) NOT NULL,
+		description VARCHAR(255) NOT NULL,
+		status VARCHAR(16) NOT NULL DEFAULT 'held',
+		release_after TIMESTAMP NOT NULL,
+		fund_transaction_id CHAR(16) NOT NULL,
+		settle_transaction_id CHAR(16) NULL,
+		cancel_reason VARCHAR(255) NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		closed_at TIMESTAMP NULL
+	);
+
+ALTER TABLE escrows
+	ADD CONSTRAINT fk_buyer_id FOREIGN KEY (buyer_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE escrows
+	ADD CONSTRAINT fk_seller_id FOREIGN KEY (seller_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE escrows ADD CONSTRAINT
+	escrows_seller_not_buyer check (seller_id <> buyer_id);
+ALTER TABLE escrows ADD CONSTRAINT
+	escrows_status_valid check (status IN ('held', 'released', 'refunded'));
+CREATE INDEX IF NOT EXISTS escrows_buyer_id_created_at
+	ON escrows (buyer_id, created_at);
+CREATE INDEX IF NOT EXISTS escrows_seller_id_created_at
+	ON escrows (seller_id, created_at);
+CREATE INDEX IF NOT EXISTS escrows_held_release_after
+	ON escrows (release_after) WHERE status = 'held';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS refresh_tokens_user_id;
+DROP INDEX IF EXISTS refresh_tokens_family_id;
+
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS
+	refresh_tokens (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		family_id CHAR(16) NOT NULL,
+		token_hash BYTEA NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+		used_at TIMESTAMP NULL,
+		revoked_at TIMESTAMP NULL
+	);
+
+ALTER TABLE refresh_tokens
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE refresh_tokens ADD CONSTRAINT refresh_tokens_token_hash_unique UNIQUE (token_hash);
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id
+	ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS refresh_tokens_user_id
+	ON refresh_tokens (user_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS revoked_user_tokens;
+
+DROP INDEX IF EXISTS revoked_tokens_created_at;
+DROP TABLE IF EXISTS revoked_tokens;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS
+	revoked_tokens (
+		jti VARCHAR(32) PRIMARY KEY,
+		user_id INT NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE revoked_tokens
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS revoked_tokens_created_at
+	ON revoked_tokens (created_at);
+
+CREATE TABLE IF NOT EXISTS
+	revoked_user_tokens (
+		user_id INT PRIMARY KEY,
+		revoked_before TIMESTAMPTZ NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE revoked_user_tokens
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
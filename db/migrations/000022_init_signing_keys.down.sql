@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS signing_keys_expires_at;
+DROP TABLE IF EXISTS signing_keys;
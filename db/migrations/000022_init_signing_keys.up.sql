@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS
+	signing_keys (
+		kid VARCHAR(32) PRIMARY KEY,
+		algorithm VARCHAR(8) NOT NULL,
+		encrypted_private_key BYTEA NOT NULL,
+		activates_at TIMESTAMPTZ NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE signing_keys
+	ADD CONSTRAINT signing_keys_expire_after_activation CHECK (expires_at > activates_at);
+CREATE INDEX IF NOT EXISTS signing_keys_expires_at
+	ON signing_keys (expires_at);
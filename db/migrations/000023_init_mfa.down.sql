@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS mfa_challenges;
+
+DROP INDEX IF EXISTS mfa_recovery_codes_user_id;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+
+DROP TABLE IF EXISTS user_totp;
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS
+	user_totp (
+		user_id INT PRIMARY KEY,
+		encrypted_secret BYTEA NOT NULL,
+		last_used_step BIGINT NOT NULL DEFAULT 0,
+		enabled_at TIMESTAMPTZ NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE user_totp
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE TABLE IF NOT EXISTS
+	mfa_recovery_codes (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		code_hash BYTEA NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+		used_at TIMESTAMPTZ NULL
+	);
+
+ALTER TABLE mfa_recovery_codes
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS mfa_recovery_codes_user_id
+	ON mfa_recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS
+	mfa_challenges (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		token_hash BYTEA NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+		used_at TIMESTAMPTZ NULL
+	);
+
+ALTER TABLE mfa_challenges
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE mfa_challenges ADD CONSTRAINT mfa_challenges_token_hash_unique UNIQUE (token_hash);
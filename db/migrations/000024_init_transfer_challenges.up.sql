@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS
+	transfer_challenges (
+		id CHAR(16) PRIMARY KEY,
+		user_id INT NOT NULL,
+		amount NUMERIC NOT NULL,
+		currency VARCHAR(60) NOT NULL,
+		bank_account_number VARCHAR(30) NOT NULL,
+		bank_name VARCHAR(30) NOT NULL,
+		attempts INT NOT NULL DEFAULT 0,
+		expires_at TIMESTAMPTZ NOT NULL,
+		confirmed_at TIMESTAMPTZ NULL,
+		transaction_id CHAR(16) NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE transfer_challenges
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE transfer_challenges
+	ADD CONSTRAINT fk_transaction_id FOREIGN KEY (transaction_id) REFERENCES user_transactions(id) ON DELETE SET NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS
+	user_pins (
+		user_id INT PRIMARY KEY,
+		hashed_pin BYTEA NOT NULL,
+		failed_attempts INT NOT NULL DEFAULT 0,
+		locked_until TIMESTAMPTZ NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE user_pins
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS login_failures_last_failure_at;
+DROP TABLE IF EXISTS login_failures;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS
+	login_failures (
+		scope VARCHAR(8) NOT NULL,
+		key VARCHAR(255) NOT NULL,
+		failures INT NOT NULL DEFAULT 0,
+		last_failure_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+		blocked_until TIMESTAMPTZ NULL,
+		PRIMARY KEY (scope, key)
+	);
+
+ALTER TABLE login_failures ADD CONSTRAINT
+	login_failures_scope_valid check (scope IN ('account', 'ip'));
+CREATE INDEX IF NOT EXISTS login_failures_last_failure_at
+	ON login_failures (last_failure_at);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS
+	password_resets (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		token_hash BYTEA NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+		used_at TIMESTAMPTZ NULL
+	);
+
+ALTER TABLE password_resets
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE password_resets ADD CONSTRAINT password_resets_token_hash_unique UNIQUE (token_hash);
+CREATE INDEX IF NOT EXISTS password_resets_user_id
+	ON password_resets (user_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS email_verifications_user_id_created_at;
+DROP TABLE IF EXISTS email_verifications;
+
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,20 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ NULL;
+-- accounts registered before verification existed are treated as verified
+UPDATE users SET email_verified_at = COALESCE(created_at, current_timestamp);
+
+CREATE TABLE IF NOT EXISTS
+	email_verifications (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		email VARCHAR(255) NOT NULL,
+		token_hash BYTEA NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+		used_at TIMESTAMPTZ NULL
+	);
+
+ALTER TABLE email_verifications
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE email_verifications ADD CONSTRAINT email_verifications_token_hash_unique UNIQUE (token_hash);
+CREATE INDEX IF NOT EXISTS email_verifications_user_id_created_at
+	ON email_verifications (user_id, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
+ALTER TABLE users DROP COLUMN IF EXISTS preferred_currency;
+ALTER TABLE users DROP COLUMN IF EXISTS phone;
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone VARCHAR(16) NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_currency VARCHAR(3) NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS user_roles_role;
+DROP TABLE IF EXISTS user_roles;
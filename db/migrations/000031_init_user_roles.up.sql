@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS
+	user_roles (
+		user_id INT NOT NULL,
+		role VARCHAR(32) NOT NULL,
+		granted_by INT NULL,
+		granted_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp,
+		PRIMARY KEY (user_id, role)
+	);
+
+ALTER TABLE user_roles
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE user_roles
+	ADD CONSTRAINT fk_granted_by FOREIGN KEY (granted_by) REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS user_roles_role
+	ON user_roles (role);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS customer_notes_user_id_created_at;
+DROP TABLE IF EXISTS customer_notes;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS
+	customer_notes (
+		id SERIAL PRIMARY KEY,
+		user_id INT NOT NULL,
+		author_id INT NULL,
+		body TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE customer_notes
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE customer_notes
+	ADD CONSTRAINT fk_author_id FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS customer_notes_user_id_created_at
+	ON customer_notes (user_id, created_at DESC);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS
+	transaction_chain_heads (
+		user_id INT PRIMARY KEY,
+		chain_seq BIGINT NOT NULL,
+		hash CHAR(64) NOT NULL,
+		anchor CHAR(64) NOT NULL,
+		updated_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+	);
+
+ALTER TABLE transaction_chain_heads
+	ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
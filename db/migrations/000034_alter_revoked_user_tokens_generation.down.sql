@@ -0,0 +1,2 @@
+ALTER TABLE revoked_user_tokens ADD COLUMN IF NOT EXISTS revoked_before TIMESTAMPTZ NOT NULL DEFAULT current_timestamp;
+ALTER TABLE revoked_user_tokens DROP COLUMN IF EXISTS generation;
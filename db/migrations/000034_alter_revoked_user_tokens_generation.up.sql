@@ -0,0 +1,2 @@
+ALTER TABLE revoked_user_tokens ADD COLUMN IF NOT EXISTS generation BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE revoked_user_tokens DROP COLUMN IF EXISTS revoked_before;
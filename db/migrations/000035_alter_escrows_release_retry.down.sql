@@ -0,0 +1,2 @@
+ALTER TABLE escrows DROP COLUMN IF EXISTS next_release_at;
+ALTER TABLE escrows DROP COLUMN IF EXISTS release_attempts;
@@ -0,0 +1,2 @@
+ALTER TABLE escrows ADD COLUMN IF NOT EXISTS release_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE escrows ADD COLUMN IF NOT EXISTS next_release_at TIMESTAMP NULL;
@@ -0,0 +1 @@
+ALTER TABLE virtual_accounts DROP COLUMN IF EXISTS disabled_at;
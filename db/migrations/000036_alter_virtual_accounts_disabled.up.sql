@@ -0,0 +1 @@
+ALTER TABLE virtual_accounts ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP NULL;
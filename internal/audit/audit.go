@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditLog struct {
+	ID          uint64
+	RequestID   string
+	ActorUserID *uint64
+	Method      string
+	Route       string
+	Path        string
+	Payload     json.RawMessage
+	StatusCode  int
+	IP          string
+	UserAgent   string
+	CreatedAt   time.Time
+}
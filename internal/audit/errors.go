@@ -0,0 +1,7 @@
+package audit
+
+import "errors"
+
+var (
+	ErrValidationFailed = errors.New("validation failed")
+)
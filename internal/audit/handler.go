@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	var req ListAuditLogPayload
+	var params = r.URL.Query()
+	if v, ok := request.CheckPositiveInt(params, "limit"); ok {
+		req.Limit = v
+		if v == 0 {
+			req.Limit = 20
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if v, ok := request.CheckPositiveInt(params, "offset"); ok {
+		req.Offset = v
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	for key, dst := range map[string]**time.Time{"from": &req.From, "to": &req.To} {
+		if !params.Has(key) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, params.Get(key))
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+				Message: "Bad request",
+				Error:   key + " must be an RFC 3339 timestamp",
+			})
+			return
+		}
+		*dst = &t
+	}
+	req.ActorUserID = params.Get("actorUserId")
+
+	logs, pagination, err := h.service.List(r.Context(), req)
+	if errors.Is(err, ErrValidationFailed) {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Bad request",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, response.ResponseBody{
+			Message: "Internal server error",
+			Error:   err.Error(),
+		})
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    logs,
+		Meta:    pagination,
+	})
+}
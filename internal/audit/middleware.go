@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/jwt"
+	"github.com/citadel-corp/paimon-bank/internal/common/middleware"
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/gorilla/mux"
+)
+
+// maxPayloadSize bounds how much of a request body is kept in the audit trail.
+const maxPayloadSize = 16 * 1024
+
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *statusResponseWriter) WriteHeader(code int) {
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Recorder writes an audit log entry for every state-changing request once it has been served.
+func Recorder(service Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var payload []byte
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") || r.Header.Get("Content-Type") == "" {
+				buf, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize+1))
+				if err == nil && len(buf) <= maxPayloadSize {
+					payload = buf
+				}
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(buf), r.Body))
+			}
+
+			statusWriter := &statusResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(statusWriter, r)
+
+			log := newAuditLog(r, statusWriter.statusCode, payload)
+			// the request context is cancelled once the client goes away, the audit entry must still be written
+			err := service.Record(context.WithoutCancel(r.Context()), log)
+			if err != nil {
+				slog.Error(fmt.Sprintf("Cannot write audit log for request %s: %v", log.RequestID, err))
+			}
+		})
+	}
+}
+
+// ReadRecorder writes an audit log entry for every read request, for routes that serve customer data.
+// State-changing requests are left to Recorder. The response is held back until the entry is written
+// and replaced by an error if it cannot be, so no data is served without a record of who read it.
+func ReadRecorder(service Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buffered := &bufferedResponseWriter{header: http.Header{}, statusCode: http.StatusOK}
+			next.ServeHTTP(buffered, r)
+
+			log := newAuditLog(r, buffered.statusCode, queryPayload(r.URL.Query()))
+			err := service.Record(context.WithoutCancel(r.Context()), log)
+			if err != nil {
+				slog.Error(fmt.Sprintf("Cannot write audit log for request %s: %v", log.RequestID, err))
+				response.JSON(w, http.StatusInternalServerError, response.ResponseBody{
+					Message: "Internal server error",
+					Error:   "cannot record this read in the audit log",
+				})
+				return
+			}
+
+			for key, values := range buffered.header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(buffered.statusCode)
+			w.Write(buffered.body.Bytes())
+		})
+	}
+}
+
+// bufferedResponseWriter keeps a response in memory until it is known that it may be sent.
+type bufferedResponseWriter struct {
+	header     http.Header
+	body       bytes.Buffer
+	statusCode int
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	w.statusCode = code
+}
+
+func newAuditLog(r *http.Request, statusCode int, payload []byte) *AuditLog {
+	route := r.URL.Path
+	if current := mux.CurrentRoute(r); current != nil {
+		if tpl, err := current.GetPathTemplate(); err == nil {
+			route = tpl
+		}
+	}
+
+	return &AuditLog{
+		RequestID:   middleware.GetRequestID(r),
+		ActorUserID: actorUserID(r),
+		Method:      r.Method,
+		Route:       route,
+		Path:        r.URL.Path,
+		Payload:     payload,
+		StatusCode:  statusCode,
+		IP:          request.ClientIP(r),
+		UserAgent:   r.UserAgent(),
+	}
+}
+
+// queryPayload keeps the query string of a read, such as a search term, as a JSON object.
+func queryPayload(query url.Values) []byte {
+	if len(query) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(query))
+	for key, values := range query {
+		params[key] = strings.Join(values, ",")
+	}
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return nil
+	}
+	return payload
+}
+
+// actorUserID returns the user the bearer token was issued to, if any.
+func actorUserID(r *http.Request) *uint64 {
+	tokenString := r.Header.Get("Authorization")
+	if len(tokenString) <= len("Bearer ") {
+		return nil
+	}
+	subject, err := jwt.VerifyAndGetSubject(tokenString[len("Bearer "):])
+	if err != nil {
+		return nil
+	}
+	userID, err := strconv.ParseUint(subject, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &userID
+}
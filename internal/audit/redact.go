@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are the lowercased names of the payload fields whose values are redacted.
+// Names are matched exactly, so fields such as currencyCode are kept.
+var sensitiveKeys = map[string]bool{
+	"password":        true,
+	"currentpassword": true,
+	"newpassword":     true,
+	"pin":             true,
+	"currentpin":      true,
+	"newpin":          true,
+	"token":           true,
+	"accesstoken":     true,
+	"refreshtoken":    true,
+	"mfatoken":        true,
+	"secret":          true,
+	"otpauthuri":      true,
+	"code":            true,
+	"recoverycode":    true,
+	"recoverycodes":   true,
+}
+
+// redact replaces the values of sensitive fields anywhere in a JSON payload.
+// Payloads that are not valid JSON are dropped entirely.
+func redact(payload json.RawMessage) json.RawMessage {
+	if len(payload) == 0 {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(payload, &v); err != nil {
+		return nil
+	}
+	b, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, value := range t {
+			if isSensitiveKey(key) {
+				t[key] = redacted
+				continue
+			}
+			t[key] = redactValue(value)
+		}
+		return t
+	case []any:
+		for i, value := range t {
+			t[i] = redactValue(value)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	return sensitiveKeys[strings.ToLower(key)]
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{
+			name:    "empty",
+			payload: "",
+			want:    "",
+		},
+		{
+			name:    "invalid json",
+			payload: "{",
+			want:    "",
+		},
+		{
+			name:    "credentials",
+			payload: `{"email":"a@b.c","password":"hunter2","pin":"123456"}`,
+			want:    `{"email":"a@b.c","password":"[REDACTED]","pin":"[REDACTED]"}`,
+		},
+		{
+			name:    "key names match case-insensitively",
+			payload: `{"RefreshToken":"abc","newPin":"654321"}`,
+			want:    `{"RefreshToken":"[REDACTED]","newPin":"[REDACTED]"}`,
+		},
+		{
+			name:    "similar names are kept",
+			payload: `{"currencyCode":"IDR","shipping":"express","qrCode":"000201","spinner":true}`,
+			want:    `{"currencyCode":"IDR","qrCode":"000201","shipping":"express","spinner":true}`,
+		},
+		{
+			name:    "nested objects and arrays",
+			payload: `{"items":[{"code":"123456"},{"recoveryCode":"abcd-efgh"}],"meta":{"token":"x"}}`,
+			want:    `{"items":[{"code":"[REDACTED]"},{"recoveryCode":"[REDACTED]"}],"meta":{"token":"[REDACTED]"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redact(json.RawMessage(tt.payload))
+			if string(got) != tt.want {
+				t.Errorf("redact(%s) = %s, want %s", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+)
+
+type Repository interface {
+	Create(ctx context.Context, log *AuditLog) error
+	List(ctx context.Context, payload ListAuditLogPayload) ([]AuditLog, *response.Pagination, error)
+}
+
+type dbRepository struct {
+	db *db.DB
+}
+
+func NewRepository(db *db.DB) Repository {
+	return &dbRepository{db: db}
+}
+
+// Create implements Repository.
+func (d *dbRepository) Create(ctx context.Context, log *AuditLog) error {
+	createAuditLogQuery := `
+		INSERT INTO audit_logs (
+			request_id, actor_user_id, method, route, path, payload, status_code, ip, user_agent
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+		RETURNING id, created_at;
+	`
+	var payload any
+	if len(log.Payload) > 0 {
+		payload = string(log.Payload)
+	}
+	row := d.db.DB().QueryRowContext(ctx, createAuditLogQuery, log.RequestID, log.ActorUserID, log.Method, log.Route, log.Path, payload, log.StatusCode, log.IP, log.UserAgent)
+	return row.Scan(&log.ID, &log.CreatedAt)
+}
+
+// List implements Repository.
+func (d *dbRepository) List(ctx context.Context, payload ListAuditLogPayload) ([]AuditLog, *response.Pagination, error) {
+	var resp []AuditLog
+	pagination := &response.Pagination{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	}
+
+	args := []any{}
+	conditions := ""
+	if payload.ActorUserID != "" {
+		args = append(args, payload.ActorUserID)
+		conditions += fmt.Sprintf(" AND actor_user_id = $%d", len(args))
+	}
+	if payload.From != nil {
+		args = append(args, payload.From.UTC())
+		conditions += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if payload.To != nil {
+		args = append(args, payload.To.UTC())
+		conditions += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	args = append(args, payload.Limit, payload.Offset)
+
+	selectQuery := fmt.Sprintf(`
+		SELECT COUNT(*) OVER() AS total_count, id, request_id, actor_user_id, method, route, path, payload, status_code, ip, user_agent, created_at
+		FROM audit_logs
+		WHERE TRUE%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+		OFFSET $%d
+	`, conditions, len(args)-1, len(args))
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var al AuditLog
+		var payload *string
+		err = rows.Scan(&pagination.Total, &al.ID, &al.RequestID, &al.ActorUserID, &al.Method, &al.Route, &al.Path, &payload, &al.StatusCode, &al.IP, &al.UserAgent, &al.CreatedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+		if payload != nil {
+			al.Payload = []byte(*payload)
+		}
+
+		resp = append(resp, al)
+	}
+
+	return resp, pagination, rows.Err()
+}
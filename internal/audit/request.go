@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type ListAuditLogPayload struct {
+	ActorUserID string
+	From        *time.Time
+	To          *time.Time
+	Limit       int
+	Offset      int
+}
+
+func (p ListAuditLogPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.ActorUserID, is.Digit),
+		validation.Field(&p.To, validation.When(p.From != nil && p.To != nil, validation.By(func(value interface{}) error {
+			if p.To.Before(*p.From) {
+				return validation.NewError("validation_to_before_from", "must not be before from")
+			}
+			return nil
+		}))),
+	)
+}
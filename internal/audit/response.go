@@ -0,0 +1,17 @@
+package audit
+
+import "encoding/json"
+
+type AuditLogResponse struct {
+	ID          uint64          `json:"id"`
+	RequestID   string          `json:"requestId"`
+	ActorUserID *uint64         `json:"actorUserId"`
+	Method      string          `json:"method"`
+	Route       string          `json:"route"`
+	Path        string          `json:"path"`
+	Payload     json.RawMessage `json:"payload"`
+	StatusCode  int             `json:"statusCode"`
+	IP          string          `json:"ip"`
+	UserAgent   string          `json:"userAgent"`
+	CreatedAt   int64           `json:"createdAt"`
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+)
+
+type Service interface {
+	Record(ctx context.Context, log *AuditLog) error
+	List(ctx context.Context, req ListAuditLogPayload) ([]AuditLogResponse, *response.Pagination, error)
+}
+
+type auditService struct {
+	repository Repository
+}
+
+func NewService(repository Repository) Service {
+	return &auditService{repository: repository}
+}
+
+func (s *auditService) Record(ctx context.Context, log *AuditLog) error {
+	log.Payload = redact(log.Payload)
+	return s.repository.Create(ctx, log)
+}
+
+func (s *auditService) List(ctx context.Context, req ListAuditLogPayload) ([]AuditLogResponse, *response.Pagination, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	logs, pagination, err := s.repository.List(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := make([]AuditLogResponse, len(logs))
+	for i, al := range logs {
+		resp[i] = AuditLogResponse{
+			ID:          al.ID,
+			RequestID:   al.RequestID,
+			ActorUserID: al.ActorUserID,
+			Method:      al.Method,
+			Route:       al.Route,
+			Path:        al.Path,
+			Payload:     al.Payload,
+			StatusCode:  al.StatusCode,
+			IP:          al.IP,
+			UserAgent:   al.UserAgent,
+			CreatedAt:   al.CreatedAt.UnixMilli(),
+		}
+	}
+	return resp, pagination, nil
+}
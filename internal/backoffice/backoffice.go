@@ -0,0 +1,27 @@
+package backoffice
+
+import "time"
+
+// Customer is a user account as back-office staff see it.
+type Customer struct {
+	ID                string
+	Email             string
+	Name              string
+	EmailVerifiedAt   *time.Time
+	Phone             *string
+	PreferredCurrency *string
+	Timezone          string
+	CreatedAt         time.Time
+	ClosedAt          *time.Time
+}
+
+// Note is an internal remark staff keep about a customer; customers never see notes.
+// AuthorID and AuthorName are nil once the author's account is deleted.
+type Note struct {
+	ID         uint64
+	CustomerID string
+	AuthorID   *string
+	AuthorName *string
+	Body       string
+	CreatedAt  time.Time
+}
@@ -0,0 +1,8 @@
+package backoffice
+
+import "errors"
+
+var (
+	ErrValidationFailed = errors.New("validation failed")
+	ErrCustomerNotFound = errors.New("customer not found")
+)
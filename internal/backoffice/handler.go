@@ -0,0 +1,173 @@
+package backoffice
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/middleware"
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	var req SearchCustomerPayload
+	var params = r.URL.Query()
+	var ok bool
+	req.Limit, req.Offset, ok = pageParams(params)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	req.Query = params.Get("q")
+
+	customers, pagination, err := h.service.Search(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    customers,
+		Meta:    pagination,
+	})
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	customer, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    customer,
+	})
+}
+
+func (h *Handler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	req := ListTransactionPayload{CustomerID: mux.Vars(r)["id"]}
+	var ok bool
+	req.Limit, req.Offset, ok = pageParams(r.URL.Query())
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	transactions, pagination, err := h.service.ListTransactions(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    transactions,
+		Meta:    pagination,
+	})
+}
+
+func (h *Handler) ListNotes(w http.ResponseWriter, r *http.Request) {
+	req := ListNotePayload{CustomerID: mux.Vars(r)["id"]}
+	var ok bool
+	req.Limit, req.Offset, ok = pageParams(r.URL.Query())
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	notes, pagination, err := h.service.ListNotes(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    notes,
+		Meta:    pagination,
+	})
+}
+
+func (h *Handler) AddNote(w http.ResponseWriter, r *http.Request) {
+	authorID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req AddNotePayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.CustomerID = mux.Vars(r)["id"]
+	req.AuthorID = authorID
+
+	note, err := h.service.AddNote(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, response.ResponseBody{
+		Message: "Note added successfully",
+		Data:    note,
+	})
+}
+
+// pageParams reads limit, 20 by default, and offset.
+func pageParams(params url.Values) (int, int, bool) {
+	limit, ok := request.CheckPositiveInt(params, "limit")
+	if !ok {
+		return 0, 0, false
+	}
+	if limit == 0 {
+		limit = 20
+	}
+	offset, ok := request.CheckPositiveInt(params, "offset")
+	if !ok {
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "Internal server error"
+	switch {
+	case errors.Is(err, ErrValidationFailed):
+		status = http.StatusBadRequest
+		message = "Bad request"
+	case errors.Is(err, ErrCustomerNotFound):
+		status = http.StatusNotFound
+		message = "Not found"
+	}
+	response.JSON(w, status, response.ResponseBody{
+		Message: message,
+		Error:   err.Error(),
+	})
+}
+
+func getUserID(r *http.Request) (string, error) {
+	if authValue, ok := r.Context().Value(middleware.ContextAuthKey{}).(string); ok {
+		return authValue, nil
+	}
+
+	return "", errors.New("unauthorized")
+}
@@ -0,0 +1,159 @@
+package backoffice
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type Repository interface {
+	SearchCustomers(ctx context.Context, payload SearchCustomerPayload) ([]Customer, *response.Pagination, error)
+	GetCustomer(ctx context.Context, id string) (*Customer, error)
+	CreateNote(ctx context.Context, note *Note) error
+	ListNotes(ctx context.Context, payload ListNotePayload) ([]Note, *response.Pagination, error)
+}
+
+type dbRepository struct {
+	db *db.DB
+}
+
+func NewRepository(db *db.DB) Repository {
+	return &dbRepository{db: db}
+}
+
+const customerColumns = `
+	id::TEXT, email, name, email_verified_at, phone, preferred_currency, timezone, created_at, closed_at
+`
+
+func scanCustomer(scan func(dest ...any) error, c *Customer, extra ...any) error {
+	dest := append(extra, &c.ID, &c.Email, &c.Name, &c.EmailVerifiedAt, &c.Phone, &c.PreferredCurrency, &c.Timezone, &c.CreatedAt, &c.ClosedAt)
+	return scan(dest...)
+}
+
+// likeEscaper makes wildcards typed in a search match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchCustomers implements Repository.
+// An exact ID or email match is listed first, then the rest by ID. Queries shorter than
+// minSearchQueryLength only match the ID.
+func (d *dbRepository) SearchCustomers(ctx context.Context, payload SearchCustomerPayload) ([]Customer, *response.Pagination, error) {
+	resp := []Customer{}
+	pagination := &response.Pagination{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	}
+
+	selectQuery := `
+		SELECT COUNT(*) OVER() AS total_count, ` + customerColumns + `
+		FROM users
+		WHERE id::TEXT = $1 OR email ILIKE $2 OR name ILIKE $2
+		ORDER BY (id::TEXT = $1 OR lower(email) = lower($1)) DESC, id
+		LIMIT $3
+		OFFSET $4
+	`
+	// a NULL pattern matches nothing
+	var pattern *string
+	if utf8.RuneCountInString(payload.Query) >= minSearchQueryLength {
+		p := "%" + likeEscaper.Replace(payload.Query) + "%"
+		pattern = &p
+	}
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, payload.Query, pattern, payload.Limit, payload.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Customer
+		err = scanCustomer(rows.Scan, &c, &pagination.Total)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp = append(resp, c)
+	}
+
+	return resp, pagination, rows.Err()
+}
+
+// GetCustomer implements Repository.
+func (d *dbRepository) GetCustomer(ctx context.Context, id string) (*Customer, error) {
+	selectQuery := `
+		SELECT ` + customerColumns + `
+		FROM users
+		WHERE id = $1
+	`
+	c := &Customer{}
+	err := scanCustomer(d.db.DB().QueryRowContext(ctx, selectQuery, id).Scan, c)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCustomerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// CreateNote implements Repository.
+func (d *dbRepository) CreateNote(ctx context.Context, note *Note) error {
+	createNoteQuery := `
+		INSERT INTO customer_notes (
+			user_id, author_id, body
+		) VALUES (
+			$1, $2, $3
+		)
+		RETURNING id, created_at, (SELECT name FROM users WHERE id = author_id)
+	`
+	row := d.db.DB().QueryRowContext(ctx, createNoteQuery, note.CustomerID, note.AuthorID, note.Body)
+	err := row.Scan(&note.ID, &note.CreatedAt, &note.AuthorName)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == "fk_user_id" {
+		return ErrCustomerNotFound
+	}
+	return err
+}
+
+// ListNotes implements Repository.
+// It returns the customer's notes newest first.
+func (d *dbRepository) ListNotes(ctx context.Context, payload ListNotePayload) ([]Note, *response.Pagination, error) {
+	resp := []Note{}
+	pagination := &response.Pagination{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	}
+
+	selectQuery := `
+		SELECT COUNT(*) OVER() AS total_count, n.id, n.user_id::TEXT, n.author_id::TEXT, author.name, n.body, n.created_at
+		FROM customer_notes n
+		LEFT JOIN users author ON author.id = n.author_id
+		WHERE n.user_id = $1
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $2
+		OFFSET $3
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, payload.CustomerID, payload.Limit, payload.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var n Note
+		err = rows.Scan(&pagination.Total, &n.ID, &n.CustomerID, &n.AuthorID, &n.AuthorName, &n.Body, &n.CreatedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp = append(resp, n)
+	}
+
+	return resp, pagination, rows.Err()
+}
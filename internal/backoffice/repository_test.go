@@ -0,0 +1,26 @@
+package backoffice
+
+import "testing"
+
+func TestLikeEscaper(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "budi", want: "budi"},
+		{query: "100%", want: `100\%`},
+		{query: "budi_santoso", want: `budi\_santoso`},
+		{query: `back\slash`, want: `back\\slash`},
+		{query: `%_\`, want: `\%\_\\`},
+		{query: `\%`, want: `\\\%`},
+		{query: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := likeEscaper.Replace(tt.query); got != tt.want {
+				t.Errorf("likeEscaper.Replace(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package backoffice
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// minSearchQueryLength keeps substring searches, which read every email and name, specific enough to be worth it.
+const minSearchQueryLength = 3
+
+// SearchCustomerPayload matches Query against the user ID exactly and anywhere in the email or name.
+// Shorter queries than minSearchQueryLength must be a user ID and are only matched against it.
+type SearchCustomerPayload struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+func (p SearchCustomerPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Query, validation.Required, validation.Length(1, 255),
+			validation.When(is.Digit.Validate(p.Query) != nil, validation.RuneLength(minSearchQueryLength, 255))),
+	)
+}
+
+type ListTransactionPayload struct {
+	CustomerID string
+	Limit      int
+	Offset     int
+}
+
+type ListNotePayload struct {
+	CustomerID string
+	Limit      int
+	Offset     int
+}
+
+// AddNotePayload records a note about CustomerID written by AuthorID.
+type AddNotePayload struct {
+	Body       string `json:"body"`
+	CustomerID string `json:"-"`
+	AuthorID   string `json:"-"`
+}
+
+func (p AddNotePayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Body, validation.Required, validation.Length(1, 2000)),
+	)
+}
@@ -0,0 +1,33 @@
+package backoffice
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchCustomerPayloadValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "empty", query: "", wantErr: true},
+		{name: "one letter", query: "b", wantErr: true},
+		{name: "two letters", query: "bu", wantErr: true},
+		{name: "two runes", query: "日本", wantErr: true},
+		{name: "three letters", query: "bud", wantErr: false},
+		{name: "three runes", query: "日本語", wantErr: false},
+		{name: "short user ID", query: "7", wantErr: false},
+		{name: "email", query: "budi@example.com", wantErr: false},
+		{name: "too long", query: strings.Repeat("a", 256), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SearchCustomerPayload{Query: tt.query}.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package backoffice
+
+import userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+
+type CustomerResponse struct {
+	ID                string  `json:"id"`
+	Email             string  `json:"email"`
+	Name              string  `json:"name"`
+	EmailVerified     bool    `json:"emailVerified"`
+	Phone             *string `json:"phone"`
+	PreferredCurrency *string `json:"preferredCurrency"`
+	Timezone          string  `json:"timezone"`
+	CreatedAt         int64   `json:"createdAt"`
+	ClosedAt          *int64  `json:"closedAt"`
+}
+
+// CustomerDetailResponse is a customer with their balances.
+type CustomerDetailResponse struct {
+	CustomerResponse
+	Balances []userbalance.UserBalanceResponse `json:"balances"`
+}
+
+type NoteResponse struct {
+	ID         uint64  `json:"id"`
+	AuthorID   *string `json:"authorId"`
+	AuthorName *string `json:"authorName"`
+	Body       string  `json:"body"`
+	CreatedAt  int64   `json:"createdAt"`
+}
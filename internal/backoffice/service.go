@@ -0,0 +1,156 @@
+package backoffice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+type Service interface {
+	// Search finds customers by ID, email or name.
+	Search(ctx context.Context, req SearchCustomerPayload) ([]CustomerResponse, *response.Pagination, error)
+	// Get returns a customer's profile and balances.
+	Get(ctx context.Context, id string) (*CustomerDetailResponse, error)
+	// ListTransactions pages through a customer's whole transaction history, newest first.
+	ListTransactions(ctx context.Context, req ListTransactionPayload) ([]userbalance.UserTransactionResponse, *response.Pagination, error)
+	ListNotes(ctx context.Context, req ListNotePayload) ([]NoteResponse, *response.Pagination, error)
+	AddNote(ctx context.Context, req AddNotePayload) (*NoteResponse, error)
+}
+
+type backOfficeService struct {
+	repository Repository
+	balances   userbalance.Repository
+}
+
+func NewService(repository Repository, balances userbalance.Repository) Service {
+	return &backOfficeService{repository: repository, balances: balances}
+}
+
+func (s *backOfficeService) Search(ctx context.Context, req SearchCustomerPayload) ([]CustomerResponse, *response.Pagination, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	customers, pagination, err := s.repository.SearchCustomers(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := make([]CustomerResponse, len(customers))
+	for i, c := range customers {
+		resp[i] = toCustomerResponse(c)
+	}
+	return resp, pagination, nil
+}
+
+func (s *backOfficeService) Get(ctx context.Context, id string) (*CustomerDetailResponse, error) {
+	c, err := s.customer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.balances.FindByUserID(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &CustomerDetailResponse{
+		CustomerResponse: toCustomerResponse(*c),
+		Balances:         balances,
+	}, nil
+}
+
+func (s *backOfficeService) ListTransactions(ctx context.Context, req ListTransactionPayload) ([]userbalance.UserTransactionResponse, *response.Pagination, error) {
+	c, err := s.customer(ctx, req.CustomerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactions, pagination, err := s.balances.ListTransactions(ctx, userbalance.ListUserTransactionPayload{
+		UserID: c.ID,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := make([]userbalance.UserTransactionResponse, len(transactions))
+	for i, ut := range transactions {
+		resp[i] = userbalance.ToTransactionResponse(ut)
+	}
+	return resp, pagination, nil
+}
+
+func (s *backOfficeService) ListNotes(ctx context.Context, req ListNotePayload) ([]NoteResponse, *response.Pagination, error) {
+	_, err := s.customer(ctx, req.CustomerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	notes, pagination, err := s.repository.ListNotes(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := make([]NoteResponse, len(notes))
+	for i, n := range notes {
+		resp[i] = toNoteResponse(n)
+	}
+	return resp, pagination, nil
+}
+
+func (s *backOfficeService) AddNote(ctx context.Context, req AddNotePayload) (*NoteResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	c, err := s.customer(ctx, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	note := &Note{
+		CustomerID: c.ID,
+		AuthorID:   &req.AuthorID,
+		Body:       req.Body,
+	}
+	err = s.repository.CreateNote(ctx, note)
+	if err != nil {
+		return nil, err
+	}
+	resp := toNoteResponse(*note)
+	return &resp, nil
+}
+
+// customer looks up a customer by ID, treating IDs that cannot exist as not found.
+func (s *backOfficeService) customer(ctx context.Context, id string) (*Customer, error) {
+	_, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return nil, ErrCustomerNotFound
+	}
+	return s.repository.GetCustomer(ctx, id)
+}
+
+func toCustomerResponse(c Customer) CustomerResponse {
+	resp := CustomerResponse{
+		ID:                c.ID,
+		Email:             c.Email,
+		Name:              c.Name,
+		EmailVerified:     c.EmailVerifiedAt != nil,
+		Phone:             c.Phone,
+		PreferredCurrency: c.PreferredCurrency,
+		Timezone:          c.Timezone,
+		CreatedAt:         c.CreatedAt.UnixMilli(),
+	}
+	if c.ClosedAt != nil {
+		closedAt := c.ClosedAt.UnixMilli()
+		resp.ClosedAt = &closedAt
+	}
+	return resp
+}
+
+func toNoteResponse(n Note) NoteResponse {
+	return NoteResponse{
+		ID:         n.ID,
+		AuthorID:   n.AuthorID,
+		AuthorName: n.AuthorName,
+		Body:       n.Body,
+		CreatedAt:  n.CreatedAt.UnixMilli(),
+	}
+}
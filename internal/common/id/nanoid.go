@@ -9,3 +9,19 @@ const chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 func GenerateStringID(n int) string {
 	return gonanoid.MustGenerate(chars, n)
 }
+
+const referenceChars = "0123456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// GenerateReference returns an upper-case ID without look-alike letters, suitable for
+// customers to type into a bank transfer description.
+func GenerateReference(n int) string {
+	return gonanoid.MustGenerate(referenceChars, n)
+}
+
+const recoveryChars = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// GenerateRecoveryCode returns a lower-case code without look-alike characters, meant to be
+// written down on paper and typed back in.
+func GenerateRecoveryCode(n int) string {
+	return gonanoid.MustGenerate(recoveryChars, n)
+}
@@ -3,54 +3,99 @@ package jwt
 import (
 	"errors"
 	"fmt"
-	"os"
+	"slices"
 	"time"
 
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 var (
-	key = []byte(os.Getenv("JWT_SECRET"))
-
 	ErrUnknownClaims = errors.New("unknown claims type")
 	ErrTokenInvalid  = errors.New("invalid token")
+	ErrUnknownKey    = errors.New("token signed with an unknown key")
+	ErrNoSigningKey  = errors.New("no active signing key")
 )
 
-func Sign(ttl time.Duration, subject string) (string, error) {
+// Claims are the claims carried by access tokens. ID is the jti used to revoke a single token,
+// SessionID the login session the token was issued for, and Generation the subject's token
+// generation, which revoking all of the subject's tokens moves past.
+// Roles and Permissions are those the subject held when the token was issued.
+type Claims struct {
+	jwt.RegisteredClaims
+	SessionID   string   `json:"sid,omitempty"`
+	Generation  int64    `json:"gen,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// HasPermission reports whether the token grants permission.
+func (c *Claims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+// Sign issues a token for subject's session signed with the active key, whose ID is put in the kid header.
+func Sign(ttl time.Duration, subject, sessionID string, generation int64, roles, permissions []string) (string, error) {
+	key := keys.signingKey()
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
 	now := time.Now()
 	expiry := now.Add(ttl)
 	t := jwt.NewWithClaims(
-		jwt.SigningMethodHS256,
-		jwt.RegisteredClaims{
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(expiry),
-			Subject:   subject,
+		key.method(),
+		Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:        id.GenerateStringID(16),
+				IssuedAt:  jwt.NewNumericDate(now),
+				NotBefore: jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(expiry),
+				Subject:   subject,
+			},
+			SessionID:   sessionID,
+			Generation:  generation,
+			Roles:       roles,
+			Permissions: permissions,
 		},
 	)
-	return t.SignedString(key)
+	t.Header["kid"] = key.ID
+	return t.SignedString(key.Private)
 }
 
-func VerifyAndGetSubject(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+// Verify checks the signature and expiry of tokenString against the published keys and returns its claims.
+func Verify(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key := keys.verificationKey(kid)
+		if key == nil {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.method().Alg() {
 			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
 		}
 
-		return key, nil
-	})
+		return key.Private.Public(), nil
+	}, jwt.WithValidMethods([]string{AlgorithmEdDSA, AlgorithmRS256}))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Checking token validity
 	if !token.Valid {
-		return "", ErrTokenInvalid
+		return nil, ErrTokenInvalid
 	}
 
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok {
-		return claims.Subject, nil
+	if claims, ok := token.Claims.(*Claims); ok {
+		return claims, nil
 	} else {
-		return "", ErrUnknownClaims
+		return nil, ErrUnknownClaims
+	}
+}
+
+func VerifyAndGetSubject(tokenString string) (string, error) {
+	claims, err := Verify(tokenString)
+	if err != nil {
+		return "", err
 	}
+	return claims.Subject, nil
 }
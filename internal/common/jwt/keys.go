@@ -0,0 +1,160 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+
+	rsaKeyBits = 2048
+)
+
+// Key is a signing key pair. Tokens are signed with it from ActivatesAt, and it is published
+// for verification until ExpiresAt so tokens signed before a rotation stay valid.
+type Key struct {
+	ID          string
+	Algorithm   string
+	Private     crypto.Signer
+	ActivatesAt time.Time
+	ExpiresAt   time.Time
+}
+
+// GenerateKey creates a new key pair for algorithm.
+func GenerateKey(algorithm string, activatesAt, expiresAt time.Time) (*Key, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Key{
+		ID:          id.GenerateStringID(16),
+		Algorithm:   algorithm,
+		Private:     private,
+		ActivatesAt: activatesAt,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (k *Key) jwk() JWK {
+	jwk := JWK{
+		KeyID:     k.ID,
+		Use:       "sig",
+		Algorithm: k.Algorithm,
+	}
+	switch public := k.Private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// keySet holds the keys in use by this process. It is replaced as a whole on every rotation.
+type keySet struct {
+	mu   sync.RWMutex
+	keys []*Key
+}
+
+var keys = &keySet{}
+
+// UseKeys replaces the signing and verification keys.
+func UseKeys(k []*Key) {
+	keys.mu.Lock()
+	defer keys.mu.Unlock()
+	keys.keys = k
+}
+
+// signingKey returns the most recently activated key.
+func (s *keySet) signingKey() *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	var signing *Key
+	for _, k := range s.keys {
+		if k.ActivatesAt.After(now) || !k.ExpiresAt.After(now) {
+			continue
+		}
+		if signing == nil || k.ActivatesAt.After(signing.ActivatesAt) {
+			signing = k
+		}
+	}
+	return signing
+}
+
+func (s *keySet) verificationKey(kid string) *Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	for _, k := range s.keys {
+		if k.ID == kid && k.ExpiresAt.After(now) {
+			return k
+		}
+	}
+	return nil
+}
+
+// PublicKeys returns every unexpired key, including keys not active yet, so verifiers
+// can cache a key before the first token signed with it appears.
+func PublicKeys() JWKSet {
+	keys.mu.RLock()
+	defer keys.mu.RUnlock()
+	now := time.Now()
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range keys.keys {
+		if k.ExpiresAt.After(now) {
+			set.Keys = append(set.Keys, k.jwk())
+		}
+	}
+	return set
+}
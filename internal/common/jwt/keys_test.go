@@ -0,0 +1,204 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKey generates a key for algorithm, active from activatesAt until expiresAt.
+func testKey(t *testing.T, algorithm string, activatesAt, expiresAt time.Time) *Key {
+	t.Helper()
+	key, err := GenerateKey(algorithm, activatesAt, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// useTestKeys installs k for the rest of the test.
+func useTestKeys(t *testing.T, k ...*Key) {
+	t.Helper()
+	previous := keys.keys
+	UseKeys(k)
+	t.Cleanup(func() { UseKeys(previous) })
+}
+
+func TestSignVerify(t *testing.T) {
+	now := time.Now()
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmRS256} {
+		t.Run(algorithm, func(t *testing.T) {
+			key := testKey(t, algorithm, now.Add(-time.Minute), now.Add(time.Hour))
+			useTestKeys(t, key)
+
+			token, err := Sign(time.Minute, "42", "session", 3, []string{"admin"}, []string{"audit:read"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Header["kid"] != key.ID || parsed.Header["alg"] != algorithm {
+				t.Errorf("header = %v, want kid %s and alg %s", parsed.Header, key.ID, algorithm)
+			}
+
+			claims, err := Verify(token)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.Subject != "42" || claims.SessionID != "session" || claims.Generation != 3 || !claims.HasPermission("audit:read") {
+				t.Errorf("Verify() = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestSignWithoutActiveKey(t *testing.T) {
+	now := time.Now()
+	useTestKeys(t,
+		testKey(t, AlgorithmEdDSA, now.Add(time.Hour), now.Add(2*time.Hour)),
+		testKey(t, AlgorithmEdDSA, now.Add(-2*time.Hour), now.Add(-time.Hour)),
+	)
+	_, err := Sign(time.Minute, "42", "", 0, nil, nil)
+	if !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("Sign() error = %v, want %v", err, ErrNoSigningKey)
+	}
+}
+
+func TestSigningKeyIsNewestActive(t *testing.T) {
+	now := time.Now()
+	old := testKey(t, AlgorithmEdDSA, now.Add(-2*time.Hour), now.Add(time.Hour))
+	current := testKey(t, AlgorithmEdDSA, now.Add(-time.Hour), now.Add(2*time.Hour))
+	next := testKey(t, AlgorithmEdDSA, now.Add(time.Hour), now.Add(3*time.Hour))
+	useTestKeys(t, current, next, old)
+
+	if got := keys.signingKey(); got != current {
+		t.Errorf("signingKey() = %s, want %s", got.ID, current.ID)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	now := time.Now()
+	ed := testKey(t, AlgorithmEdDSA, now.Add(-time.Minute), now.Add(time.Hour))
+	rs := testKey(t, AlgorithmRS256, now.Add(-time.Minute), now.Add(time.Hour))
+	retired := testKey(t, AlgorithmEdDSA, now.Add(-3*time.Hour), now.Add(-time.Hour))
+	stranger := testKey(t, AlgorithmEdDSA, now.Add(-time.Minute), now.Add(time.Hour))
+	useTestKeys(t, ed, rs, retired)
+
+	claims := Claims{RegisteredClaims: jwt.RegisteredClaims{
+		Subject:   "42",
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}}
+	sign := func(method jwt.SigningMethod, kid string, key any) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "unknown kid", token: sign(jwt.SigningMethodEdDSA, stranger.ID, stranger.Private), wantErr: ErrUnknownKey},
+		{name: "missing kid", token: sign(jwt.SigningMethodEdDSA, "", ed.Private), wantErr: ErrUnknownKey},
+		{name: "key past its verify grace", token: sign(jwt.SigningMethodEdDSA, retired.ID, retired.Private), wantErr: ErrUnknownKey},
+		{name: "RS256 token naming an EdDSA key", token: sign(jwt.SigningMethodRS256, ed.ID, rs.Private), wantErr: jwt.ErrTokenUnverifiable},
+		{name: "EdDSA token naming an RS256 key", token: sign(jwt.SigningMethodEdDSA, rs.ID, ed.Private), wantErr: jwt.ErrTokenUnverifiable},
+		{name: "unsigned", token: sign(jwt.SigningMethodNone, ed.ID, jwt.UnsafeAllowNoneSignatureType), wantErr: jwt.ErrTokenSignatureInvalid},
+		{name: "signed by another key under a known kid", token: sign(jwt.SigningMethodEdDSA, ed.ID, stranger.Private), wantErr: jwt.ErrTokenSignatureInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	expired := claims
+	expired.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Minute))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodEdDSA, expired).SignedString(ed.Private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Verify(token); err == nil {
+		t.Errorf("Verify() accepted an expired token")
+	}
+}
+
+func TestPublicKeys(t *testing.T) {
+	now := time.Now()
+	active := testKey(t, AlgorithmEdDSA, now.Add(-time.Hour), now.Add(time.Hour))
+	upcoming := testKey(t, AlgorithmRS256, now.Add(time.Hour), now.Add(2*time.Hour))
+	retired := testKey(t, AlgorithmEdDSA, now.Add(-3*time.Hour), now.Add(-time.Hour))
+	useTestKeys(t, retired, active, upcoming)
+
+	set := PublicKeys()
+	if len(set.Keys) != 2 || set.Keys[0].KeyID != active.ID || set.Keys[1].KeyID != upcoming.ID {
+		t.Fatalf("PublicKeys() = %+v, want %s and %s", set.Keys, active.ID, upcoming.ID)
+	}
+
+	ed := set.Keys[0]
+	x, err := base64.RawURLEncoding.DecodeString(ed.X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ed.KeyType != "OKP" || ed.Curve != "Ed25519" || ed.Algorithm != AlgorithmEdDSA || ed.Use != "sig" ||
+		!ed25519.PublicKey(x).Equal(active.Private.Public()) {
+		t.Errorf("Ed25519 JWK = %+v", ed)
+	}
+
+	rs := set.Keys[1]
+	n, err := base64.RawURLEncoding.DecodeString(rs.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(rs.E)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if rs.KeyType != "RSA" || rs.Algorithm != AlgorithmRS256 || !public.Equal(upcoming.Private.Public()) {
+		t.Errorf("RSA JWK = %+v", rs)
+	}
+	js, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct{ Keys []map[string]any }
+	err = json.Unmarshal(js, &raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range raw.Keys {
+		for _, private := range []string{"d", "p", "q", "dp", "dq", "qi"} {
+			if _, ok := k[private]; ok {
+				t.Errorf("JWK %v carries private member %q", k["kid"], private)
+			}
+		}
+	}
+}
+
+func TestGenerateKeyUnsupportedAlgorithm(t *testing.T) {
+	if _, err := GenerateKey("HS256", time.Now(), time.Now().Add(time.Hour)); err == nil {
+		t.Errorf("GenerateKey(HS256) error = nil")
+	}
+}
@@ -0,0 +1,192 @@
+// Package keystore keeps the JWT signing keys in Postgres so every instance signs with
+// the same key and verifies tokens signed by any other, and rotates them on a schedule.
+package keystore
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	"github.com/citadel-corp/paimon-bank/internal/common/jwt"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/citadel-corp/paimon-bank/internal/common/secretbox"
+)
+
+const (
+	// publishAhead is how long a new key is listed in the JWKS before tokens are signed with it.
+	publishAhead = time.Hour
+	// verifyGrace keeps a key verifiable after its successor takes over, well beyond the access token lifetime.
+	verifyGrace = 24 * time.Hour
+	// rotationLockID serializes rotations across instances with a transaction-level advisory lock.
+	rotationLockID = 4201
+)
+
+// Rotator creates signing keys when the current one is due for rotation and installs the
+// unexpired keys for jwt.Sign and jwt.Verify. Private keys are stored encrypted.
+type Rotator struct {
+	db             *db.DB
+	algorithm      string
+	rotationPeriod time.Duration
+	box            *secretbox.Box
+}
+
+func NewRotator(db *db.DB, algorithm string, rotationPeriod time.Duration, encryptionSecret string) (*Rotator, error) {
+	if algorithm != jwt.AlgorithmEdDSA && algorithm != jwt.AlgorithmRS256 {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+	box, err := secretbox.New(encryptionSecret)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_KEY_ENCRYPTION_SECRET: %w", err)
+	}
+	return &Rotator{db: db, algorithm: algorithm, rotationPeriod: rotationPeriod, box: box}, nil
+}
+
+// Run rotates every interval until ctx is cancelled, which also picks up keys created by other instances.
+func (r *Rotator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := r.Rotate(ctx)
+			if err != nil {
+				slog.Error(fmt.Sprintf("Cannot rotate signing keys: %v", err))
+			}
+		}
+	}
+}
+
+// Rotate creates the next key once the newest one has been active for the rotation period minus
+// publishAhead, then loads all unexpired keys. The first key is created active immediately.
+func (r *Rotator) Rotate(ctx context.Context) error {
+	var keys []*jwt.Key
+	err := r.db.StartTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, rotationLockID)
+		if err != nil {
+			return err
+		}
+
+		newestQuery := `
+			SELECT MAX(activates_at)
+			FROM signing_keys
+			WHERE algorithm = $1 AND expires_at > current_timestamp
+		`
+		var newest sql.NullTime
+		err = tx.QueryRowContext(ctx, newestQuery, r.algorithm).Scan(&newest)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if !newest.Valid || !now.Before(newest.Time.Add(r.rotationPeriod-publishAhead)) {
+			activatesAt := now
+			if newest.Valid && newest.Time.Add(r.rotationPeriod).After(now) {
+				activatesAt = newest.Time.Add(r.rotationPeriod)
+			}
+			key, err := jwt.GenerateKey(r.algorithm, activatesAt, activatesAt.Add(r.rotationPeriod+verifyGrace))
+			if err != nil {
+				return err
+			}
+			err = r.insert(ctx, tx, key)
+			if err != nil {
+				return err
+			}
+			slog.Info(fmt.Sprintf("Created %s signing key %s active from %s", key.Algorithm, key.ID, key.ActivatesAt.Format(time.RFC3339)))
+		}
+
+		keys, err = r.load(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	jwt.UseKeys(keys)
+	return nil
+}
+
+func (r *Rotator) insert(ctx context.Context, tx *sql.Tx, key *jwt.Key) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return err
+	}
+	// the key ID is authenticated with the key so encrypted rows cannot be swapped
+	encrypted, err := r.box.Seal(der, []byte(key.ID))
+	if err != nil {
+		return err
+	}
+
+	createKeyQuery := `
+		INSERT INTO signing_keys (
+			kid, algorithm, encrypted_private_key, activates_at, expires_at
+		) VALUES (
+			$1, $2, $3, $4, $5
+		)
+	`
+	_, err = tx.ExecContext(ctx, createKeyQuery, key.ID, key.Algorithm, encrypted, key.ActivatesAt, key.ExpiresAt)
+	return err
+}
+
+func (r *Rotator) load(ctx context.Context, tx *sql.Tx) ([]*jwt.Key, error) {
+	var keys []*jwt.Key
+
+	selectKeysQuery := `
+		SELECT kid, algorithm, encrypted_private_key, activates_at, expires_at
+		FROM signing_keys
+		WHERE expires_at > current_timestamp
+		ORDER BY activates_at
+	`
+	rows, err := tx.QueryContext(ctx, selectKeysQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		key := &jwt.Key{}
+		var encrypted []byte
+		err = rows.Scan(&key.ID, &key.Algorithm, &encrypted, &key.ActivatesAt, &key.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		key.Private, err = r.decrypt(key.ID, encrypted)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", key.ID, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *Rotator) decrypt(kid string, encrypted []byte) (crypto.Signer, error) {
+	der, err := r.box.Open(encrypted, []byte(kid))
+	if err != nil {
+		return nil, err
+	}
+	private, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("stored key cannot sign")
+	}
+	return signer, nil
+}
+
+// JWKS serves the public verification keys as a JSON Web Key Set.
+func JWKS(w http.ResponseWriter, r *http.Request) {
+	headers := http.Header{}
+	headers.Set("Cache-Control", "public, max-age=300")
+	response.JSONWithHeaders(w, http.StatusOK, jwt.PublicKeys(), headers)
+}
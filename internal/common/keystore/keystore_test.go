@@ -0,0 +1,122 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/jwt"
+)
+
+func TestNewRotator(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		secret    string
+		wantErr   bool
+	}{
+		{name: "EdDSA", algorithm: jwt.AlgorithmEdDSA, secret: "secret"},
+		{name: "RS256", algorithm: jwt.AlgorithmRS256, secret: "secret"},
+		{name: "HMAC", algorithm: "HS256", secret: "secret", wantErr: true},
+		{name: "none", algorithm: "none", secret: "secret", wantErr: true},
+		{name: "no encryption secret", algorithm: jwt.AlgorithmEdDSA, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRotator(nil, tt.algorithm, time.Hour, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRotator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	r, err := NewRotator(nil, jwt.AlgorithmEdDSA, time.Hour, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewRotator(nil, jwt.AlgorithmEdDSA, time.Hour, "other secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, algorithm := range []string{jwt.AlgorithmEdDSA, jwt.AlgorithmRS256} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := jwt.GenerateKey(algorithm, time.Now(), time.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// sealed the way insert stores it
+			encrypted, err := r.box.Seal(der, []byte(key.ID))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			signer, err := r.decrypt(key.ID, encrypted)
+			if err != nil {
+				t.Fatalf("decrypt() error = %v", err)
+			}
+			public, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+			if !ok || !public.Equal(key.Private.Public()) {
+				t.Errorf("decrypt() returned a different key")
+			}
+
+			if _, err = r.decrypt("another-kid", encrypted); err == nil {
+				t.Errorf("decrypt() opened a key stored under another kid")
+			}
+			if _, err = other.decrypt(key.ID, encrypted); err == nil {
+				t.Errorf("decrypt() opened a key sealed with another secret")
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	now := time.Now()
+	published, err := jwt.GenerateKey(jwt.AlgorithmEdDSA, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	upcoming, err := jwt.GenerateKey(jwt.AlgorithmEdDSA, now.Add(publishAhead), now.Add(2*publishAhead))
+	if err != nil {
+		t.Fatal(err)
+	}
+	retired, err := jwt.GenerateKey(jwt.AlgorithmEdDSA, now.Add(-verifyGrace-time.Hour), now.Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwt.UseKeys([]*jwt.Key{retired, published, upcoming})
+	t.Cleanup(func() { jwt.UseKeys(nil) })
+
+	w := httptest.NewRecorder()
+	JWKS(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("JWKS status = %d", w.Code)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=300" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+	var set jwt.JWKSet
+	err = json.Unmarshal(w.Body.Bytes(), &set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kids []string
+	for _, k := range set.Keys {
+		kids = append(kids, k.KeyID)
+	}
+	if len(kids) != 2 || kids[0] != published.ID || kids[1] != upcoming.ID {
+		t.Errorf("JWKS kids = %v, want %s and %s but not %s", kids, published.ID, upcoming.ID, retired.ID)
+	}
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	LoginFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "login_failures_total",
+		Help: "Logins rejected for a wrong email or password.",
+	})
+	// LoginLockouts counts failures that locked an account or an IP address, by scope.
+	LoginLockouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "login_lockouts_total",
+		Help: "Login lockouts of an account or IP address after repeated failures.",
+	}, []string{"scope"})
+	// LoginThrottled counts logins refused without checking the password because of an earlier delay or lockout.
+	LoginThrottled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "login_throttled_total",
+		Help: "Login attempts refused while an account or IP address was delayed or locked.",
+	}, []string{"scope"})
+)
@@ -9,6 +9,37 @@ import (
 
 type ContextAuthKey struct{}
 
+type ContextClaimsKey struct{}
+
+// RevocationChecker reports whether an otherwise valid access token has been revoked.
+type RevocationChecker interface {
+	IsRevoked(claims *jwt.Claims) bool
+}
+
+var revocations RevocationChecker
+
+// UseRevocationChecker makes Authorized and Authenticate reject revoked tokens.
+func UseRevocationChecker(checker RevocationChecker) {
+	revocations = checker
+}
+
+// GetClaims returns the access token claims of an authorized request.
+func GetClaims(r *http.Request) (*jwt.Claims, bool) {
+	claims, ok := r.Context().Value(ContextClaimsKey{}).(*jwt.Claims)
+	return claims, ok
+}
+
+func verify(tokenString string) (*jwt.Claims, error) {
+	claims, err := jwt.Verify(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if revocations != nil && revocations.IsRevoked(claims) {
+		return nil, jwt.ErrTokenInvalid
+	}
+	return claims, nil
+}
+
 func Authorized(next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -30,13 +61,14 @@ func Authorized(next func(w http.ResponseWriter, r *http.Request)) func(w http.R
 			return
 		}
 
-		subject, err := jwt.VerifyAndGetSubject(tokenString)
+		claims, err := verify(tokenString)
 		if err != nil {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), ContextAuthKey{}, subject)
+		ctx := context.WithValue(r.Context(), ContextAuthKey{}, claims.Subject)
+		ctx = context.WithValue(ctx, ContextClaimsKey{}, claims)
 		r = r.WithContext(ctx)
 
 		next(w, r)
@@ -65,13 +97,14 @@ func Authenticate(next func(w http.ResponseWriter, r *http.Request)) func(w http
 			return
 		}
 
-		subject, err := jwt.VerifyAndGetSubject(tokenString)
+		claims, err := verify(tokenString)
 		if err != nil {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), ContextAuthKey{}, subject)
+		ctx := context.WithValue(r.Context(), ContextAuthKey{}, claims.Subject)
+		ctx = context.WithValue(ctx, ContextClaimsKey{}, claims)
 		r = r.WithContext(ctx)
 
 		next(w, r)
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
@@ -32,10 +33,20 @@ func (w *LogResponseWriter) Write(body []byte) (int, error) {
 	return w.ResponseWriter.Write(body)
 }
 
+type ContextRequestIDKey struct{}
+
+// GetRequestID returns the ID assigned to the request by Logging.
+func GetRequestID(r *http.Request) string {
+	requestID, _ := r.Context().Value(ContextRequestIDKey{}).(string)
+	return requestID
+}
+
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		startTime := time.Now()
 		requestID := id.GenerateStringID(12)
+		w.Header().Set("X-Request-ID", requestID)
+		r = r.WithContext(context.WithValue(r.Context(), ContextRequestIDKey{}, requestID))
 		logRespWriter := NewLogResponseWriter(w)
 		next.ServeHTTP(logRespWriter, r)
 
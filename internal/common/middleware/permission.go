@@ -0,0 +1,19 @@
+package middleware
+
+import "net/http"
+
+// RequirePermission only lets authorized requests through whose access token grants permission.
+// Permissions are read from the token, so a role change takes effect once the user's tokens are reissued.
+func RequirePermission(permission string) func(next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+	return func(next func(w http.ResponseWriter, r *http.Request)) func(w http.ResponseWriter, r *http.Request) {
+		return Authorized(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetClaims(r)
+			if !ok || !claims.HasPermission(permission) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			next(w, r)
+		})
+	}
+}
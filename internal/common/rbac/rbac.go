@@ -0,0 +1,78 @@
+package rbac
+
+import (
+	"slices"
+	"sort"
+)
+
+// Permissions name a back-office action as resource:action. Access tokens carry the permissions
+// of the user's roles and routes demand them with middleware.RequirePermission.
+const (
+	AuditRead        = "audit:read"
+	BalancesManage   = "balances:manage"
+	TransfersManage  = "transfers:manage"
+	StatementsManage = "statements:manage"
+	DepositsSimulate = "deposits:simulate"
+	EscrowsCancel    = "escrows:cancel"
+	CurrenciesManage = "currencies:manage"
+	RolesManage      = "roles:manage"
+	CustomersRead    = "customers:read"
+	NotesWrite       = "notes:write"
+)
+
+const (
+	// RoleAdmin holds every permission, including assigning roles.
+	RoleAdmin = "admin"
+	// RoleOperations runs payouts, statement reconciliation and dispute handling.
+	RoleOperations = "operations"
+	// RoleAuditor can read the audit log but change nothing.
+	RoleAuditor = "auditor"
+	// RoleSupport looks up customers and keeps notes about them.
+	RoleSupport = "support"
+)
+
+var rolePermissions = map[string][]string{
+	RoleAdmin: {
+		AuditRead, BalancesManage, TransfersManage, StatementsManage, DepositsSimulate, EscrowsCancel, CurrenciesManage, RolesManage,
+		CustomersRead, NotesWrite,
+	},
+	RoleOperations: {
+		BalancesManage, TransfersManage, StatementsManage, DepositsSimulate, EscrowsCancel, CurrenciesManage, CustomersRead,
+	},
+	RoleAuditor: {
+		AuditRead,
+	},
+	RoleSupport: {
+		CustomersRead, NotesWrite,
+	},
+}
+
+// ValidRole reports whether role is one of the roles defined above.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Roles returns the names of every role, sorted.
+func Roles() []string {
+	roles := make([]string, 0, len(rolePermissions))
+	for role := range rolePermissions {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Permissions returns the sorted union of the permissions of roles. Unknown roles grant nothing.
+func Permissions(roles []string) []string {
+	permissions := []string{}
+	for _, role := range roles {
+		for _, permission := range rolePermissions[role] {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
@@ -0,0 +1,76 @@
+package rbac
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestPermissions(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		want  []string
+	}{
+		{name: "no roles", roles: nil, want: []string{}},
+		{name: "unknown role", roles: []string{"superuser"}, want: []string{}},
+		{name: "auditor", roles: []string{RoleAuditor}, want: []string{AuditRead}},
+		{name: "support", roles: []string{RoleSupport}, want: []string{CustomersRead, NotesWrite}},
+		{
+			name:  "union is sorted and without duplicates",
+			roles: []string{RoleSupport, RoleAuditor, RoleSupport},
+			want:  []string{AuditRead, CustomersRead, NotesWrite},
+		},
+		{
+			name:  "unknown role among known ones",
+			roles: []string{"superuser", RoleAuditor},
+			want:  []string{AuditRead},
+		},
+		{
+			name:  "operations and support overlap on customers",
+			roles: []string{RoleOperations, RoleSupport},
+			want: []string{
+				BalancesManage, CurrenciesManage, CustomersRead, DepositsSimulate, EscrowsCancel, NotesWrite,
+				StatementsManage, TransfersManage,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Permissions(tt.roles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Permissions(%v) = %v, want %v", tt.roles, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdminHoldsEveryPermission(t *testing.T) {
+	admin := Permissions([]string{RoleAdmin})
+	if got := Permissions(Roles()); !reflect.DeepEqual(got, admin) {
+		t.Errorf("permissions of every role = %v, admin has %v", got, admin)
+	}
+	if !slices.Contains(admin, RolesManage) {
+		t.Errorf("admin cannot manage roles")
+	}
+}
+
+func TestValidRole(t *testing.T) {
+	for _, role := range Roles() {
+		if !ValidRole(role) {
+			t.Errorf("ValidRole(%q) = false", role)
+		}
+	}
+	for _, role := range []string{"", "Admin", "superuser"} {
+		if ValidRole(role) {
+			t.Errorf("ValidRole(%q) = true", role)
+		}
+	}
+}
+
+func TestRoles(t *testing.T) {
+	want := []string{RoleAdmin, RoleAuditor, RoleOperations, RoleSupport}
+	if got := Roles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Roles() = %v, want %v", got, want)
+	}
+}
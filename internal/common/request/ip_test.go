@@ -0,0 +1,113 @@
+package request
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		wantErr bool
+	}{
+		{name: "empty", list: ""},
+		{name: "cidrs and addresses", list: "10.0.0.0/8, 192.168.1.10,fd00::/8"},
+		{name: "invalid address", list: "10.0.0.300", wantErr: true},
+		{name: "invalid cidr", list: "10.0.0.0/40", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := UseTrustedProxies(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UseTrustedProxies(%q) error = %v, want error %v", tt.list, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		proxies    string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{
+			name:       "no proxy",
+			remoteAddr: "203.0.113.7:4321",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forwarded header from an untrusted client",
+			remoteAddr: "203.0.113.7:4321",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forwarded by a trusted proxy",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "spoofed entries left of the client are ignored",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"1.2.3.4, 198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "chain of trusted proxies",
+			proxies:    "10.0.0.0/8, 192.168.1.10",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"1.2.3.4, 198.51.100.1, 192.168.1.10", "10.0.0.3"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "only trusted proxies",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"10.0.0.5"},
+			want:       "10.0.0.5",
+		},
+		{
+			name:       "malformed entry",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.2:4321",
+			forwarded:  []string{"198.51.100.1, unknown"},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "trusted proxy without header",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.2:4321",
+			want:       "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := UseTrustedProxies(tt.proxies); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { trustedProxies = nil })
+
+			r, err := http.NewRequest(http.MethodGet, "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package revocation tracks access tokens that were revoked before they expired.
+//
+// Revocations are written to Postgres and kept in memory, so checking a token on every
+// request never touches the database. Each instance reloads recent revocations
+// periodically to pick up logouts handled by other instances.
+package revocation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	"github.com/citadel-corp/paimon-bank/internal/common/jwt"
+)
+
+type Store struct {
+	db *db.DB
+
+	mu sync.RWMutex
+	// tokens maps a revoked jti to the expiry of its token, after which it can be forgotten.
+	tokens map[string]time.Time
+	// users maps a user ID to the token generation below which all their tokens are revoked.
+	users map[string]int64
+	// syncedAt is the database time of the last reload.
+	syncedAt time.Time
+}
+
+func NewStore(db *db.DB) *Store {
+	return &Store{
+		db:     db,
+		tokens: map[string]time.Time{},
+		users:  map[string]int64{},
+	}
+}
+
+// IsRevoked reports whether claims belong to a revoked token or to a user who logged out everywhere since it was issued.
+func (s *Store) IsRevoked(claims *jwt.Claims) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.tokens[claims.ID]; ok {
+		return true
+	}
+	return claims.Generation < s.users[claims.Subject]
+}
+
+// RevokeToken revokes a single access token until it expires.
+func (s *Store) RevokeToken(ctx context.Context, claims *jwt.Claims) error {
+	expiresAt := time.Now().Add(time.Hour)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	revokeTokenQuery := `
+		INSERT INTO revoked_tokens (
+			jti, user_id, expires_at
+		) VALUES (
+			$1, $2, $3
+		)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	_, err := s.db.DB().ExecContext(ctx, revokeTokenQuery, claims.ID, claims.Subject, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.tokens[claims.ID] = expiresAt
+	s.mu.Unlock()
+	return nil
+}
+
+// RevokeUser revokes every access token of the user signed so far by moving to the next token generation.
+// Unlike an issue time cutoff, this also catches tokens signed in the same second as the revocation.
+func (s *Store) RevokeUser(ctx context.Context, userID string) error {
+	revokeUserQuery := `
+		INSERT INTO revoked_user_tokens (
+			user_id, generation
+		) VALUES (
+			$1, 1
+		)
+		ON CONFLICT (user_id)
+		DO UPDATE
+			SET generation = revoked_user_tokens.generation + 1, updated_at = current_timestamp
+		RETURNING generation
+	`
+	var generation int64
+	err := s.db.DB().QueryRowContext(ctx, revokeUserQuery, userID).Scan(&generation)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if generation > s.users[userID] {
+		s.users[userID] = generation
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Generation returns the user's current token generation, read from the database
+// so that a revocation handled by another instance is never missed.
+func (s *Store) Generation(ctx context.Context, userID string) (int64, error) {
+	selectGenerationQuery := `
+		SELECT generation
+		FROM revoked_user_tokens
+		WHERE user_id = $1
+	`
+	var generation int64
+	err := s.db.DB().QueryRowContext(ctx, selectGenerationQuery, userID).Scan(&generation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return generation, err
+}
+
+// Load reads all revocations that can still matter. Call it once before serving requests.
+func (s *Store) Load(ctx context.Context) error {
+	return s.reload(ctx, time.Time{})
+}
+
+// Run reloads revocations recorded by other instances every interval and forgets
+// revocations of tokens that have expired, until ctx is cancelled.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.RLock()
+			// overlap the window so rows committed late are not missed
+			since := s.syncedAt.Add(-interval)
+			s.mu.RUnlock()
+			err := s.reload(ctx, since)
+			if err != nil {
+				slog.Error(fmt.Sprintf("Cannot reload token revocations: %v", err))
+			}
+			s.prune()
+		}
+	}
+}
+
+func (s *Store) reload(ctx context.Context, since time.Time) error {
+	var now time.Time
+	err := s.db.DB().QueryRowContext(ctx, `SELECT current_timestamp`).Scan(&now)
+	if err != nil {
+		return err
+	}
+
+	selectTokensQuery := `
+		SELECT jti, expires_at
+		FROM revoked_tokens
+		WHERE expires_at > current_timestamp AND created_at >= $1
+	`
+	rows, err := s.db.DB().QueryContext(ctx, selectTokensQuery, since)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	tokens := map[string]time.Time{}
+	for rows.Next() {
+		var jti string
+		var expiresAt time.Time
+		err = rows.Scan(&jti, &expiresAt)
+		if err != nil {
+			return err
+		}
+		tokens[jti] = expiresAt
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	selectUsersQuery := `
+		SELECT user_id, generation
+		FROM revoked_user_tokens
+		WHERE updated_at >= $1
+	`
+	userRows, err := s.db.DB().QueryContext(ctx, selectUsersQuery, since)
+	if err != nil {
+		return err
+	}
+	defer userRows.Close()
+	users := map[string]int64{}
+	for userRows.Next() {
+		var userID string
+		var generation int64
+		err = userRows.Scan(&userID, &generation)
+		if err != nil {
+			return err
+		}
+		users[userID] = generation
+	}
+	if err = userRows.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, expiresAt := range tokens {
+		s.tokens[jti] = expiresAt
+	}
+	for userID, generation := range users {
+		if generation > s.users[userID] {
+			s.users[userID] = generation
+		}
+	}
+	s.syncedAt = now
+	return nil
+}
+
+func (s *Store) prune() {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, expiresAt := range s.tokens {
+		if expiresAt.Before(now) {
+			delete(s.tokens, jti)
+		}
+	}
+}
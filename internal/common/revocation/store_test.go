@@ -0,0 +1,41 @@
+package revocation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/jwt"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+func TestStoreIsRevoked(t *testing.T) {
+	store := NewStore(nil)
+	store.tokens["revoked-jti"] = time.Now().Add(time.Hour)
+	store.users["1"] = 2
+
+	claims := func(jti, subject string, generation int64) *jwt.Claims {
+		return &jwt.Claims{
+			RegisteredClaims: gojwt.RegisteredClaims{ID: jti, Subject: subject},
+			Generation:       generation,
+		}
+	}
+	tests := []struct {
+		name   string
+		claims *jwt.Claims
+		want   bool
+	}{
+		{name: "revoked token", claims: claims("revoked-jti", "2", 0), want: true},
+		{name: "user without revocations", claims: claims("jti", "2", 0), want: false},
+		{name: "older generation", claims: claims("jti", "1", 1), want: true},
+		{name: "token without generation", claims: claims("jti", "1", 0), want: true},
+		{name: "current generation", claims: claims("jti", "1", 2), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := store.IsRevoked(tt.claims); got != tt.want {
+				t.Errorf("IsRevoked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
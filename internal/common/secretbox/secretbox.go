@@ -0,0 +1,60 @@
+// Package secretbox encrypts small secrets at rest with AES-256-GCM under a key derived from a configured secret.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+)
+
+var (
+	ErrNoSecret      = errors.New("encryption secret is not set")
+	ErrMalformedBox  = errors.New("ciphertext is too short")
+	ErrCannotDecrypt = errors.New("ciphertext cannot be decrypted with this secret")
+)
+
+type Box struct {
+	aead cipher.AEAD
+}
+
+func New(secret string) (*Box, error) {
+	if secret == "" {
+		return nil, ErrNoSecret
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal encrypts plaintext with a random nonce prepended to the result. additionalData is
+// authenticated but not stored, so a ciphertext only opens for the record it was sealed for.
+func (b *Box) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func (b *Box) Open(ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < b.aead.NonceSize() {
+		return nil, ErrMalformedBox
+	}
+	nonce, sealed := ciphertext[:b.aead.NonceSize()], ciphertext[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, ErrCannotDecrypt
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,118 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 4226 and RFC 6238 test vectors.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode(t *testing.T) {
+	// RFC 4226 appendix D, and RFC 6238 appendix B truncated to 6 digits
+	tests := []struct {
+		name string
+		step int64
+		want string
+	}{
+		{name: "counter 0", step: 0, want: "755224"},
+		{name: "counter 1", step: 1, want: "287082"},
+		{name: "counter 2", step: 2, want: "359152"},
+		{name: "counter 9", step: 9, want: "520489"},
+		{name: "time 59", step: Step(time.Unix(59, 0)), want: "287082"},
+		{name: "time 1111111109", step: Step(time.Unix(1111111109, 0)), want: "081804"},
+		{name: "time 1111111111", step: Step(time.Unix(1111111111, 0)), want: "050471"},
+		{name: "time 1234567890", step: Step(time.Unix(1234567890, 0)), want: "005924"},
+		{name: "time 2000000000", step: Step(time.Unix(2000000000, 0)), want: "279037"},
+		{name: "time 20000000000", step: Step(time.Unix(20000000000, 0)), want: "353130"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(rfcSecret, tt.step); got != tt.want {
+				t.Errorf("Code(step %d) = %s, want %s", tt.step, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: Code(rfcSecret, current), wantStep: current, wantOK: true},
+		{name: "previous step", code: Code(rfcSecret, current-1), wantStep: current - 1, wantOK: true},
+		{name: "next step", code: Code(rfcSecret, current+1), wantStep: current + 1, wantOK: true},
+		{name: "two steps ago", code: Code(rfcSecret, current-2)},
+		{name: "two steps ahead", code: Code(rfcSecret, current+2)},
+		{name: "replayed", code: Code(rfcSecret, current), lastStep: current},
+		{name: "older than the last used step", code: Code(rfcSecret, current-1), lastStep: current - 1},
+		{name: "later than the last used step", code: Code(rfcSecret, current+1), lastStep: current, wantStep: current + 1, wantOK: true},
+		{name: "wrong code", code: "000000"},
+		{name: "too short", code: Code(rfcSecret, current)[:5]},
+		{name: "too long", code: Code(rfcSecret, current) + "0"},
+		{name: "empty", code: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.lastStep)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate(%q) = %d, %v, want %d, %v", tt.code, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	u, err := url.Parse(URI("Paimon Bank", "budi@example.com", secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Errorf("URI scheme and host = %s://%s, want otpauth://totp", u.Scheme, u.Host)
+	}
+	if u.Path != "/Paimon Bank:budi@example.com" {
+		t.Errorf("URI label = %q", u.Path)
+	}
+	want := map[string]string{
+		"secret":    "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"issuer":    "Paimon Bank",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	query := u.Query()
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("URI %s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != secretSize || len(second) != secretSize {
+		t.Errorf("secrets are %d and %d bytes, want %d", len(first), len(second), secretSize)
+	}
+	if string(first) == string(second) {
+		t.Errorf("two generated secrets are equal")
+	}
+}
@@ -0,0 +1,14 @@
+package currency
+
+import "time"
+
+type Currency struct {
+	Code          string
+	Enabled       bool
+	MinAmount     int
+	MaxAmount     *int
+	Symbol        string
+	DecimalPlaces int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,11 @@
+package currency
+
+import "errors"
+
+var (
+	ErrCurrencyNotFound     = errors.New("currency not found")
+	ErrCurrencyNotSupported = errors.New("currency is not supported")
+	ErrAmountBelowMinimum   = errors.New("amount is below the currency minimum")
+	ErrAmountAboveMaximum   = errors.New("amount is above the currency maximum")
+	ErrValidationFailed     = errors.New("validation failed")
+)
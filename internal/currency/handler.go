@@ -0,0 +1,77 @@
+package currency
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns the currencies customers can use.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, ListCurrencyPayload{EnabledOnly: true})
+}
+
+// ListAll returns every registered currency, including disabled ones.
+func (h *Handler) ListAll(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, ListCurrencyPayload{})
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, req ListCurrencyPayload) {
+	currencies, err := h.service.List(r.Context(), req)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, response.ResponseBody{
+			Message: "Internal server error",
+			Error:   err.Error(),
+		})
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    currencies,
+	})
+}
+
+func (h *Handler) Upsert(w http.ResponseWriter, r *http.Request) {
+	var req UpsertCurrencyPayload
+
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.Code = mux.Vars(r)["code"]
+
+	currencyResp, err := h.service.Upsert(r.Context(), req)
+	if errors.Is(err, ErrValidationFailed) {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Bad request",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, response.ResponseBody{
+			Message: "Internal server error",
+			Error:   err.Error(),
+		})
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "Currency updated successfully",
+		Data:    currencyResp,
+	})
+}
@@ -0,0 +1,89 @@
+package currency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/db"
+)
+
+type Repository interface {
+	Upsert(ctx context.Context, currency *Currency) error
+	GetByCode(ctx context.Context, code string) (*Currency, error)
+	List(ctx context.Context, payload ListCurrencyPayload) ([]Currency, error)
+}
+
+type dbRepository struct {
+	db *db.DB
+}
+
+func NewRepository(db *db.DB) Repository {
+	return &dbRepository{db: db}
+}
+
+// Upsert implements Repository.
+func (d *dbRepository) Upsert(ctx context.Context, currency *Currency) error {
+	upsertCurrencyQuery := `
+		INSERT INTO currencies (
+			code, enabled, min_amount, max_amount, symbol, decimal_places
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (code)
+		DO UPDATE
+			SET enabled = $2, min_amount = $3, max_amount = $4, symbol = $5, decimal_places = $6, updated_at = current_timestamp
+		RETURNING created_at, updated_at;
+	`
+	row := d.db.DB().QueryRowContext(ctx, upsertCurrencyQuery, currency.Code, currency.Enabled, currency.MinAmount, currency.MaxAmount, currency.Symbol, currency.DecimalPlaces)
+	return row.Scan(&currency.CreatedAt, &currency.UpdatedAt)
+}
+
+// GetByCode implements Repository.
+func (d *dbRepository) GetByCode(ctx context.Context, code string) (*Currency, error) {
+	getCurrencyQuery := `
+		SELECT code, enabled, min_amount, max_amount, symbol, decimal_places, created_at, updated_at
+		FROM currencies
+		WHERE code = $1;
+	`
+	row := d.db.DB().QueryRowContext(ctx, getCurrencyQuery, code)
+	c := &Currency{}
+	err := row.Scan(&c.Code, &c.Enabled, &c.MinAmount, &c.MaxAmount, &c.Symbol, &c.DecimalPlaces, &c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCurrencyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// List implements Repository.
+func (d *dbRepository) List(ctx context.Context, payload ListCurrencyPayload) ([]Currency, error) {
+	var resp []Currency
+
+	selectQuery := `
+		SELECT code, enabled, min_amount, max_amount, symbol, decimal_places, created_at, updated_at
+		FROM currencies
+		WHERE enabled OR NOT $1
+		ORDER BY code
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, payload.EnabledOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Currency
+		err = rows.Scan(&c.Code, &c.Enabled, &c.MinAmount, &c.MaxAmount, &c.Symbol, &c.DecimalPlaces, &c.CreatedAt, &c.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, c)
+	}
+
+	return resp, rows.Err()
+}
@@ -0,0 +1,30 @@
+package currency
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type UpsertCurrencyPayload struct {
+	Code          string `json:"-"`
+	Enabled       *bool  `json:"enabled"`
+	MinAmount     int    `json:"minAmount"`
+	MaxAmount     *int   `json:"maxAmount"`
+	Symbol        string `json:"symbol"`
+	DecimalPlaces int    `json:"decimalPlaces"`
+}
+
+func (p UpsertCurrencyPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Code, validation.Required, is.CurrencyCode),
+		validation.Field(&p.Enabled, validation.NotNil),
+		validation.Field(&p.MinAmount, validation.Min(0)),
+		validation.Field(&p.MaxAmount, validation.When(p.MaxAmount != nil, validation.Min(p.MinAmount))),
+		validation.Field(&p.Symbol, validation.Required, validation.Length(1, 8)),
+		validation.Field(&p.DecimalPlaces, validation.Min(0), validation.Max(4)),
+	)
+}
+
+type ListCurrencyPayload struct {
+	EnabledOnly bool
+}
@@ -0,0 +1,10 @@
+package currency
+
+type CurrencyResponse struct {
+	Code          string `json:"code"`
+	Enabled       bool   `json:"enabled"`
+	MinAmount     int    `json:"minAmount"`
+	MaxAmount     *int   `json:"maxAmount"`
+	Symbol        string `json:"symbol"`
+	DecimalPlaces int    `json:"decimalPlaces"`
+}
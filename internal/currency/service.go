@@ -0,0 +1,104 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type Service interface {
+	Upsert(ctx context.Context, req UpsertCurrencyPayload) (*CurrencyResponse, error)
+	List(ctx context.Context, req ListCurrencyPayload) ([]CurrencyResponse, error)
+	// ValidateAmount checks that code is an enabled currency and amount is within its limits.
+	ValidateAmount(ctx context.Context, code string, amount int) error
+	// ValidateCode checks that code is an enabled currency.
+	ValidateCode(ctx context.Context, code string) error
+}
+
+type currencyService struct {
+	repository Repository
+}
+
+func NewService(repository Repository) Service {
+	return &currencyService{repository: repository}
+}
+
+func (s *currencyService) Upsert(ctx context.Context, req UpsertCurrencyPayload) (*CurrencyResponse, error) {
+	req.Code = strings.ToUpper(req.Code)
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	currency := &Currency{
+		Code:          req.Code,
+		Enabled:       *req.Enabled,
+		MinAmount:     req.MinAmount,
+		MaxAmount:     req.MaxAmount,
+		Symbol:        req.Symbol,
+		DecimalPlaces: req.DecimalPlaces,
+	}
+	err = s.repository.Upsert(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	resp := toResponse(*currency)
+	return &resp, nil
+}
+
+func (s *currencyService) List(ctx context.Context, req ListCurrencyPayload) ([]CurrencyResponse, error) {
+	currencies, err := s.repository.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]CurrencyResponse, len(currencies))
+	for i, c := range currencies {
+		resp[i] = toResponse(c)
+	}
+	return resp, nil
+}
+
+func (s *currencyService) ValidateAmount(ctx context.Context, code string, amount int) error {
+	currency, err := s.enabled(ctx, code)
+	if err != nil {
+		return err
+	}
+	if amount < currency.MinAmount {
+		return fmt.Errorf("%w of %d", ErrAmountBelowMinimum, currency.MinAmount)
+	}
+	if currency.MaxAmount != nil && amount > *currency.MaxAmount {
+		return fmt.Errorf("%w of %d", ErrAmountAboveMaximum, *currency.MaxAmount)
+	}
+	return nil
+}
+
+func (s *currencyService) ValidateCode(ctx context.Context, code string) error {
+	_, err := s.enabled(ctx, code)
+	return err
+}
+
+// enabled returns the currency, or ErrCurrencyNotSupported when it is unknown or disabled.
+func (s *currencyService) enabled(ctx context.Context, code string) (*Currency, error) {
+	currency, err := s.repository.GetByCode(ctx, code)
+	if errors.Is(err, ErrCurrencyNotFound) {
+		return nil, ErrCurrencyNotSupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !currency.Enabled {
+		return nil, ErrCurrencyNotSupported
+	}
+	return currency, nil
+}
+
+func toResponse(c Currency) CurrencyResponse {
+	return CurrencyResponse{
+		Code:          c.Code,
+		Enabled:       c.Enabled,
+		MinAmount:     c.MinAmount,
+		MaxAmount:     c.MaxAmount,
+		Symbol:        c.Symbol,
+		DecimalPlaces: c.DecimalPlaces,
+	}
+}
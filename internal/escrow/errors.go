@@ -0,0 +1,13 @@
+package escrow
+
+import "errors"
+
+var (
+	ErrValidationFailed = errors.New("validation failed")
+	ErrSellerNotFound   = errors.New("seller not found")
+	ErrSellerIsBuyer    = errors.New("cannot open an escrow with yourself")
+	ErrEscrowNotFound   = errors.New("escrow not found")
+	ErrEscrowClosed     = errors.New("escrow is already released or refunded")
+	ErrNotBuyer         = errors.New("only the buyer can confirm an escrow")
+	ErrNotSeller        = errors.New("only the seller can cancel an escrow")
+)
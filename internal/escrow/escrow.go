@@ -0,0 +1,34 @@
+package escrow
+
+import "time"
+
+type Status string
+
+const (
+	StatusHeld     Status = "held"
+	StatusReleased Status = "released"
+	StatusRefunded Status = "refunded"
+)
+
+// BankName is recorded as the counterparty bank on escrow transactions, with the escrow ID as account.
+const BankName = "Paimon Escrow"
+
+// Escrow parks a buyer's money until the buyer confirms delivery or ReleaseAfter passes,
+// when it goes to the seller, or until it is cancelled and refunded to the buyer.
+type Escrow struct {
+	ID                  string
+	BuyerID             string
+	BuyerName           string
+	SellerID            string
+	SellerName          string
+	Amount              int
+	Currency            string
+	Description         string
+	Status              Status
+	ReleaseAfter        time.Time
+	FundTransactionID   string
+	SettleTransactionID *string
+	CancelReason        *string
+	CreatedAt           time.Time
+	ClosedAt            *time.Time
+}
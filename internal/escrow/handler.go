@@ -0,0 +1,234 @@
+package escrow
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/middleware"
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateEscrowPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.BuyerID = userID
+
+	escrow, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, response.ResponseBody{
+		Message: "Escrow funded successfully",
+		Data:    escrow,
+	})
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	req := ListEscrowPayload{UserID: userID}
+	var params = r.URL.Query()
+	if v, ok := request.CheckPositiveInt(params, "limit"); ok {
+		req.Limit = v
+		if v == 0 {
+			req.Limit = 5
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if v, ok := request.CheckPositiveInt(params, "offset"); ok {
+		req.Offset = v
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	req.Role = Role(params.Get("role"))
+	req.Status = Status(params.Get("status"))
+
+	escrows, pagination, err := h.service.List(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    escrows,
+		Meta:    pagination,
+	})
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	escrow, err := h.service.Get(r.Context(), GetEscrowPayload{
+		ID:     mux.Vars(r)["id"],
+		UserID: userID,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    escrow,
+	})
+}
+
+func (h *Handler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req ConfirmEscrowPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.ID = mux.Vars(r)["id"]
+	req.UserID = userID
+
+	escrow, err := h.service.Confirm(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "Escrow released to the seller",
+		Data:    escrow,
+	})
+}
+
+// Cancel refunds the buyer at the seller's request.
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	h.cancel(w, r, userID)
+}
+
+// CancelByOperator refunds the buyer, for example to resolve a dispute.
+func (h *Handler) CancelByOperator(w http.ResponseWriter, r *http.Request) {
+	h.cancel(w, r, "")
+}
+
+func (h *Handler) cancel(w http.ResponseWriter, r *http.Request, userID string) {
+	var req CancelEscrowPayload
+
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.ID = mux.Vars(r)["id"]
+	req.UserID = userID
+
+	escrow, err := h.service.Cancel(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "Escrow refunded to the buyer",
+		Data:    escrow,
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "Internal server error"
+	switch {
+	case errors.Is(err, ErrValidationFailed),
+		errors.Is(err, userbalance.ErrPINNotSet),
+		errors.Is(err, ErrSellerIsBuyer),
+		errors.Is(err, userbalance.ErrNotEnoughBalance),
+		errors.Is(err, userbalance.ErrNoCurrencyOrUserRecorded),
+		errors.Is(err, userbalance.ErrAccountClosed):
+		status = http.StatusBadRequest
+		message = "Bad request"
+	case errors.Is(err, ErrNotBuyer),
+		errors.Is(err, ErrNotSeller),
+		errors.Is(err, userbalance.ErrWrongPIN),
+		errors.Is(err, userbalance.ErrStepUpRequired),
+		errors.Is(err, userbalance.ErrStepUpFailed),
+		errors.Is(err, userbalance.ErrEmailNotVerified):
+		status = http.StatusForbidden
+		message = "Forbidden"
+	case errors.Is(err, ErrSellerNotFound), errors.Is(err, ErrEscrowNotFound):
+		status = http.StatusNotFound
+		message = "Not found"
+	case errors.Is(err, ErrEscrowClosed):
+		status = http.StatusConflict
+		message = "Conflict"
+	case errors.Is(err, userbalance.ErrPINLocked),
+		errors.Is(err, userbalance.ErrStepUpLocked):
+		status = http.StatusLocked
+		message = "Locked"
+	}
+	response.JSON(w, status, response.ResponseBody{
+		Message: message,
+		Error:   err.Error(),
+	})
+}
+
+func getUserID(r *http.Request) (string, error) {
+	if authValue, ok := r.Context().Value(middleware.ContextAuthKey{}).(string); ok {
+		return authValue, nil
+	}
+
+	return "", errors.New("unauthorized")
+}
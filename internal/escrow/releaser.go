@@ -0,0 +1,40 @@
+package escrow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const releaseBatchSize = 50
+
+// Releaser pays held escrows to their sellers once the buyer's confirmation window has passed.
+type Releaser struct {
+	service  Service
+	interval time.Duration
+}
+
+func NewReleaser(service Service, interval time.Duration) *Releaser {
+	return &Releaser{service: service, interval: interval}
+}
+
+// Run releases due escrows every interval until ctx is cancelled.
+func (r *Releaser) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := r.service.ReleaseDue(ctx, releaseBatchSize)
+			if err != nil {
+				slog.Error(fmt.Sprintf("Cannot release due escrows: %v", err))
+			}
+			if released > 0 {
+				slog.Info(fmt.Sprintf("Released %d escrows after timeout", released))
+			}
+		}
+	}
+}
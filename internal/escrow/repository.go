@@ -0,0 +1,280 @@
+package escrow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+type Repository interface {
+	// Create debits the buyer into the escrow. The seller is resolved by email.
+	Create(ctx context.Context, e *Escrow, sellerEmail string, releaseAfterHours int) error
+	Get(ctx context.Context, id string) (*Escrow, error)
+	List(ctx context.Context, payload ListEscrowPayload) ([]Escrow, *response.Pagination, error)
+	// Release pays the escrow to the seller after check accepts it.
+	Release(ctx context.Context, id string, check func(Escrow) error) error
+	// Refund pays the escrow back to the buyer after check accepts it.
+	Refund(ctx context.Context, id, reason string, check func(Escrow) error) error
+	ListDue(ctx context.Context, limit int) ([]string, error)
+	// DeferRelease puts off releasing an escrow that failed to release, for longer after each failure.
+	DeferRelease(ctx context.Context, id string) error
+}
+
+type dbRepository struct {
+	db *db.DB
+}
+
+func NewRepository(db *db.DB) Repository {
+	return &dbRepository{db: db}
+}
+
+const selectColumns = `
+	e.id, e.buyer_id, buyer.name, e.seller_id, seller.name, e.amount, e.currency, e.description, e.status,
+	e.release_after, e.fund_transaction_id, e.settle_transaction_id, e.cancel_reason, e.created_at, e.closed_at
+`
+
+func scanEscrow(scan func(dest ...any) error, e *Escrow, extra ...any) error {
+	dest := append(extra, &e.ID, &e.BuyerID, &e.BuyerName, &e.SellerID, &e.SellerName, &e.Amount, &e.Currency, &e.Description, &e.Status,
+		&e.ReleaseAfter, &e.FundTransactionID, &e.SettleTransactionID, &e.CancelReason, &e.CreatedAt, &e.ClosedAt)
+	return scan(dest...)
+}
+
+// Create implements Repository.
+func (d *dbRepository) Create(ctx context.Context, e *Escrow, sellerEmail string, releaseAfterHours int) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		selectSellerQuery := `
+			SELECT id
+			FROM users
+			WHERE email = $1 AND closed_at IS NULL
+		`
+		err := tx.QueryRowContext(ctx, selectSellerQuery, sellerEmail).Scan(&e.SellerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSellerNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if e.SellerID == e.BuyerID {
+			return ErrSellerIsBuyer
+		}
+		// an account being closed waits for this escrow, or the escrow sees it closed, so a
+		// closed account never ends up holding one
+		err = userbalance.LockOpenUsers(ctx, tx, e.BuyerID, e.SellerID)
+		if err != nil {
+			return err
+		}
+
+		fund := &userbalance.HoldingMove{
+			UserID:   e.BuyerID,
+			Amount:   e.Amount,
+			Currency: e.Currency,
+			Account:  e.ID,
+			BankName: BankName,
+		}
+		err = userbalance.RecordHoldingDebit(ctx, tx, fund)
+		if err != nil {
+			return err
+		}
+		e.FundTransactionID = fund.TransactionID
+
+		createEscrowQuery := `
+			INSERT INTO escrows (
+				id, buyer_id, seller_id, amount, currency, description, release_after, fund_transaction_id
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, current_timestamp + make_interval(hours => $7), $8
+			)
+			RETURNING status, release_after, created_at
+		`
+		row := tx.QueryRowContext(ctx, createEscrowQuery, e.ID, e.BuyerID, e.SellerID, e.Amount, e.Currency, e.Description, releaseAfterHours, e.FundTransactionID)
+		return row.Scan(&e.Status, &e.ReleaseAfter, &e.CreatedAt)
+	})
+}
+
+// Get implements Repository.
+func (d *dbRepository) Get(ctx context.Context, id string) (*Escrow, error) {
+	selectQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM escrows e
+		JOIN users buyer ON buyer.id = e.buyer_id
+		JOIN users seller ON seller.id = e.seller_id
+		WHERE e.id = $1
+	`, selectColumns)
+	e := &Escrow{}
+	err := scanEscrow(d.db.DB().QueryRowContext(ctx, selectQuery, id).Scan, e)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEscrowNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// List implements Repository.
+func (d *dbRepository) List(ctx context.Context, payload ListEscrowPayload) ([]Escrow, *response.Pagination, error) {
+	resp := []Escrow{}
+	pagination := &response.Pagination{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	}
+
+	args := []any{payload.UserID}
+	var conditions string
+	switch payload.Role {
+	case RoleBuyer:
+		conditions = "e.buyer_id = $1"
+	case RoleSeller:
+		conditions = "e.seller_id = $1"
+	default:
+		conditions = "(e.buyer_id = $1 OR e.seller_id = $1)"
+	}
+	if payload.Status != "" {
+		args = append(args, payload.Status)
+		conditions += fmt.Sprintf(" AND e.status = $%d", len(args))
+	}
+	args = append(args, payload.Limit, payload.Offset)
+
+	selectQuery := fmt.Sprintf(`
+		SELECT COUNT(*) OVER() AS total_count, %s
+		FROM escrows e
+		JOIN users buyer ON buyer.id = e.buyer_id
+		JOIN users seller ON seller.id = e.seller_id
+		WHERE %s
+		ORDER BY e.created_at DESC
+		LIMIT $%d
+		OFFSET $%d
+	`, selectColumns, conditions, len(args)-1, len(args))
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e Escrow
+		err = scanEscrow(rows.Scan, &e, &pagination.Total)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp = append(resp, e)
+	}
+
+	return resp, pagination, rows.Err()
+}
+
+// Release implements Repository.
+func (d *dbRepository) Release(ctx context.Context, id string, check func(Escrow) error) error {
+	return d.close(ctx, id, check, StatusReleased, nil)
+}
+
+// Refund implements Repository.
+func (d *dbRepository) Refund(ctx context.Context, id, reason string, check func(Escrow) error) error {
+	return d.close(ctx, id, check, StatusRefunded, &reason)
+}
+
+// close locks a held escrow and pays it out to the seller when released or the buyer when refunded.
+func (d *dbRepository) close(ctx context.Context, id string, check func(Escrow) error, status Status, reason *string) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		selectQuery := fmt.Sprintf(`
+			SELECT %s
+			FROM escrows e
+			JOIN users buyer ON buyer.id = e.buyer_id
+			JOIN users seller ON seller.id = e.seller_id
+			WHERE e.id = $1
+			FOR UPDATE OF e
+		`, selectColumns)
+		e := &Escrow{}
+		err := scanEscrow(tx.QueryRowContext(ctx, selectQuery, id).Scan, e)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEscrowNotFound
+		}
+		if err != nil {
+			return err
+		}
+		err = check(*e)
+		if err != nil {
+			return err
+		}
+		if e.Status != StatusHeld {
+			return ErrEscrowClosed
+		}
+
+		payee := e.SellerID
+		if status == StatusRefunded {
+			payee = e.BuyerID
+		}
+		settle := &userbalance.HoldingMove{
+			UserID:   payee,
+			Amount:   e.Amount,
+			Currency: e.Currency,
+			Account:  e.ID,
+			BankName: BankName,
+		}
+		err = userbalance.RecordHoldingCredit(ctx, tx, settle)
+		if err != nil {
+			return err
+		}
+
+		updateQuery := `
+			UPDATE escrows
+			SET status = $1, settle_transaction_id = $2, cancel_reason = $3, closed_at = current_timestamp
+			WHERE id = $4
+		`
+		_, err = tx.ExecContext(ctx, updateQuery, status, settle.TransactionID, reason, e.ID)
+		return err
+	})
+}
+
+// ListDue implements Repository.
+// It returns held escrows whose release time has passed, oldest first, leaving out those
+// deferred after a failed release until their next attempt is due.
+func (d *dbRepository) ListDue(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+
+	selectQuery := `
+		SELECT id
+		FROM escrows
+		WHERE status = $1 AND release_after <= current_timestamp
+			AND (next_release_at IS NULL OR next_release_at <= current_timestamp)
+		ORDER BY release_after
+		LIMIT $2
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, StatusHeld, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// DeferRelease implements Repository.
+// The next attempt waits a minute after the first failure, doubling up to a day.
+func (d *dbRepository) DeferRelease(ctx context.Context, id string) error {
+	deferReleaseQuery := `
+		UPDATE escrows
+		SET release_attempts = release_attempts + 1,
+			next_release_at = current_timestamp + LEAST(interval '1 minute' * power(2, LEAST(release_attempts, 11)), interval '1 day')
+		WHERE id = $1 AND status = $2
+	`
+	_, err := d.db.DB().ExecContext(ctx, deferReleaseQuery, id, StatusHeld)
+	return err
+}
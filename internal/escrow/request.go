@@ -0,0 +1,87 @@
+package escrow
+
+import (
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type Role string
+
+const (
+	RoleBuyer  Role = "buyer"
+	RoleSeller Role = "seller"
+)
+
+// CreateEscrowPayload holds the buyer's funds; amounts at or above the step-up threshold also carry a step-up factor.
+type CreateEscrowPayload struct {
+	userbalance.StepUpFactor
+	SellerEmail       string `json:"sellerEmail"`
+	Amount            int    `json:"amount"`
+	Currency          string `json:"currency"`
+	Description       string `json:"description"`
+	ReleaseAfterHours int    `json:"releaseAfterHours"`
+	PIN               string `json:"pin"`
+	BuyerID           string
+}
+
+func (p CreateEscrowPayload) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.SellerEmail, validation.Required, is.EmailFormat),
+		validation.Field(&p.Amount, validation.Required, validation.Min(1)),
+		validation.Field(&p.Currency, validation.Required, is.CurrencyCode),
+		validation.Field(&p.Description, validation.Required, validation.Length(1, 255)),
+		validation.Field(&p.ReleaseAfterHours, validation.Min(1), validation.Max(maxReleaseAfterHours)),
+		validation.Field(&p.PIN, userbalance.PINRules...),
+	)
+	if err != nil {
+		return err
+	}
+	return p.StepUpFactor.Validate()
+}
+
+type ListEscrowPayload struct {
+	UserID string
+	Role   Role
+	Status Status
+	Limit  int
+	Offset int
+}
+
+func (p ListEscrowPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Role, validation.In(RoleBuyer, RoleSeller)),
+		validation.Field(&p.Status, validation.In(StatusHeld, StatusReleased, StatusRefunded)),
+	)
+}
+
+type GetEscrowPayload struct {
+	ID     string
+	UserID string
+}
+
+// ConfirmEscrowPayload releases the escrow to the seller; the buyer authorizes it with the transaction PIN.
+type ConfirmEscrowPayload struct {
+	ID     string `json:"-"`
+	UserID string `json:"-"`
+	PIN    string `json:"pin"`
+}
+
+func (p ConfirmEscrowPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.PIN, userbalance.PINRules...),
+	)
+}
+
+// CancelEscrowPayload refunds the buyer. UserID is empty when an operator cancels.
+type CancelEscrowPayload struct {
+	ID     string `json:"-"`
+	UserID string `json:"-"`
+	Reason string `json:"reason"`
+}
+
+func (p CancelEscrowPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Reason, validation.Required, validation.Length(1, 255)),
+	)
+}
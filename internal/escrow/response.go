@@ -0,0 +1,19 @@
+package escrow
+
+type EscrowResponse struct {
+	ID                  string  `json:"id"`
+	BuyerID             string  `json:"buyerId"`
+	BuyerName           string  `json:"buyerName"`
+	SellerID            string  `json:"sellerId"`
+	SellerName          string  `json:"sellerName"`
+	Amount              int     `json:"amount"`
+	Currency            string  `json:"currency"`
+	Description         string  `json:"description"`
+	Status              Status  `json:"status"`
+	ReleaseAfter        int64   `json:"releaseAfter"`
+	FundTransactionID   string  `json:"fundTransactionId"`
+	SettleTransactionID *string `json:"settleTransactionId"`
+	CancelReason        *string `json:"cancelReason"`
+	CreatedAt           int64   `json:"createdAt"`
+	ClosedAt            *int64  `json:"closedAt"`
+}
@@ -0,0 +1,214 @@
+package escrow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/citadel-corp/paimon-bank/internal/currency"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+const (
+	defaultReleaseAfterHours = 168
+	maxReleaseAfterHours     = 2160
+)
+
+type Service interface {
+	Create(ctx context.Context, req CreateEscrowPayload) (*EscrowResponse, error)
+	Get(ctx context.Context, req GetEscrowPayload) (*EscrowResponse, error)
+	List(ctx context.Context, req ListEscrowPayload) ([]EscrowResponse, *response.Pagination, error)
+	// Confirm is the buyer accepting delivery, releasing the funds to the seller.
+	Confirm(ctx context.Context, req ConfirmEscrowPayload) (*EscrowResponse, error)
+	// Cancel refunds the buyer. Only the seller or an operator can cancel.
+	Cancel(ctx context.Context, req CancelEscrowPayload) (*EscrowResponse, error)
+	// ReleaseDue releases escrows whose release time has passed and returns how many were released.
+	// Escrows that cannot be released are deferred, and their errors are joined in the returned error.
+	ReleaseDue(ctx context.Context, limit int) (int, error)
+}
+
+type escrowService struct {
+	repository      Repository
+	currencyService currency.Service
+	pins            userbalance.PINVerifier
+	stepUp          userbalance.StepUpPolicy
+}
+
+func NewService(repository Repository, currencyService currency.Service, pins userbalance.PINVerifier, stepUp userbalance.StepUpPolicy) Service {
+	return &escrowService{repository: repository, currencyService: currencyService, pins: pins, stepUp: stepUp}
+}
+
+func (s *escrowService) Create(ctx context.Context, req CreateEscrowPayload) (*EscrowResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	err = s.currencyService.ValidateAmount(ctx, req.Currency, req.Amount)
+	if errors.Is(err, currency.ErrCurrencyNotSupported) || errors.Is(err, currency.ErrAmountBelowMinimum) || errors.Is(err, currency.ErrAmountAboveMaximum) {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = s.pins.VerifyPIN(ctx, req.BuyerID, req.PIN)
+	if err != nil {
+		return nil, err
+	}
+	err = s.stepUp.Authorize(ctx, req.BuyerID, req.Currency, req.Amount, req.StepUpFactor)
+	if err != nil {
+		return nil, err
+	}
+	if req.ReleaseAfterHours == 0 {
+		req.ReleaseAfterHours = defaultReleaseAfterHours
+	}
+
+	e := &Escrow{
+		ID:          id.GenerateStringID(16),
+		BuyerID:     req.BuyerID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: req.Description,
+	}
+	err = s.repository.Create(ctx, e, req.SellerEmail, req.ReleaseAfterHours)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, e.ID, req.BuyerID)
+}
+
+func (s *escrowService) Get(ctx context.Context, req GetEscrowPayload) (*EscrowResponse, error) {
+	return s.get(ctx, req.ID, req.UserID)
+}
+
+func (s *escrowService) List(ctx context.Context, req ListEscrowPayload) ([]EscrowResponse, *response.Pagination, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	escrows, pagination, err := s.repository.List(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := make([]EscrowResponse, len(escrows))
+	for i, e := range escrows {
+		resp[i] = toResponse(e)
+	}
+	return resp, pagination, nil
+}
+
+func (s *escrowService) Confirm(ctx context.Context, req ConfirmEscrowPayload) (*EscrowResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	err = s.pins.VerifyPIN(ctx, req.UserID, req.PIN)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repository.Release(ctx, req.ID, func(e Escrow) error {
+		if e.BuyerID != req.UserID {
+			if e.SellerID == req.UserID {
+				return ErrNotBuyer
+			}
+			return ErrEscrowNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, req.ID, req.UserID)
+}
+
+func (s *escrowService) Cancel(ctx context.Context, req CancelEscrowPayload) (*EscrowResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	err = s.repository.Refund(ctx, req.ID, req.Reason, func(e Escrow) error {
+		if req.UserID != "" && e.SellerID != req.UserID {
+			if e.BuyerID == req.UserID {
+				return ErrNotSeller
+			}
+			return ErrEscrowNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.repository.Get(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := toResponse(*e)
+	return &resp, nil
+}
+
+func (s *escrowService) ReleaseDue(ctx context.Context, limit int) (int, error) {
+	ids, err := s.repository.ListDue(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	released := 0
+	var errs []error
+	for _, id := range ids {
+		err = s.repository.Release(ctx, id, func(e Escrow) error {
+			return nil
+		})
+		// confirmed or cancelled since it was listed
+		if errors.Is(err, ErrEscrowClosed) {
+			continue
+		}
+		if err != nil {
+			// one escrow that cannot be released must not hold up the others, now or in later batches
+			errs = append(errs, fmt.Errorf("escrow %s: %w", id, err))
+			deferErr := s.repository.DeferRelease(ctx, id)
+			if deferErr != nil {
+				errs = append(errs, fmt.Errorf("escrow %s: %w", id, deferErr))
+			}
+			continue
+		}
+		released++
+	}
+	return released, errors.Join(errs...)
+}
+
+// get returns the escrow only to its buyer and seller.
+func (s *escrowService) get(ctx context.Context, id, userID string) (*EscrowResponse, error) {
+	e, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.BuyerID != userID && e.SellerID != userID {
+		return nil, ErrEscrowNotFound
+	}
+	resp := toResponse(*e)
+	return &resp, nil
+}
+
+func toResponse(e Escrow) EscrowResponse {
+	resp := EscrowResponse{
+		ID:                  e.ID,
+		BuyerID:             e.BuyerID,
+		BuyerName:           e.BuyerName,
+		SellerID:            e.SellerID,
+		SellerName:          e.SellerName,
+		Amount:              e.Amount,
+		Currency:            e.Currency,
+		Description:         e.Description,
+		Status:              e.Status,
+		ReleaseAfter:        e.ReleaseAfter.UnixMilli(),
+		FundTransactionID:   e.FundTransactionID,
+		SettleTransactionID: e.SettleTransactionID,
+		CancelReason:        e.CancelReason,
+		CreatedAt:           e.CreatedAt.UnixMilli(),
+	}
+	if e.ClosedAt != nil {
+		closedAt := e.ClosedAt.UnixMilli()
+		resp.ClosedAt = &closedAt
+	}
+	return resp
+}
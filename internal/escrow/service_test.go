@@ -0,0 +1,101 @@
+package escrow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+// releaseRepository releases due escrows, failing those listed in errs.
+// Methods ReleaseDue does not call are left to the embedded nil Repository.
+type releaseRepository struct {
+	Repository
+	due      []string
+	errs     map[string]error
+	released []string
+	deferred []string
+}
+
+func (r *releaseRepository) ListDue(ctx context.Context, limit int) ([]string, error) {
+	return r.due, nil
+}
+
+func (r *releaseRepository) Release(ctx context.Context, id string, check func(Escrow) error) error {
+	if err := r.errs[id]; err != nil {
+		return err
+	}
+	r.released = append(r.released, id)
+	return nil
+}
+
+func (r *releaseRepository) DeferRelease(ctx context.Context, id string) error {
+	r.deferred = append(r.deferred, id)
+	return nil
+}
+
+func TestReleaseDue(t *testing.T) {
+	errDatabase := errors.New("database is down")
+
+	tests := []struct {
+		name         string
+		due          []string
+		errs         map[string]error
+		wantReleased []string
+		wantDeferred []string
+		wantErrs     []error
+	}{
+		{
+			name:         "all released",
+			due:          []string{"e1", "e2"},
+			wantReleased: []string{"e1", "e2"},
+		},
+		{
+			name:         "closed since listed",
+			due:          []string{"e1", "e2"},
+			errs:         map[string]error{"e1": ErrEscrowClosed},
+			wantReleased: []string{"e2"},
+		},
+		{
+			name:         "failures do not stop the others",
+			due:          []string{"e1", "e2", "e3"},
+			errs:         map[string]error{"e1": errDatabase, "e3": ErrEscrowNotFound},
+			wantReleased: []string{"e2"},
+			wantDeferred: []string{"e1", "e3"},
+			wantErrs:     []error{errDatabase, ErrEscrowNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &releaseRepository{due: tt.due, errs: tt.errs}
+			service := NewService(repository, nil, nil, userbalance.StepUpPolicy{})
+
+			released, err := service.ReleaseDue(context.Background(), 10)
+			if released != len(tt.wantReleased) {
+				t.Errorf("ReleaseDue() released %d, want %d", released, len(tt.wantReleased))
+			}
+			if len(repository.released) != len(tt.wantReleased) {
+				t.Fatalf("released %v, want %v", repository.released, tt.wantReleased)
+			}
+			for i, id := range tt.wantReleased {
+				if repository.released[i] != id {
+					t.Errorf("released %v, want %v", repository.released, tt.wantReleased)
+				}
+			}
+			if !reflect.DeepEqual(repository.deferred, tt.wantDeferred) {
+				t.Errorf("deferred %v, want %v", repository.deferred, tt.wantDeferred)
+			}
+			if (err != nil) != (len(tt.wantErrs) > 0) {
+				t.Fatalf("ReleaseDue() error = %v, want %v", err, tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("ReleaseDue() error = %v, want it to wrap %v", err, want)
+				}
+			}
+		})
+	}
+}
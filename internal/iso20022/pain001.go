@@ -0,0 +1,221 @@
+// Package iso20022 generates ISO 20022 payment messages.
+package iso20022
+
+import (
+	"encoding/xml"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+	notProvided      = "NOTPROVIDED"
+)
+
+var (
+	ErrNoTransfers = errors.New("no credit transfers to export")
+)
+
+// Debtor is the account that funds every transfer in the message.
+type Debtor struct {
+	Name    string
+	Account string
+	BIC     string
+}
+
+// CreditTransfer is a single outgoing payment.
+type CreditTransfer struct {
+	EndToEndID        string
+	Amount            int
+	Currency          string
+	CreditorAccount   string
+	CreditorAgentName string
+	RemittanceInfo    string
+}
+
+// Pain001 is a generated customer credit transfer initiation message.
+type Pain001 struct {
+	MessageID            string
+	NumberOfTransactions int
+	ControlSum           int
+	Document             []byte
+}
+
+// NewPain001 builds a pain.001.001.03 message for transfers, with one payment information
+// block per currency.
+func NewPain001(messageID string, createdAt time.Time, debtor Debtor, transfers []CreditTransfer) (*Pain001, error) {
+	if len(transfers) == 0 {
+		return nil, ErrNoTransfers
+	}
+
+	byCurrency := make(map[string][]CreditTransfer)
+	for _, t := range transfers {
+		byCurrency[t.Currency] = append(byCurrency[t.Currency], t)
+	}
+	currencies := make([]string, 0, len(byCurrency))
+	for currency := range byCurrency {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	// the schema requires non-empty identifications
+	if debtor.Name == "" {
+		debtor.Name = notProvided
+	}
+	if debtor.Account == "" {
+		debtor.Account = notProvided
+	}
+	debtorAgent := financialInstitution{Other: &genericID{ID: notProvided}}
+	if debtor.BIC != "" {
+		debtorAgent = financialInstitution{BIC: debtor.BIC}
+	}
+
+	controlSum := 0
+	paymentInfos := make([]paymentInformation, 0, len(currencies))
+	for _, currency := range currencies {
+		info := paymentInformation{
+			PaymentInformationID: messageID + "-" + currency,
+			PaymentMethod:        "TRF",
+			RequestedExecution:   createdAt.Format(time.DateOnly),
+			Debtor:               party{Name: debtor.Name},
+			DebtorAccount: account{
+				ID:       accountID{Other: genericID{ID: debtor.Account}},
+				Currency: currency,
+			},
+			DebtorAgent: agent{FinancialInstitution: debtorAgent},
+		}
+		sum := 0
+		for _, t := range byCurrency[currency] {
+			sum += t.Amount
+			tx := creditTransferTransaction{
+				PaymentID: paymentID{EndToEndID: t.EndToEndID},
+				Amount: amount{InstructedAmount: instructedAmount{
+					Currency: t.Currency,
+					Value:    strconv.Itoa(t.Amount),
+				}},
+				CreditorAgent: &agent{FinancialInstitution: financialInstitution{Name: t.CreditorAgentName}},
+				CreditorAccount: account{
+					ID: accountID{Other: genericID{ID: t.CreditorAccount}},
+				},
+			}
+			if t.RemittanceInfo != "" {
+				tx.Remittance = &remittance{Unstructured: t.RemittanceInfo}
+			}
+			info.Transactions = append(info.Transactions, tx)
+		}
+		info.NumberOfTransactions = strconv.Itoa(len(info.Transactions))
+		info.ControlSum = strconv.Itoa(sum)
+		controlSum += sum
+		paymentInfos = append(paymentInfos, info)
+	}
+
+	doc := document{
+		Namespace: pain001Namespace,
+		Initiation: customerCreditTransferInitiation{
+			GroupHeader: groupHeader{
+				MessageID:            messageID,
+				CreatedAt:            createdAt.UTC().Format("2006-01-02T15:04:05"),
+				NumberOfTransactions: strconv.Itoa(len(transfers)),
+				ControlSum:           strconv.Itoa(controlSum),
+				InitiatingParty:      party{Name: debtor.Name},
+			},
+			PaymentInformation: paymentInfos,
+		},
+	}
+	b, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pain001{
+		MessageID:            messageID,
+		NumberOfTransactions: len(transfers),
+		ControlSum:           controlSum,
+		Document:             append([]byte(xml.Header), b...),
+	}, nil
+}
+
+type document struct {
+	XMLName    xml.Name                         `xml:"Document"`
+	Namespace  string                           `xml:"xmlns,attr"`
+	Initiation customerCreditTransferInitiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type customerCreditTransferInitiation struct {
+	GroupHeader        groupHeader          `xml:"GrpHdr"`
+	PaymentInformation []paymentInformation `xml:"PmtInf"`
+}
+
+type groupHeader struct {
+	MessageID            string `xml:"MsgId"`
+	CreatedAt            string `xml:"CreDtTm"`
+	NumberOfTransactions string `xml:"NbOfTxs"`
+	ControlSum           string `xml:"CtrlSum"`
+	InitiatingParty      party  `xml:"InitgPty"`
+}
+
+type paymentInformation struct {
+	PaymentInformationID string                      `xml:"PmtInfId"`
+	PaymentMethod        string                      `xml:"PmtMtd"`
+	NumberOfTransactions string                      `xml:"NbOfTxs"`
+	ControlSum           string                      `xml:"CtrlSum"`
+	RequestedExecution   string                      `xml:"ReqdExctnDt"`
+	Debtor               party                       `xml:"Dbtr"`
+	DebtorAccount        account                     `xml:"DbtrAcct"`
+	DebtorAgent          agent                       `xml:"DbtrAgt"`
+	Transactions         []creditTransferTransaction `xml:"CdtTrfTxInf"`
+}
+
+type creditTransferTransaction struct {
+	PaymentID       paymentID   `xml:"PmtId"`
+	Amount          amount      `xml:"Amt"`
+	CreditorAgent   *agent      `xml:"CdtrAgt,omitempty"`
+	CreditorAccount account     `xml:"CdtrAcct"`
+	Remittance      *remittance `xml:"RmtInf,omitempty"`
+}
+
+type party struct {
+	Name string `xml:"Nm"`
+}
+
+type account struct {
+	ID       accountID `xml:"Id"`
+	Currency string    `xml:"Ccy,omitempty"`
+}
+
+type accountID struct {
+	Other genericID `xml:"Othr"`
+}
+
+type genericID struct {
+	ID string `xml:"Id"`
+}
+
+type agent struct {
+	FinancialInstitution financialInstitution `xml:"FinInstnId"`
+}
+
+type financialInstitution struct {
+	BIC   string     `xml:"BIC,omitempty"`
+	Name  string     `xml:"Nm,omitempty"`
+	Other *genericID `xml:"Othr,omitempty"`
+}
+
+type paymentID struct {
+	EndToEndID string `xml:"EndToEndId"`
+}
+
+type amount struct {
+	InstructedAmount instructedAmount `xml:"InstdAmt"`
+}
+
+type instructedAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type remittance struct {
+	Unstructured string `xml:"Ustrd"`
+}
@@ -0,0 +1,134 @@
+package iso20022
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNewPain001(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 17, 30, 0, 0, time.FixedZone("WIB", 7*60*60))
+	debtor := Debtor{Name: "Paimon Bank", Account: "0011223344", BIC: "PAIMIDJA"}
+
+	type wantInfo struct {
+		id           string
+		currency     string
+		transactions int
+		controlSum   string
+	}
+	tests := []struct {
+		name           string
+		debtor         Debtor
+		transfers      []CreditTransfer
+		wantErr        error
+		wantCount      int
+		wantControlSum int
+		wantInfos      []wantInfo
+		wantDebtorName string
+		wantDebtorBIC  string
+	}{
+		{
+			name:    "no transfers",
+			debtor:  debtor,
+			wantErr: ErrNoTransfers,
+		},
+		{
+			name:   "single transfer",
+			debtor: debtor,
+			transfers: []CreditTransfer{
+				{EndToEndID: "tx1", Amount: 15000, Currency: "IDR", CreditorAccount: "987654", CreditorAgentName: "BCA", RemittanceInfo: "rent"},
+			},
+			wantCount:      1,
+			wantControlSum: 15000,
+			wantInfos:      []wantInfo{{id: "MSG1-IDR", currency: "IDR", transactions: 1, controlSum: "15000"}},
+			wantDebtorName: "Paimon Bank",
+			wantDebtorBIC:  "PAIMIDJA",
+		},
+		{
+			name:   "grouped by currency in order",
+			debtor: debtor,
+			transfers: []CreditTransfer{
+				{EndToEndID: "tx1", Amount: 100, Currency: "USD", CreditorAccount: "1", CreditorAgentName: "Chase"},
+				{EndToEndID: "tx2", Amount: 15000, Currency: "IDR", CreditorAccount: "2", CreditorAgentName: "BCA"},
+				{EndToEndID: "tx3", Amount: 250, Currency: "USD", CreditorAccount: "3", CreditorAgentName: "Citi"},
+			},
+			wantCount:      3,
+			wantControlSum: 15350,
+			wantInfos: []wantInfo{
+				{id: "MSG1-IDR", currency: "IDR", transactions: 1, controlSum: "15000"},
+				{id: "MSG1-USD", currency: "USD", transactions: 2, controlSum: "350"},
+			},
+			wantDebtorName: "Paimon Bank",
+			wantDebtorBIC:  "PAIMIDJA",
+		},
+		{
+			name: "debtor not configured",
+			transfers: []CreditTransfer{
+				{EndToEndID: "tx1", Amount: 15000, Currency: "IDR", CreditorAccount: "987654", CreditorAgentName: "BCA"},
+			},
+			wantCount:      1,
+			wantControlSum: 15000,
+			wantInfos:      []wantInfo{{id: "MSG1-IDR", currency: "IDR", transactions: 1, controlSum: "15000"}},
+			wantDebtorName: notProvided,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPain001("MSG1", createdAt, tt.debtor, tt.transfers)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewPain001() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.MessageID != "MSG1" || got.NumberOfTransactions != tt.wantCount || got.ControlSum != tt.wantControlSum {
+				t.Errorf("NewPain001() = %s/%d/%d, want MSG1/%d/%d", got.MessageID, got.NumberOfTransactions, got.ControlSum, tt.wantCount, tt.wantControlSum)
+			}
+			if !bytes.HasPrefix(got.Document, []byte(xml.Header)) {
+				t.Errorf("document does not start with the XML header")
+			}
+
+			var doc document
+			if err := xml.Unmarshal(got.Document, &doc); err != nil {
+				t.Fatalf("document is not valid XML: %v", err)
+			}
+			if doc.Namespace != pain001Namespace {
+				t.Errorf("namespace = %q, want %q", doc.Namespace, pain001Namespace)
+			}
+			header := doc.Initiation.GroupHeader
+			if header.CreatedAt != "2024-03-01T10:30:00" {
+				t.Errorf("CreDtTm = %q, want the UTC creation time", header.CreatedAt)
+			}
+			if header.InitiatingParty.Name != tt.wantDebtorName {
+				t.Errorf("InitgPty = %q, want %q", header.InitiatingParty.Name, tt.wantDebtorName)
+			}
+
+			infos := doc.Initiation.PaymentInformation
+			if len(infos) != len(tt.wantInfos) {
+				t.Fatalf("got %d payment information blocks, want %d", len(infos), len(tt.wantInfos))
+			}
+			for i, want := range tt.wantInfos {
+				info := infos[i]
+				if info.PaymentInformationID != want.id || info.DebtorAccount.Currency != want.currency ||
+					len(info.Transactions) != want.transactions || info.NumberOfTransactions != strconv.Itoa(want.transactions) ||
+					info.ControlSum != want.controlSum {
+					t.Errorf("PmtInf[%d] = %s/%s/%d txs/%s, want %s/%s/%d txs/%s", i, info.PaymentInformationID, info.DebtorAccount.Currency,
+						len(info.Transactions), info.ControlSum, want.id, want.currency, want.transactions, want.controlSum)
+				}
+				if info.RequestedExecution != "2024-03-01" {
+					t.Errorf("PmtInf[%d] ReqdExctnDt = %q, want 2024-03-01", i, info.RequestedExecution)
+				}
+				if info.DebtorAgent.FinancialInstitution.BIC != tt.wantDebtorBIC {
+					t.Errorf("PmtInf[%d] debtor BIC = %q, want %q", i, info.DebtorAgent.FinancialInstitution.BIC, tt.wantDebtorBIC)
+				}
+				if tt.wantDebtorBIC == "" && info.DebtorAgent.FinancialInstitution.Other == nil {
+					t.Errorf("PmtInf[%d] debtor agent has no identification", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+)
+
+// fileMailer writes each message to dir as an .eml file, for local runs without a mail server.
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (Mailer, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+// Send implements Mailer.
+func (m *fileMailer) Send(ctx context.Context, msg Message) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), id.GenerateStringID(8))
+	return os.WriteFile(filepath.Join(m.dir, name), format(m.from, msg), 0o600)
+}
+
+// logMailer writes messages to the log. Bodies hold one-time tokens, so it is meant for development only.
+type logMailer struct{}
+
+func NewLogMailer() Mailer {
+	return logMailer{}
+}
+
+// Send implements Mailer.
+func (logMailer) Send(ctx context.Context, msg Message) error {
+	slog.Info(fmt.Sprintf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body))
+	return nil
+}
@@ -0,0 +1,36 @@
+package mailer
+
+import (
+	"context"
+	"net"
+	"net/mail"
+	"net/smtp"
+)
+
+// smtpMailer sends through an SMTP relay, authenticating with PLAIN when a username is set.
+// net/smtp upgrades to TLS whenever the server offers STARTTLS.
+type smtpMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+	// sender is the bare address of from, used as the envelope sender.
+	sender string
+}
+
+// NewSMTPMailer fails when from, which may carry a display name, is not a valid address.
+func NewSMTPMailer(host, port, username, password, from string) (Mailer, error) {
+	address, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &smtpMailer{addr: net.JoinHostPort(host, port), auth: auth, from: from, sender: address.Address}, nil
+}
+
+// Send implements Mailer.
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	return smtp.SendMail(m.addr, m.auth, m.sender, []string{msg.To}, format(m.from, msg))
+}
@@ -0,0 +1,11 @@
+package paymentrequest
+
+import "errors"
+
+var (
+	ErrValidationFailed        = errors.New("validation failed")
+	ErrPayerNotFound           = errors.New("payer not found")
+	ErrPayerIsRequester        = errors.New("cannot request money from yourself")
+	ErrPaymentRequestNotFound  = errors.New("payment request not found")
+	ErrPaymentRequestNotActive = errors.New("payment request is no longer pending")
+)
@@ -0,0 +1,201 @@
+package paymentrequest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/middleware"
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req CreatePaymentRequestPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.RequesterID = userID
+
+	paymentRequest, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, response.ResponseBody{
+		Message: "Payment request created successfully",
+		Data:    paymentRequest,
+	})
+}
+
+// List returns the requests the user has to pay (direction=incoming, the default) or has sent (direction=outgoing).
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	req := ListPaymentRequestPayload{UserID: userID}
+	var params = r.URL.Query()
+	if v, ok := request.CheckPositiveInt(params, "limit"); ok {
+		req.Limit = v
+		if v == 0 {
+			req.Limit = 5
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if v, ok := request.CheckPositiveInt(params, "offset"); ok {
+		req.Offset = v
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	req.Direction = Direction(params.Get("direction"))
+	if req.Direction == "" {
+		req.Direction = DirectionIncoming
+	}
+	req.Status = Status(params.Get("status"))
+
+	paymentRequests, pagination, err := h.service.List(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    paymentRequests,
+		Meta:    pagination,
+	})
+}
+
+func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req AcceptPaymentRequestPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.ID = mux.Vars(r)["id"]
+	req.UserID = userID
+
+	paymentRequest, err := h.service.Accept(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "Payment request paid successfully",
+		Data:    paymentRequest,
+	})
+}
+
+func (h *Handler) Decline(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, r, h.service.Decline, "Payment request declined")
+}
+
+func (h *Handler) respond(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, req RespondPaymentRequestPayload) (*PaymentRequestResponse, error), message string) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	paymentRequest, err := action(r.Context(), RespondPaymentRequestPayload{
+		ID:     mux.Vars(r)["id"],
+		UserID: userID,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: message,
+		Data:    paymentRequest,
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "Internal server error"
+	switch {
+	case errors.Is(err, ErrValidationFailed),
+		errors.Is(err, userbalance.ErrPINNotSet),
+		errors.Is(err, ErrPayerIsRequester),
+		errors.Is(err, userbalance.ErrNotEnoughBalance),
+		errors.Is(err, userbalance.ErrNoCurrencyOrUserRecorded),
+		errors.Is(err, userbalance.ErrAccountClosed):
+		status = http.StatusBadRequest
+		message = "Bad request"
+	case errors.Is(err, ErrPayerNotFound), errors.Is(err, ErrPaymentRequestNotFound):
+		status = http.StatusNotFound
+		message = "Not found"
+	case errors.Is(err, ErrPaymentRequestNotActive):
+		status = http.StatusConflict
+		message = "Conflict"
+	case errors.Is(err, userbalance.ErrWrongPIN),
+		errors.Is(err, userbalance.ErrEmailNotVerified),
+		errors.Is(err, userbalance.ErrStepUpRequired),
+		errors.Is(err, userbalance.ErrStepUpFailed):
+		status = http.StatusForbidden
+		message = "Forbidden"
+	case errors.Is(err, userbalance.ErrPINLocked),
+		errors.Is(err, userbalance.ErrStepUpLocked):
+		status = http.StatusLocked
+		message = "Locked"
+	}
+	response.JSON(w, status, response.ResponseBody{
+		Message: message,
+		Error:   err.Error(),
+	})
+}
+
+func getUserID(r *http.Request) (string, error) {
+	if authValue, ok := r.Context().Value(middleware.ContextAuthKey{}).(string); ok {
+		return authValue, nil
+	}
+
+	return "", errors.New("unauthorized")
+}
@@ -0,0 +1,30 @@
+package paymentrequest
+
+import "time"
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusAccepted Status = "accepted"
+	StatusDeclined Status = "declined"
+	StatusExpired  Status = "expired"
+)
+
+// PaymentRequest is one user asking another for money.
+// Status is reported as expired once ExpiresAt passes while the request is still pending.
+type PaymentRequest struct {
+	ID            string
+	RequesterID   string
+	RequesterName string
+	PayerID       string
+	PayerName     string
+	Amount        int
+	Currency      string
+	Memo          string
+	Status        Status
+	TransactionID *string
+	ExpiresAt     time.Time
+	CreatedAt     time.Time
+	RespondedAt   *time.Time
+}
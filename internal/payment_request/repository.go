@@ -0,0 +1,209 @@
+package paymentrequest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type Repository interface {
+	Create(ctx context.Context, pr *PaymentRequest, payerEmail string, expiresInHours int) error
+	Get(ctx context.Context, id string) (*PaymentRequest, error)
+	List(ctx context.Context, payload ListPaymentRequestPayload) ([]PaymentRequest, *response.Pagination, error)
+	Accept(ctx context.Context, id, payerID string) error
+	Decline(ctx context.Context, id, payerID string) error
+}
+
+type dbRepository struct {
+	db *db.DB
+}
+
+func NewRepository(db *db.DB) Repository {
+	return &dbRepository{db: db}
+}
+
+// statusColumn reports pending requests past their expiry as expired without a background sweep.
+const statusColumn = `CASE WHEN pr.status = 'pending' AND pr.expires_at <= current_timestamp THEN 'expired' ELSE pr.status END`
+
+var selectColumns = fmt.Sprintf(`
+	pr.id, pr.requester_id, requester.name, pr.payer_id, payer.name, pr.amount, pr.currency, pr.memo,
+	%s, pr.transaction_id, pr.expires_at, pr.created_at, pr.responded_at
+`, statusColumn)
+
+func scanPaymentRequest(scan func(dest ...any) error, pr *PaymentRequest, extra ...any) error {
+	dest := append(extra, &pr.ID, &pr.RequesterID, &pr.RequesterName, &pr.PayerID, &pr.PayerName, &pr.Amount, &pr.Currency, &pr.Memo,
+		&pr.Status, &pr.TransactionID, &pr.ExpiresAt, &pr.CreatedAt, &pr.RespondedAt)
+	return scan(dest...)
+}
+
+// Create implements Repository.
+// The payer is resolved by email in the same statement.
+func (d *dbRepository) Create(ctx context.Context, pr *PaymentRequest, payerEmail string, expiresInHours int) error {
+	createQuery := `
+		INSERT INTO payment_requests (
+			id, requester_id, payer_id, amount, currency, memo, expires_at
+		)
+		SELECT $1, $2, u.id, $3, $4, $5, current_timestamp + make_interval(hours => $6)
+		FROM users u
+		WHERE u.email = $7 AND u.closed_at IS NULL
+		RETURNING payer_id, status, expires_at, created_at
+	`
+	row := d.db.DB().QueryRowContext(ctx, createQuery, pr.ID, pr.RequesterID, pr.Amount, pr.Currency, pr.Memo, expiresInHours, payerEmail)
+	err := row.Scan(&pr.PayerID, &pr.Status, &pr.ExpiresAt, &pr.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPayerNotFound
+	}
+	if errors.As(err, &pgErr) && pgErr.Code == "23514" && pgErr.ConstraintName == "payment_requests_payer_not_requester" {
+		return ErrPayerIsRequester
+	}
+	return err
+}
+
+// Get implements Repository.
+func (d *dbRepository) Get(ctx context.Context, id string) (*PaymentRequest, error) {
+	selectQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM payment_requests pr
+		JOIN users requester ON requester.id = pr.requester_id
+		JOIN users payer ON payer.id = pr.payer_id
+		WHERE pr.id = $1
+	`, selectColumns)
+	pr := &PaymentRequest{}
+	err := scanPaymentRequest(d.db.DB().QueryRowContext(ctx, selectQuery, id).Scan, pr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPaymentRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// List implements Repository.
+func (d *dbRepository) List(ctx context.Context, payload ListPaymentRequestPayload) ([]PaymentRequest, *response.Pagination, error) {
+	resp := []PaymentRequest{}
+	pagination := &response.Pagination{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	}
+
+	userColumn := "pr.payer_id"
+	if payload.Direction == DirectionOutgoing {
+		userColumn = "pr.requester_id"
+	}
+	args := []any{payload.UserID}
+	conditions := ""
+	if payload.Status != "" {
+		args = append(args, payload.Status)
+		conditions += fmt.Sprintf(" AND %s = $%d", statusColumn, len(args))
+	}
+	args = append(args, payload.Limit, payload.Offset)
+
+	selectQuery := fmt.Sprintf(`
+		SELECT COUNT(*) OVER() AS total_count, %s
+		FROM payment_requests pr
+		JOIN users requester ON requester.id = pr.requester_id
+		JOIN users payer ON payer.id = pr.payer_id
+		WHERE %s = $1%s
+		ORDER BY pr.created_at DESC
+		LIMIT $%d
+		OFFSET $%d
+	`, selectColumns, userColumn, conditions, len(args)-1, len(args))
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pr PaymentRequest
+		err = scanPaymentRequest(rows.Scan, &pr, &pagination.Total)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp = append(resp, pr)
+	}
+
+	return resp, pagination, rows.Err()
+}
+
+// Accept implements Repository.
+// The transfer from payer to requester and the status change commit together.
+func (d *dbRepository) Accept(ctx context.Context, id, payerID string) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		pr, err := lockPending(ctx, tx, id, payerID)
+		if err != nil {
+			return err
+		}
+
+		transfer := &userbalance.InternalTransfer{
+			FromUserID: pr.PayerID,
+			ToUserID:   pr.RequesterID,
+			Amount:     pr.Amount,
+			Currency:   pr.Currency,
+		}
+		err = userbalance.RecordInternalTransfer(ctx, tx, transfer)
+		if err != nil {
+			return err
+		}
+
+		updateQuery := `
+			UPDATE payment_requests
+			SET status = $1, transaction_id = $2, responded_at = current_timestamp
+			WHERE id = $3
+		`
+		_, err = tx.ExecContext(ctx, updateQuery, StatusAccepted, transfer.DebitTransactionID, pr.ID)
+		return err
+	})
+}
+
+// Decline implements Repository.
+func (d *dbRepository) Decline(ctx context.Context, id, payerID string) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		pr, err := lockPending(ctx, tx, id, payerID)
+		if err != nil {
+			return err
+		}
+
+		updateQuery := `
+			UPDATE payment_requests
+			SET status = $1, responded_at = current_timestamp
+			WHERE id = $2
+		`
+		_, err = tx.ExecContext(ctx, updateQuery, StatusDeclined, pr.ID)
+		return err
+	})
+}
+
+// lockPending locks the payer's request and checks it can still be answered.
+func lockPending(ctx context.Context, tx *sql.Tx, id, payerID string) (*PaymentRequest, error) {
+	selectQuery := fmt.Sprintf(`
+		SELECT %s
+		FROM payment_requests pr
+		JOIN users requester ON requester.id = pr.requester_id
+		JOIN users payer ON payer.id = pr.payer_id
+		WHERE pr.id = $1 AND pr.payer_id = $2
+		FOR UPDATE OF pr
+	`, selectColumns)
+	pr := &PaymentRequest{}
+	err := scanPaymentRequest(tx.QueryRowContext(ctx, selectQuery, id, payerID).Scan, pr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPaymentRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pr.Status != StatusPending {
+		return nil, ErrPaymentRequestNotActive
+	}
+	return pr, nil
+}
@@ -0,0 +1,72 @@
+package paymentrequest
+
+import (
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type Direction string
+
+const (
+	DirectionIncoming Direction = "incoming"
+	DirectionOutgoing Direction = "outgoing"
+)
+
+type CreatePaymentRequestPayload struct {
+	PayerEmail     string `json:"payerEmail"`
+	Amount         int    `json:"amount"`
+	Currency       string `json:"currency"`
+	Memo           string `json:"memo"`
+	ExpiresInHours int    `json:"expiresInHours"`
+	RequesterID    string
+}
+
+func (p CreatePaymentRequestPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.PayerEmail, validation.Required, is.EmailFormat),
+		validation.Field(&p.Amount, validation.Required, validation.Min(1)),
+		validation.Field(&p.Currency, validation.Required, is.CurrencyCode),
+		validation.Field(&p.Memo, validation.Length(0, 140)),
+		validation.Field(&p.ExpiresInHours, validation.Min(1), validation.Max(maxExpiresInHours)),
+	)
+}
+
+type ListPaymentRequestPayload struct {
+	UserID    string
+	Direction Direction
+	Status    Status
+	Limit     int
+	Offset    int
+}
+
+func (p ListPaymentRequestPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Direction, validation.Required, validation.In(DirectionIncoming, DirectionOutgoing)),
+		validation.Field(&p.Status, validation.In(StatusPending, StatusAccepted, StatusDeclined, StatusExpired)),
+	)
+}
+
+type RespondPaymentRequestPayload struct {
+	ID     string
+	UserID string
+}
+
+// AcceptPaymentRequestPayload pays a request; the payer authorizes it with the transaction PIN.
+// Amounts at or above the step-up threshold also need a step-up factor.
+type AcceptPaymentRequestPayload struct {
+	userbalance.StepUpFactor
+	ID     string `json:"-"`
+	UserID string `json:"-"`
+	PIN    string `json:"pin"`
+}
+
+func (p AcceptPaymentRequestPayload) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.PIN, userbalance.PINRules...),
+	)
+	if err != nil {
+		return err
+	}
+	return p.StepUpFactor.Validate()
+}
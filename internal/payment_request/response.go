@@ -0,0 +1,17 @@
+package paymentrequest
+
+type PaymentRequestResponse struct {
+	ID            string  `json:"id"`
+	RequesterID   string  `json:"requesterId"`
+	RequesterName string  `json:"requesterName"`
+	PayerID       string  `json:"payerId"`
+	PayerName     string  `json:"payerName"`
+	Amount        int     `json:"amount"`
+	Currency      string  `json:"currency"`
+	Memo          string  `json:"memo"`
+	Status        Status  `json:"status"`
+	TransactionID *string `json:"transactionId"`
+	ExpiresAt     int64   `json:"expiresAt"`
+	CreatedAt     int64   `json:"createdAt"`
+	RespondedAt   *int64  `json:"respondedAt"`
+}
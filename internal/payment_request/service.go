@@ -0,0 +1,148 @@
+package paymentrequest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/citadel-corp/paimon-bank/internal/currency"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+const (
+	defaultExpiresInHours = 72
+	maxExpiresInHours     = 720
+)
+
+type Service interface {
+	Create(ctx context.Context, req CreatePaymentRequestPayload) (*PaymentRequestResponse, error)
+	List(ctx context.Context, req ListPaymentRequestPayload) ([]PaymentRequestResponse, *response.Pagination, error)
+	// Accept pays the request from the payer's balance.
+	Accept(ctx context.Context, req AcceptPaymentRequestPayload) (*PaymentRequestResponse, error)
+	Decline(ctx context.Context, req RespondPaymentRequestPayload) (*PaymentRequestResponse, error)
+}
+
+type paymentRequestService struct {
+	repository      Repository
+	currencyService currency.Service
+	pins            userbalance.PINVerifier
+	stepUp          userbalance.StepUpPolicy
+}
+
+func NewService(repository Repository, currencyService currency.Service, pins userbalance.PINVerifier, stepUp userbalance.StepUpPolicy) Service {
+	return &paymentRequestService{repository: repository, currencyService: currencyService, pins: pins, stepUp: stepUp}
+}
+
+func (s *paymentRequestService) Create(ctx context.Context, req CreatePaymentRequestPayload) (*PaymentRequestResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	err = s.currencyService.ValidateAmount(ctx, req.Currency, req.Amount)
+	if errors.Is(err, currency.ErrCurrencyNotSupported) || errors.Is(err, currency.ErrAmountBelowMinimum) || errors.Is(err, currency.ErrAmountAboveMaximum) {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpiresInHours == 0 {
+		req.ExpiresInHours = defaultExpiresInHours
+	}
+
+	pr := &PaymentRequest{
+		ID:          id.GenerateStringID(16),
+		RequesterID: req.RequesterID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Memo:        req.Memo,
+	}
+	err = s.repository.Create(ctx, pr, req.PayerEmail, req.ExpiresInHours)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, pr.ID)
+}
+
+func (s *paymentRequestService) List(ctx context.Context, req ListPaymentRequestPayload) ([]PaymentRequestResponse, *response.Pagination, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	requests, pagination, err := s.repository.List(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := make([]PaymentRequestResponse, len(requests))
+	for i, pr := range requests {
+		resp[i] = toResponse(pr)
+	}
+	return resp, pagination, nil
+}
+
+func (s *paymentRequestService) Accept(ctx context.Context, req AcceptPaymentRequestPayload) (*PaymentRequestResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	err = s.pins.VerifyPIN(ctx, req.UserID, req.PIN)
+	if err != nil {
+		return nil, err
+	}
+	// the amount never changes once a request is created, so the one read here is the one Accept pays
+	pr, err := s.repository.Get(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if pr.PayerID == req.UserID {
+		err = s.stepUp.Authorize(ctx, req.UserID, pr.Currency, pr.Amount, req.StepUpFactor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.repository.Accept(ctx, req.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, req.ID)
+}
+
+func (s *paymentRequestService) Decline(ctx context.Context, req RespondPaymentRequestPayload) (*PaymentRequestResponse, error) {
+	err := s.repository.Decline(ctx, req.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, req.ID)
+}
+
+func (s *paymentRequestService) get(ctx context.Context, id string) (*PaymentRequestResponse, error) {
+	pr, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp := toResponse(*pr)
+	return &resp, nil
+}
+
+func toResponse(pr PaymentRequest) PaymentRequestResponse {
+	resp := PaymentRequestResponse{
+		ID:            pr.ID,
+		RequesterID:   pr.RequesterID,
+		RequesterName: pr.RequesterName,
+		PayerID:       pr.PayerID,
+		PayerName:     pr.PayerName,
+		Amount:        pr.Amount,
+		Currency:      pr.Currency,
+		Memo:          pr.Memo,
+		Status:        pr.Status,
+		TransactionID: pr.TransactionID,
+		ExpiresAt:     pr.ExpiresAt.UnixMilli(),
+		CreatedAt:     pr.CreatedAt.UnixMilli(),
+	}
+	if pr.RespondedAt != nil {
+		respondedAt := pr.RespondedAt.UnixMilli()
+		resp.RespondedAt = &respondedAt
+	}
+	return resp
+}
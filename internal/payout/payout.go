@@ -0,0 +1,45 @@
+package payout
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrRejected is wrapped by Submit errors for instructions the gateway will never accept.
+	// Any other error is transient and the instruction can be submitted again.
+	ErrRejected = errors.New("payout rejected")
+)
+
+type Status string
+
+const (
+	StatusSubmitted Status = "submitted"
+	StatusSettled   Status = "settled"
+	StatusFailed    Status = "failed"
+)
+
+// Instruction is a single payout to an external bank account.
+type Instruction struct {
+	TransactionID     string
+	Amount            int
+	Currency          string
+	BankAccountNumber string
+	BankName          string
+}
+
+// Result is the gateway's view of a submitted payout. Reason is set when Status is StatusFailed.
+type Result struct {
+	Status Status
+	Reason string
+}
+
+// PayoutGateway sends payouts to recipient banks.
+type PayoutGateway interface {
+	// Submit hands the instruction over to the gateway and returns its reference for later polling.
+	// It is idempotent on the instruction's TransactionID: submitting it again returns the same
+	// reference without paying out twice.
+	Submit(ctx context.Context, instruction Instruction) (reference string, err error)
+	// Status reports the current state of a previously submitted payout.
+	Status(ctx context.Context, reference string) (*Result, error)
+}
@@ -0,0 +1,76 @@
+package payout
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+const simulatorReferencePrefix = "SIM-"
+
+type simulatedPayout struct {
+	submittedAt time.Time
+	fails       bool
+}
+
+// simulatorGateway settles payouts locally after a delay, failing a fraction of them.
+// State is kept in memory, so payouts submitted before a restart are taken up again,
+// as if they were submitted when first polled.
+type simulatorGateway struct {
+	settleAfter time.Duration
+	failureRate float64
+
+	mu      sync.Mutex
+	payouts map[string]simulatedPayout
+}
+
+func NewSimulatorGateway(settleAfter time.Duration, failureRate float64) PayoutGateway {
+	return &simulatorGateway{
+		settleAfter: settleAfter,
+		failureRate: failureRate,
+		payouts:     make(map[string]simulatedPayout),
+	}
+}
+
+// Submit implements PayoutGateway.
+// The reference is derived from the transaction ID, so submitting it again returns the same one.
+func (g *simulatorGateway) Submit(ctx context.Context, instruction Instruction) (string, error) {
+	reference := simulatorReferencePrefix + strings.TrimSpace(instruction.TransactionID)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.track(reference)
+	return reference, nil
+}
+
+// Status implements PayoutGateway.
+func (g *simulatorGateway) Status(ctx context.Context, reference string) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	p := g.track(reference)
+	if time.Since(p.submittedAt) < g.settleAfter {
+		return &Result{Status: StatusSubmitted}, nil
+	}
+	// final states are only polled once
+	delete(g.payouts, reference)
+	if p.fails {
+		return &Result{Status: StatusFailed, Reason: "rejected by recipient bank"}, nil
+	}
+	return &Result{Status: StatusSettled}, nil
+}
+
+// track returns the payout with reference, starting it if the simulator does not know it yet.
+// g.mu must be held.
+func (g *simulatorGateway) track(reference string) simulatedPayout {
+	p, ok := g.payouts[reference]
+	if !ok {
+		p = simulatedPayout{
+			submittedAt: time.Now(),
+			fails:       rand.Float64() < g.failureRate,
+		}
+		g.payouts[reference] = p
+	}
+	return p
+}
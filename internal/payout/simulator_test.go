@@ -0,0 +1,53 @@
+package payout
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSimulatorGateway(t *testing.T) {
+	tests := []struct {
+		name        string
+		settleAfter time.Duration
+		failureRate float64
+		restart     bool
+		want        Status
+	}{
+		{name: "not settled yet", settleAfter: time.Hour, want: StatusSubmitted},
+		{name: "settled", want: StatusSettled},
+		{name: "failed", failureRate: 1, want: StatusFailed},
+		{name: "unknown after a restart", settleAfter: time.Hour, restart: true, want: StatusSubmitted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			gateway := NewSimulatorGateway(tt.settleAfter, tt.failureRate)
+			instruction := Instruction{TransactionID: "tx1", Amount: 1000, Currency: "IDR"}
+
+			reference, err := gateway.Submit(ctx, instruction)
+			if err != nil {
+				t.Fatal(err)
+			}
+			again, err := gateway.Submit(ctx, instruction)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != reference {
+				t.Fatalf("resubmitting returned reference %q, want %q", again, reference)
+			}
+
+			if tt.restart {
+				gateway = NewSimulatorGateway(tt.settleAfter, tt.failureRate)
+			}
+			result, err := gateway.Status(ctx, reference)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Status != tt.want {
+				t.Errorf("Status = %q, want %q", result.Status, tt.want)
+			}
+		})
+	}
+}
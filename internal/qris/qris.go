@@ -0,0 +1,236 @@
+// Package qris encodes and decodes EMVCo merchant-presented QR payloads in the QRIS profile.
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// GlobalUniqueIdentifier marks the merchant account template that holds a Paimon account.
+const GlobalUniqueIdentifier = "ID.CO.PAIMONBANK.WWW"
+
+const (
+	idPayloadFormat     = "00"
+	idPointOfInitiation = "01"
+	idMerchantAccount   = "26"
+	idMerchantCategory  = "52"
+	idCurrency          = "53"
+	idAmount            = "54"
+	idCountryCode       = "58"
+	idMerchantName      = "59"
+	idMerchantCity      = "60"
+	idAdditionalData    = "62"
+	idCRC               = "63"
+
+	// sub-fields of the merchant account template and additional data
+	idGUI            = "00"
+	idAccount        = "01"
+	idReferenceLabel = "05"
+
+	pointOfInitiationStatic  = "11"
+	pointOfInitiationDynamic = "12"
+
+	// merchantCategoryPersonal is used for person-to-person payments.
+	merchantCategoryPersonal = "0000"
+
+	maxMerchantName = 25
+	maxMerchantCity = 15
+)
+
+var (
+	ErrMalformed           = errors.New("malformed QR payload")
+	ErrChecksumMismatch    = errors.New("QR payload checksum does not match")
+	ErrUnsupportedAccount  = errors.New("QR payload does not contain a Paimon account")
+	ErrUnsupportedCurrency = errors.New("currency has no ISO 4217 numeric code")
+)
+
+// numericCurrencies maps ISO 4217 alphabetic codes to the numeric codes used in tag 53.
+var numericCurrencies = map[string]string{
+	"AUD": "036",
+	"CNY": "156",
+	"EUR": "978",
+	"GBP": "826",
+	"IDR": "360",
+	"JPY": "392",
+	"MYR": "458",
+	"SGD": "702",
+	"THB": "764",
+	"USD": "840",
+}
+
+// Payload is a merchant-presented QR payment. A static payload has no amount and the payer
+// enters one; a dynamic payload carries a fixed Amount.
+type Payload struct {
+	Dynamic      bool
+	AccountID    string
+	MerchantName string
+	MerchantCity string
+	Currency     string
+	Amount       int
+	Reference    string
+}
+
+// Encode renders p as an EMVCo TLV string terminated by its CRC.
+func Encode(p Payload) (string, error) {
+	currency, ok := numericCurrencies[p.Currency]
+	if !ok {
+		return "", ErrUnsupportedCurrency
+	}
+	if p.AccountID == "" {
+		return "", fmt.Errorf("%w: account is required", ErrMalformed)
+	}
+	if p.Dynamic && p.Amount <= 0 {
+		return "", fmt.Errorf("%w: dynamic payload needs a positive amount", ErrMalformed)
+	}
+
+	pointOfInitiation := pointOfInitiationStatic
+	if p.Dynamic {
+		pointOfInitiation = pointOfInitiationDynamic
+	}
+	fields := []field{
+		{idPayloadFormat, "01"},
+		{idPointOfInitiation, pointOfInitiation},
+		{idMerchantAccount, encodeFields([]field{
+			{idGUI, GlobalUniqueIdentifier},
+			{idAccount, p.AccountID},
+		})},
+		{idMerchantCategory, merchantCategoryPersonal},
+		{idCurrency, currency},
+	}
+	if p.Dynamic {
+		fields = append(fields, field{idAmount, strconv.Itoa(p.Amount)})
+	}
+	fields = append(fields,
+		field{idCountryCode, "ID"},
+		field{idMerchantName, truncate(p.MerchantName, maxMerchantName)},
+		field{idMerchantCity, truncate(p.MerchantCity, maxMerchantCity)},
+	)
+	if p.Reference != "" {
+		fields = append(fields, field{idAdditionalData, encodeFields([]field{
+			{idReferenceLabel, p.Reference},
+		})})
+	}
+
+	s := encodeFields(fields) + idCRC + "04"
+	return s + fmt.Sprintf("%04X", crc16(s)), nil
+}
+
+// validAccountID reports whether id can be a Paimon user ID: a positive 64-bit integer without sign or spaces.
+func validAccountID(id string) bool {
+	for i := 0; i < len(id); i++ {
+		if !isDigit(id[i]) {
+			return false
+		}
+	}
+	n, err := strconv.ParseInt(id, 10, 64)
+	return err == nil && n > 0
+}
+
+// Decode verifies the CRC of s and extracts the Paimon account, currency and amount.
+// Payloads for other institutions are rejected with ErrUnsupportedAccount.
+func Decode(s string) (*Payload, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 8 || s[len(s)-8:len(s)-4] != idCRC+"04" {
+		return nil, fmt.Errorf("%w: missing CRC", ErrMalformed)
+	}
+	crc, err := strconv.ParseUint(s[len(s)-4:], 16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid CRC", ErrMalformed)
+	}
+	if uint16(crc) != crc16(s[:len(s)-4]) {
+		return nil, ErrChecksumMismatch
+	}
+
+	fields, err := decodeFields(s[:len(s)-8])
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 || fields[0].id != idPayloadFormat || fields[0].value != "01" {
+		return nil, fmt.Errorf("%w: unsupported payload format", ErrMalformed)
+	}
+
+	p := &Payload{}
+	var currency string
+	for _, f := range fields {
+		switch {
+		case f.id == idPointOfInitiation:
+			p.Dynamic = f.value == pointOfInitiationDynamic
+		case f.id >= idMerchantAccount && f.id <= "51":
+			sub, err := decodeFields(f.value)
+			if err != nil {
+				return nil, err
+			}
+			if lookup(sub, idGUI) == GlobalUniqueIdentifier {
+				p.AccountID = lookup(sub, idAccount)
+			}
+		case f.id == idCurrency:
+			currency = f.value
+		case f.id == idAmount:
+			p.Amount, err = parseAmount(f.value)
+			if err != nil {
+				return nil, err
+			}
+		case f.id == idMerchantName:
+			p.MerchantName = f.value
+		case f.id == idMerchantCity:
+			p.MerchantCity = f.value
+		case f.id == idAdditionalData:
+			sub, err := decodeFields(f.value)
+			if err != nil {
+				return nil, err
+			}
+			p.Reference = lookup(sub, idReferenceLabel)
+		}
+	}
+	if !validAccountID(p.AccountID) {
+		return nil, ErrUnsupportedAccount
+	}
+	for code, numeric := range numericCurrencies {
+		if numeric == currency {
+			p.Currency = code
+		}
+	}
+	if p.Currency == "" {
+		return nil, ErrUnsupportedCurrency
+	}
+	if p.Dynamic && p.Amount <= 0 {
+		return nil, fmt.Errorf("%w: dynamic payload without amount", ErrMalformed)
+	}
+	return p, nil
+}
+
+// parseAmount accepts whole amounts, optionally with a zero fractional part such as "15000.00".
+func parseAmount(s string) (int, error) {
+	whole, fraction, _ := strings.Cut(s, ".")
+	if strings.Trim(fraction, "0") != "" {
+		return 0, fmt.Errorf("%w: fractional amounts are not supported", ErrMalformed)
+	}
+	amount, err := strconv.Atoi(whole)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("%w: invalid amount", ErrMalformed)
+	}
+	return amount, nil
+}
+
+func lookup(fields []field, id string) string {
+	for _, f := range fields {
+		if f.id == id {
+			return f.value
+		}
+	}
+	return ""
+}
+
+// truncate shortens s to at most n bytes without cutting a UTF-8 character in half.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
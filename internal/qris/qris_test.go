@@ -0,0 +1,248 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCRC16(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint16
+	}{
+		{data: "", want: 0xFFFF},
+		{data: "123456789", want: 0x29B1},
+		{data: "A", want: 0xB915},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			if got := crc16(tt.data); got != tt.want {
+				t.Errorf("crc16(%q) = %04X, want %04X", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "JAKARTA", n: 15, want: "JAKARTA"},
+		{s: "JAKARTA SELATAN RAYA", n: 15, want: "JAKARTA SELATAN"},
+		{s: "Café Paimon", n: 4, want: "Caf"},
+		{s: "Café Paimon", n: 5, want: "Café"},
+		{s: "日本語", n: 4, want: "日"},
+		{s: "日本語", n: 2, want: ""},
+		{s: "", n: 3, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.s, tt.n), func(t *testing.T) {
+			got := truncate(tt.s, tt.n)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncate(%q, %d) = %q is not valid UTF-8", tt.s, tt.n, got)
+			}
+		})
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload Payload
+		want    Payload
+		wantErr error
+	}{
+		{
+			name:    "static",
+			payload: Payload{AccountID: "42", MerchantName: "Budi Santoso", MerchantCity: "JAKARTA", Currency: "IDR"},
+			want:    Payload{AccountID: "42", MerchantName: "Budi Santoso", MerchantCity: "JAKARTA", Currency: "IDR"},
+		},
+		{
+			name:    "dynamic with reference",
+			payload: Payload{Dynamic: true, AccountID: "42", MerchantName: "Budi", MerchantCity: "JAKARTA", Currency: "USD", Amount: 1500, Reference: "INV-7"},
+			want:    Payload{Dynamic: true, AccountID: "42", MerchantName: "Budi", MerchantCity: "JAKARTA", Currency: "USD", Amount: 1500, Reference: "INV-7"},
+		},
+		{
+			name:    "long names are truncated",
+			payload: Payload{AccountID: "42", MerchantName: "Rumah Makan Sederhana Saé", MerchantCity: "KOTA JAKARTA SELATAN", Currency: "IDR"},
+			want:    Payload{AccountID: "42", MerchantName: "Rumah Makan Sederhana Sa", MerchantCity: "KOTA JAKARTA SE", Currency: "IDR"},
+		},
+		{
+			name:    "unsupported currency",
+			payload: Payload{AccountID: "42", Currency: "XXX"},
+			wantErr: ErrUnsupportedCurrency,
+		},
+		{
+			name:    "missing account",
+			payload: Payload{Currency: "IDR"},
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "dynamic without amount",
+			payload: Payload{Dynamic: true, AccountID: "42", Currency: "IDR"},
+			wantErr: ErrMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := Encode(tt.payload)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Encode() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !strings.HasPrefix(encoded, "000201") {
+				t.Errorf("Encode() = %q does not start with the payload format indicator", encoded)
+			}
+
+			got, err := Decode(encoded)
+			if err != nil {
+				t.Fatalf("Decode(%q) error = %v", encoded, err)
+			}
+			if *got != tt.want {
+				t.Errorf("Decode(Encode()) = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// withCRC appends the CRC field to body.
+func withCRC(body string) string {
+	s := body + idCRC + "04"
+	return s + fmt.Sprintf("%04X", crc16(s))
+}
+
+func TestDecode(t *testing.T) {
+	paimonAccount := "26" + fmt.Sprintf("%02d", len("0020"+GlobalUniqueIdentifier+"010242")) + "0020" + GlobalUniqueIdentifier + "010242"
+	valid := withCRC("000201" + "010211" + paimonAccount + "5303360" + "5802ID")
+
+	tests := []struct {
+		name    string
+		s       string
+		want    *Payload
+		wantErr error
+	}{
+		{
+			name: "valid",
+			s:    valid,
+			want: &Payload{AccountID: "42", Currency: "IDR"},
+		},
+		{
+			name: "surrounding whitespace",
+			s:    "  " + valid + "\n",
+			want: &Payload{AccountID: "42", Currency: "IDR"},
+		},
+		{
+			name: "lowercase CRC",
+			s:    valid[:len(valid)-4] + strings.ToLower(valid[len(valid)-4:]),
+			want: &Payload{AccountID: "42", Currency: "IDR"},
+		},
+		{
+			name:    "too short",
+			s:       "6304",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "missing CRC",
+			s:       "000201010211",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "invalid CRC",
+			s:       valid[:len(valid)-4] + "ZZZZ",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "checksum mismatch",
+			s:       strings.Replace(valid, "5303360", "5303840", 1),
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name:    "wrong payload format",
+			s:       withCRC("000202" + paimonAccount + "5303360"),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "truncated data object",
+			s:       withCRC("000201" + "5303"),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "length beyond the payload",
+			s:       withCRC("000201" + "599912"),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "negative length",
+			s:       withCRC("000201" + "59-1"),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "signed length",
+			s:       withCRC("000201" + "59+1A"),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "non-numeric account",
+			s:       withCRC("000201" + "26" + fmt.Sprintf("%02d", len("0020"+GlobalUniqueIdentifier+"0103abc")) + "0020" + GlobalUniqueIdentifier + "0103abc" + "5303360"),
+			wantErr: ErrUnsupportedAccount,
+		},
+		{
+			name:    "account beyond 64 bits",
+			s:       withCRC("000201" + "26" + fmt.Sprintf("%02d", len("0020"+GlobalUniqueIdentifier+"012099999999999999999999")) + "0020" + GlobalUniqueIdentifier + "012099999999999999999999" + "5303360"),
+			wantErr: ErrUnsupportedAccount,
+		},
+		{
+			name:    "other institution",
+			s:       withCRC("000201" + "2625" + "0015ID.CO.OTHERBANK" + "010242" + "5303360"),
+			wantErr: ErrUnsupportedAccount,
+		},
+		{
+			name:    "unknown currency",
+			s:       withCRC("000201" + paimonAccount + "5303999"),
+			wantErr: ErrUnsupportedCurrency,
+		},
+		{
+			name: "zero fractional amount",
+			s:    withCRC("000201" + "010212" + paimonAccount + "5303360" + "540815000.00"),
+			want: &Payload{Dynamic: true, AccountID: "42", Currency: "IDR", Amount: 15000},
+		},
+		{
+			name:    "fractional amount",
+			s:       withCRC("000201" + "010212" + paimonAccount + "5303360" + "540815000.50"),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "dynamic without amount",
+			s:       withCRC("000201" + "010212" + paimonAccount + "5303360"),
+			wantErr: ErrMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.s)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode(%q) error = %v, want %v", tt.s, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if *got != *tt.want {
+				t.Errorf("Decode(%q) = %+v, want %+v", tt.s, *got, *tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package qris
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// field is one EMVCo data object: a two-digit ID, a two-digit length and the value.
+type field struct {
+	id    string
+	value string
+}
+
+func encodeFields(fields []field) string {
+	var s string
+	for _, f := range fields {
+		s += fmt.Sprintf("%s%02d%s", f.id, len(f.value), f.value)
+	}
+	return s
+}
+
+func decodeFields(s string) ([]field, error) {
+	var fields []field
+	for len(s) > 0 {
+		if len(s) < 4 {
+			return nil, fmt.Errorf("%w: truncated data object", ErrMalformed)
+		}
+		// the length is exactly two digits; Atoi alone would also take "-1" or "+5"
+		length, err := strconv.Atoi(s[2:4])
+		if err != nil || !isDigit(s[2]) || !isDigit(s[3]) || length > len(s)-4 {
+			return nil, fmt.Errorf("%w: invalid length for data object %s", ErrMalformed, s[:2])
+		}
+		fields = append(fields, field{id: s[:2], value: s[4 : 4+length]})
+		s = s[4+length:]
+	}
+	return fields, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// crc16 is CRC-16/CCITT-FALSE (polynomial 0x1021, initial value 0xFFFF) as required by EMVCo.
+func crc16(data string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,12 @@
+package splitbill
+
+import "errors"
+
+var (
+	ErrValidationFailed      = errors.New("validation failed")
+	ErrParticipantNotFound   = errors.New("participant not found")
+	ErrSharesDoNotAddUp      = errors.New("shares do not add up to the total")
+	ErrSplitNotFound         = errors.New("split not found")
+	ErrObligationNotFound    = errors.New("you are not a participant of this split")
+	ErrObligationAlreadyPaid = errors.New("your share is already paid")
+)
@@ -0,0 +1,190 @@
+package splitbill
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/middleware"
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	"github.com/gorilla/mux"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateSplitPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.CreatorID = userID
+
+	split, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusCreated, response.ResponseBody{
+		Message: "Split created successfully",
+		Data:    split,
+	})
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	req := ListSplitPayload{UserID: userID}
+	var params = r.URL.Query()
+	if v, ok := request.CheckPositiveInt(params, "limit"); ok {
+		req.Limit = v
+		if v == 0 {
+			req.Limit = 5
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if v, ok := request.CheckPositiveInt(params, "offset"); ok {
+		req.Offset = v
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	splits, pagination, err := h.service.List(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    splits,
+		Meta:    pagination,
+	})
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	split, err := h.service.Get(r.Context(), GetSplitPayload{
+		ID:     mux.Vars(r)["id"],
+		UserID: userID,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    split,
+	})
+}
+
+func (h *Handler) Settle(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req SettleSplitPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.ID = mux.Vars(r)["id"]
+	req.UserID = userID
+
+	split, err := h.service.Settle(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "Share paid successfully",
+		Data:    split,
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	message := "Internal server error"
+	switch {
+	case errors.Is(err, ErrValidationFailed),
+		errors.Is(err, userbalance.ErrPINNotSet),
+		errors.Is(err, userbalance.ErrNotEnoughBalance),
+		errors.Is(err, userbalance.ErrNoCurrencyOrUserRecorded),
+		errors.Is(err, userbalance.ErrAccountClosed):
+		status = http.StatusBadRequest
+		message = "Bad request"
+	case errors.Is(err, ErrParticipantNotFound),
+		errors.Is(err, ErrSplitNotFound),
+		errors.Is(err, ErrObligationNotFound):
+		status = http.StatusNotFound
+		message = "Not found"
+	case errors.Is(err, ErrObligationAlreadyPaid):
+		status = http.StatusConflict
+		message = "Conflict"
+	case errors.Is(err, userbalance.ErrWrongPIN),
+		errors.Is(err, userbalance.ErrEmailNotVerified),
+		errors.Is(err, userbalance.ErrStepUpRequired),
+		errors.Is(err, userbalance.ErrStepUpFailed):
+		status = http.StatusForbidden
+		message = "Forbidden"
+	case errors.Is(err, userbalance.ErrPINLocked),
+		errors.Is(err, userbalance.ErrStepUpLocked):
+		status = http.StatusLocked
+		message = "Locked"
+	}
+	response.JSON(w, status, response.ResponseBody{
+		Message: message,
+		Error:   err.Error(),
+	})
+}
+
+func getUserID(r *http.Request) (string, error) {
+	if authValue, ok := r.Context().Value(middleware.ContextAuthKey{}).(string); ok {
+		return authValue, nil
+	}
+
+	return "", errors.New("unauthorized")
+}
@@ -0,0 +1,261 @@
+package splitbill
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+type Repository interface {
+	// Create stores split with its participants, which only need Email and Amount set.
+	Create(ctx context.Context, split *Split) error
+	Get(ctx context.Context, id string) (*Split, error)
+	List(ctx context.Context, payload ListSplitPayload) ([]Split, *response.Pagination, error)
+	Settle(ctx context.Context, id, userID string) error
+}
+
+type dbRepository struct {
+	db *db.DB
+}
+
+func NewRepository(db *db.DB) Repository {
+	return &dbRepository{db: db}
+}
+
+// Create implements Repository.
+func (d *dbRepository) Create(ctx context.Context, split *Split) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		emails := make([]string, len(split.Participants))
+		for i, p := range split.Participants {
+			emails[i] = p.Email
+		}
+		selectUsersQuery := `
+			SELECT id, email, name
+			FROM users
+			WHERE email = ANY($1) AND closed_at IS NULL
+		`
+		rows, err := tx.QueryContext(ctx, selectUsersQuery, emails)
+		if err != nil {
+			return err
+		}
+		type account struct{ id, name string }
+		accounts := map[string]account{}
+		for rows.Next() {
+			var email string
+			var a account
+			err = rows.Scan(&a.id, &email, &a.name)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			accounts[email] = a
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		for i, p := range split.Participants {
+			a, ok := accounts[p.Email]
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrParticipantNotFound, p.Email)
+			}
+			split.Participants[i].UserID = a.id
+			split.Participants[i].Name = a.name
+		}
+
+		createSplitQuery := `
+			INSERT INTO splits (
+				id, creator_id, title, total, currency, share_type
+			) VALUES (
+				$1, $2, $3, $4, $5, $6
+			)
+			RETURNING created_at
+		`
+		row := tx.QueryRowContext(ctx, createSplitQuery, split.ID, split.CreatorID, split.Title, split.Total, split.Currency, split.ShareType)
+		err = row.Scan(&split.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		// the creator already paid the bill, so their own share starts out paid
+		createParticipantQuery := `
+			INSERT INTO split_participants (
+				split_id, user_id, position, amount, status, paid_at
+			) VALUES (
+				$1, $2, $3, $4, $5, CASE WHEN $5 = 'paid' THEN current_timestamp END
+			)
+			RETURNING paid_at
+		`
+		for i, p := range split.Participants {
+			status := ObligationStatusPending
+			if p.UserID == split.CreatorID {
+				status = ObligationStatusPaid
+			}
+			split.Participants[i].Status = status
+			err = tx.QueryRowContext(ctx, createParticipantQuery, split.ID, p.UserID, i, p.Amount, status).Scan(&split.Participants[i].PaidAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get implements Repository.
+func (d *dbRepository) Get(ctx context.Context, id string) (*Split, error) {
+	selectQuery := `
+		SELECT s.id, s.creator_id, u.name, s.title, s.total, s.currency, s.share_type, s.created_at
+		FROM splits s
+		JOIN users u ON u.id = s.creator_id
+		WHERE s.id = $1
+	`
+	s := &Split{}
+	err := d.db.DB().QueryRowContext(ctx, selectQuery, id).Scan(&s.ID, &s.CreatorID, &s.CreatorName, &s.Title, &s.Total, &s.Currency, &s.ShareType, &s.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSplitNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	splits := []Split{*s}
+	err = d.loadParticipants(ctx, splits)
+	if err != nil {
+		return nil, err
+	}
+	return &splits[0], nil
+}
+
+// List implements Repository.
+// It returns splits the user created or takes part in, newest first.
+func (d *dbRepository) List(ctx context.Context, payload ListSplitPayload) ([]Split, *response.Pagination, error) {
+	resp := []Split{}
+	pagination := &response.Pagination{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	}
+
+	selectQuery := `
+		SELECT COUNT(*) OVER() AS total_count, s.id, s.creator_id, u.name, s.title, s.total, s.currency, s.share_type, s.created_at
+		FROM splits s
+		JOIN users u ON u.id = s.creator_id
+		WHERE s.creator_id = $1
+			OR EXISTS (SELECT 1 FROM split_participants sp WHERE sp.split_id = s.id AND sp.user_id = $1)
+		ORDER BY s.created_at DESC
+		LIMIT $2
+		OFFSET $3
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, payload.UserID, payload.Limit, payload.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s Split
+		err = rows.Scan(&pagination.Total, &s.ID, &s.CreatorID, &s.CreatorName, &s.Title, &s.Total, &s.Currency, &s.ShareType, &s.CreatedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp = append(resp, s)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	err = d.loadParticipants(ctx, resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, pagination, nil
+}
+
+func (d *dbRepository) loadParticipants(ctx context.Context, splits []Split) error {
+	if len(splits) == 0 {
+		return nil
+	}
+	ids := make([]string, len(splits))
+	index := map[string]int{}
+	for i, s := range splits {
+		ids[i] = s.ID
+		index[s.ID] = i
+	}
+
+	selectQuery := `
+		SELECT sp.split_id, sp.user_id, u.email, u.name, sp.amount, sp.status, sp.transaction_id, sp.paid_at
+		FROM split_participants sp
+		JOIN users u ON u.id = sp.user_id
+		WHERE sp.split_id = ANY($1)
+		ORDER BY sp.split_id, sp.position
+	`
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var splitID string
+		var p Participant
+		err = rows.Scan(&splitID, &p.UserID, &p.Email, &p.Name, &p.Amount, &p.Status, &p.TransactionID, &p.PaidAt)
+		if err != nil {
+			return err
+		}
+		i := index[splitID]
+		splits[i].Participants = append(splits[i].Participants, p)
+	}
+	return rows.Err()
+}
+
+// Settle implements Repository.
+// The participant's share is transferred to the split creator and marked paid in one transaction.
+func (d *dbRepository) Settle(ctx context.Context, id, userID string) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		selectQuery := `
+			SELECT s.creator_id, s.currency, sp.amount, sp.status
+			FROM split_participants sp
+			JOIN splits s ON s.id = sp.split_id
+			WHERE sp.split_id = $1 AND sp.user_id = $2
+			FOR UPDATE OF sp
+		`
+		var creatorID, currency string
+		var amount int
+		var status ObligationStatus
+		err := tx.QueryRowContext(ctx, selectQuery, id, userID).Scan(&creatorID, &currency, &amount, &status)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrObligationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if status == ObligationStatusPaid {
+			return ErrObligationAlreadyPaid
+		}
+
+		transfer := &userbalance.InternalTransfer{
+			FromUserID: userID,
+			ToUserID:   creatorID,
+			Amount:     amount,
+			Currency:   currency,
+		}
+		err = userbalance.RecordInternalTransfer(ctx, tx, transfer)
+		if err != nil {
+			return err
+		}
+
+		updateQuery := `
+			UPDATE split_participants
+			SET status = $1, transaction_id = $2, paid_at = current_timestamp
+			WHERE split_id = $3 AND user_id = $4
+		`
+		_, err = tx.ExecContext(ctx, updateQuery, ObligationStatusPaid, transfer.DebitTransactionID, id, userID)
+		return err
+	})
+}
@@ -0,0 +1,85 @@
+package splitbill
+
+import (
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+const maxParticipants = 50
+
+type CreateSplitPayload struct {
+	Title        string               `json:"title"`
+	Total        int                  `json:"total"`
+	Currency     string               `json:"currency"`
+	ShareType    ShareType            `json:"shareType"`
+	Participants []ParticipantPayload `json:"participants"`
+	CreatorID    string
+}
+
+// ParticipantPayload names a participant by email. Amount is used with fixed shares
+// and Percentage, with up to two decimals, with percentage shares.
+type ParticipantPayload struct {
+	Email      string  `json:"email"`
+	Amount     int     `json:"amount"`
+	Percentage float64 `json:"percentage"`
+}
+
+func (p CreateSplitPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Title, validation.Required, validation.Length(1, 100)),
+		validation.Field(&p.Total, validation.Required, validation.Min(1)),
+		validation.Field(&p.Currency, validation.Required, is.CurrencyCode),
+		validation.Field(&p.ShareType, validation.Required, validation.In(ShareTypeEqual, ShareTypeFixed, ShareTypePercentage)),
+		validation.Field(&p.Participants, validation.Required, validation.Length(1, maxParticipants), validation.By(uniqueEmails)),
+	)
+}
+
+func (p ParticipantPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Email, validation.Required, is.EmailFormat),
+		validation.Field(&p.Amount, validation.Min(0)),
+		validation.Field(&p.Percentage, validation.Min(0.0), validation.Max(100.0)),
+	)
+}
+
+func uniqueEmails(value interface{}) error {
+	seen := map[string]bool{}
+	for _, p := range value.([]ParticipantPayload) {
+		if seen[p.Email] {
+			return validation.NewError("validation_duplicate_participant", "must not list the same email twice")
+		}
+		seen[p.Email] = true
+	}
+	return nil
+}
+
+type ListSplitPayload struct {
+	UserID string
+	Limit  int
+	Offset int
+}
+
+type GetSplitPayload struct {
+	ID     string
+	UserID string
+}
+
+// SettleSplitPayload pays the user's share; they authorize it with the transaction PIN.
+// Amounts at or above the step-up threshold also need a step-up factor.
+type SettleSplitPayload struct {
+	userbalance.StepUpFactor
+	ID     string `json:"-"`
+	UserID string `json:"-"`
+	PIN    string `json:"pin"`
+}
+
+func (p SettleSplitPayload) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.PIN, userbalance.PINRules...),
+	)
+	if err != nil {
+		return err
+	}
+	return p.StepUpFactor.Validate()
+}
@@ -0,0 +1,25 @@
+package splitbill
+
+type SplitResponse struct {
+	ID           string                `json:"id"`
+	CreatorID    string                `json:"creatorId"`
+	CreatorName  string                `json:"creatorName"`
+	Title        string                `json:"title"`
+	Total        int                   `json:"total"`
+	PaidTotal    int                   `json:"paidTotal"`
+	Currency     string                `json:"currency"`
+	ShareType    ShareType             `json:"shareType"`
+	Status       Status                `json:"status"`
+	Participants []ParticipantResponse `json:"participants"`
+	CreatedAt    int64                 `json:"createdAt"`
+}
+
+type ParticipantResponse struct {
+	UserID        string           `json:"userId"`
+	Email         string           `json:"email"`
+	Name          string           `json:"name"`
+	Amount        int              `json:"amount"`
+	Status        ObligationStatus `json:"status"`
+	TransactionID *string          `json:"transactionId"`
+	PaidAt        *int64           `json:"paidAt"`
+}
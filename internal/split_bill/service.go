@@ -0,0 +1,165 @@
+package splitbill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/citadel-corp/paimon-bank/internal/currency"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+type Service interface {
+	Create(ctx context.Context, req CreateSplitPayload) (*SplitResponse, error)
+	Get(ctx context.Context, req GetSplitPayload) (*SplitResponse, error)
+	List(ctx context.Context, req ListSplitPayload) ([]SplitResponse, *response.Pagination, error)
+	// Settle pays the user's share of the split to its creator.
+	Settle(ctx context.Context, req SettleSplitPayload) (*SplitResponse, error)
+}
+
+type splitBillService struct {
+	repository      Repository
+	currencyService currency.Service
+	pins            userbalance.PINVerifier
+	stepUp          userbalance.StepUpPolicy
+}
+
+func NewService(repository Repository, currencyService currency.Service, pins userbalance.PINVerifier, stepUp userbalance.StepUpPolicy) Service {
+	return &splitBillService{repository: repository, currencyService: currencyService, pins: pins, stepUp: stepUp}
+}
+
+func (s *splitBillService) Create(ctx context.Context, req CreateSplitPayload) (*SplitResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	err = s.currencyService.ValidateAmount(ctx, req.Currency, req.Total)
+	if errors.Is(err, currency.ErrCurrencyNotSupported) || errors.Is(err, currency.ErrAmountBelowMinimum) || errors.Is(err, currency.ErrAmountAboveMaximum) {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	amounts, err := computeShares(req.Total, req.ShareType, req.Participants)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+
+	split := &Split{
+		ID:        id.GenerateStringID(16),
+		CreatorID: req.CreatorID,
+		Title:     req.Title,
+		Total:     req.Total,
+		Currency:  req.Currency,
+		ShareType: req.ShareType,
+	}
+	for i, p := range req.Participants {
+		split.Participants = append(split.Participants, Participant{
+			Email:  p.Email,
+			Amount: amounts[i],
+		})
+	}
+	err = s.repository.Create(ctx, split)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, split.ID, req.CreatorID)
+}
+
+func (s *splitBillService) Get(ctx context.Context, req GetSplitPayload) (*SplitResponse, error) {
+	return s.get(ctx, req.ID, req.UserID)
+}
+
+func (s *splitBillService) List(ctx context.Context, req ListSplitPayload) ([]SplitResponse, *response.Pagination, error) {
+	splits, pagination, err := s.repository.List(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := make([]SplitResponse, len(splits))
+	for i, split := range splits {
+		resp[i] = toResponse(split)
+	}
+	return resp, pagination, nil
+}
+
+func (s *splitBillService) Settle(ctx context.Context, req SettleSplitPayload) (*SplitResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	err = s.pins.VerifyPIN(ctx, req.UserID, req.PIN)
+	if err != nil {
+		return nil, err
+	}
+	// shares never change once a split is created, so the one read here is the one Settle pays
+	split, err := s.repository.Get(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range split.Participants {
+		if p.UserID != req.UserID {
+			continue
+		}
+		err = s.stepUp.Authorize(ctx, req.UserID, split.Currency, p.Amount, req.StepUpFactor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = s.repository.Settle(ctx, req.ID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(ctx, req.ID, req.UserID)
+}
+
+// get returns the split only to its creator and participants.
+func (s *splitBillService) get(ctx context.Context, id, userID string) (*SplitResponse, error) {
+	split, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	visible := split.CreatorID == userID
+	for _, p := range split.Participants {
+		visible = visible || p.UserID == userID
+	}
+	if !visible {
+		return nil, ErrSplitNotFound
+	}
+	resp := toResponse(*split)
+	return &resp, nil
+}
+
+func toResponse(s Split) SplitResponse {
+	resp := SplitResponse{
+		ID:           s.ID,
+		CreatorID:    s.CreatorID,
+		CreatorName:  s.CreatorName,
+		Title:        s.Title,
+		Total:        s.Total,
+		Currency:     s.Currency,
+		ShareType:    s.ShareType,
+		Status:       s.Status(),
+		Participants: make([]ParticipantResponse, len(s.Participants)),
+		CreatedAt:    s.CreatedAt.UnixMilli(),
+	}
+	for i, p := range s.Participants {
+		resp.Participants[i] = ParticipantResponse{
+			UserID:        p.UserID,
+			Email:         p.Email,
+			Name:          p.Name,
+			Amount:        p.Amount,
+			Status:        p.Status,
+			TransactionID: p.TransactionID,
+		}
+		if p.PaidAt != nil {
+			paidAt := p.PaidAt.UnixMilli()
+			resp.Participants[i].PaidAt = &paidAt
+		}
+		if p.Status == ObligationStatusPaid {
+			resp.PaidTotal += p.Amount
+		}
+	}
+	return resp
+}
@@ -0,0 +1,46 @@
+package splitbill
+
+import "math"
+
+// computeShares returns how much each participant owes, in the order given.
+// Units that do not divide evenly go one each to the first participants.
+func computeShares(total int, shareType ShareType, participants []ParticipantPayload) ([]int, error) {
+	amounts := make([]int, len(participants))
+	switch shareType {
+	case ShareTypeEqual:
+		for i := range amounts {
+			amounts[i] = total / len(participants)
+		}
+	case ShareTypeFixed:
+		sum := 0
+		for i, p := range participants {
+			amounts[i] = p.Amount
+			sum += p.Amount
+		}
+		if sum != total {
+			return nil, ErrSharesDoNotAddUp
+		}
+		return amounts, nil
+	case ShareTypePercentage:
+		// work in basis points so two-decimal percentages are exact
+		sum := 0
+		for i, p := range participants {
+			bp := int(math.Round(p.Percentage * 100))
+			amounts[i] = total * bp / 10000
+			sum += bp
+		}
+		if sum != 10000 {
+			return nil, ErrSharesDoNotAddUp
+		}
+	}
+
+	remainder := total
+	for _, a := range amounts {
+		remainder -= a
+	}
+	for i := 0; remainder > 0; i = (i + 1) % len(amounts) {
+		amounts[i]++
+		remainder--
+	}
+	return amounts, nil
+}
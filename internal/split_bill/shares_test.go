@@ -0,0 +1,94 @@
+package splitbill
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestComputeShares(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		shareType    ShareType
+		participants []ParticipantPayload
+		want         []int
+		wantErr      error
+	}{
+		{
+			name:         "equal without remainder",
+			total:        90,
+			shareType:    ShareTypeEqual,
+			participants: []ParticipantPayload{{}, {}, {}},
+			want:         []int{30, 30, 30},
+		},
+		{
+			name:         "equal remainder goes to the first participants",
+			total:        101,
+			shareType:    ShareTypeEqual,
+			participants: []ParticipantPayload{{}, {}, {}},
+			want:         []int{34, 34, 33},
+		},
+		{
+			name:         "equal total smaller than participants",
+			total:        2,
+			shareType:    ShareTypeEqual,
+			participants: []ParticipantPayload{{}, {}, {}},
+			want:         []int{1, 1, 0},
+		},
+		{
+			name:         "fixed",
+			total:        100,
+			shareType:    ShareTypeFixed,
+			participants: []ParticipantPayload{{Amount: 70}, {Amount: 30}},
+			want:         []int{70, 30},
+		},
+		{
+			name:         "fixed not adding up",
+			total:        100,
+			shareType:    ShareTypeFixed,
+			participants: []ParticipantPayload{{Amount: 70}, {Amount: 20}},
+			wantErr:      ErrSharesDoNotAddUp,
+		},
+		{
+			name:         "percentage",
+			total:        1000,
+			shareType:    ShareTypePercentage,
+			participants: []ParticipantPayload{{Percentage: 12.5}, {Percentage: 87.5}},
+			want:         []int{125, 875},
+		},
+		{
+			name:         "percentage with two decimals",
+			total:        100,
+			shareType:    ShareTypePercentage,
+			participants: []ParticipantPayload{{Percentage: 33.33}, {Percentage: 33.33}, {Percentage: 33.34}},
+			want:         []int{34, 33, 33},
+		},
+		{
+			name:         "percentage remainder goes to the first participants",
+			total:        101,
+			shareType:    ShareTypePercentage,
+			participants: []ParticipantPayload{{Percentage: 50}, {Percentage: 50}},
+			want:         []int{51, 50},
+		},
+		{
+			name:         "percentage not adding up",
+			total:        100,
+			shareType:    ShareTypePercentage,
+			participants: []ParticipantPayload{{Percentage: 50}, {Percentage: 49.99}},
+			wantErr:      ErrSharesDoNotAddUp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := computeShares(tt.total, tt.shareType, tt.participants)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("computeShares() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("computeShares() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package splitbill
+
+import "time"
+
+type ShareType string
+
+const (
+	ShareTypeEqual      ShareType = "equal"
+	ShareTypeFixed      ShareType = "fixed"
+	ShareTypePercentage ShareType = "percentage"
+)
+
+type ObligationStatus string
+
+const (
+	ObligationStatusPending ObligationStatus = "pending"
+	ObligationStatusPaid    ObligationStatus = "paid"
+)
+
+type Status string
+
+const (
+	StatusOpen    Status = "open"
+	StatusSettled Status = "settled"
+)
+
+// Split is a bill paid by its creator and shared among participants, who each owe the creator their share.
+type Split struct {
+	ID           string
+	CreatorID    string
+	CreatorName  string
+	Title        string
+	Total        int
+	Currency     string
+	ShareType    ShareType
+	CreatedAt    time.Time
+	Participants []Participant
+}
+
+// Participant is one user's obligation in a split.
+// The creator's own share, if they take part, is paid from the start and has no transaction.
+type Participant struct {
+	UserID        string
+	Email         string
+	Name          string
+	Amount        int
+	Status        ObligationStatus
+	TransactionID *string
+	PaidAt        *time.Time
+}
+
+// Status reports the split as settled once every participant has paid.
+func (s Split) Status() Status {
+	for _, p := range s.Participants {
+		if p.Status != ObligationStatusPaid {
+			return StatusOpen
+		}
+	}
+	return StatusSettled
+}
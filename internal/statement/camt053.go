@@ -0,0 +1,155 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// camt053Document covers the parts of camt.053 (any version) needed to extract credits.
+// Element names are matched without namespace so that every message version is accepted.
+type camt053Document struct {
+	Statements []struct {
+		ID      string         `xml:"Id"`
+		Account camt053Account `xml:"Acct"`
+		Entries []struct {
+			Amount struct {
+				Value    string `xml:",chardata"`
+				Currency string `xml:"Ccy,attr"`
+			} `xml:"Amt"`
+			CreditDebit    string      `xml:"CdtDbtInd"`
+			Reversal       bool        `xml:"RvslInd"`
+			BookingDate    camt053Date `xml:"BookgDt"`
+			EntryReference string      `xml:"NtryRef"`
+			ServicerRef    string      `xml:"AcctSvcrRef"`
+			Details        []struct {
+				Transactions []struct {
+					References struct {
+						EndToEndID  string `xml:"EndToEndId"`
+						ServicerRef string `xml:"AcctSvcrRef"`
+					} `xml:"Refs"`
+					RelatedParties struct {
+						Debtor struct {
+							Name string `xml:"Nm"`
+						} `xml:"Dbtr"`
+						DebtorAccount camt053Account `xml:"DbtrAcct"`
+					} `xml:"RltdPties"`
+					Remittance struct {
+						Unstructured []string `xml:"Ustrd"`
+						Structured   []struct {
+							CreditorReference struct {
+								Reference string `xml:"Ref"`
+							} `xml:"CdtrRefInf"`
+						} `xml:"Strd"`
+					} `xml:"RmtInf"`
+				} `xml:"TxDtls"`
+			} `xml:"NtryDtls"`
+			AdditionalInfo string `xml:"AddtlNtryInf"`
+		} `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camt053Account struct {
+	ID struct {
+		IBAN  string `xml:"IBAN"`
+		Other struct {
+			ID string `xml:"Id"`
+		} `xml:"Othr"`
+	} `xml:"Id"`
+}
+
+func (a camt053Account) String() string {
+	if a.ID.IBAN != "" {
+		return a.ID.IBAN
+	}
+	return a.ID.Other.ID
+}
+
+type camt053Date struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+func (d camt053Date) Time() time.Time {
+	if t, err := time.Parse(time.DateOnly, d.Date); err == nil {
+		return t
+	}
+	if len(d.DateTime) >= len(time.DateOnly) {
+		if t, err := time.Parse(time.DateOnly, d.DateTime[:len(time.DateOnly)]); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func parseCAMT053(data []byte) (*Statement, error) {
+	var doc camt053Document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, fmt.Errorf("%w: no statement found", ErrMalformed)
+	}
+
+	s := &Statement{
+		ID:      doc.Statements[0].ID,
+		Account: NormalizeAccount(doc.Statements[0].Account.String()),
+	}
+	for _, stmt := range doc.Statements {
+		for i, entry := range stmt.Entries {
+			if entry.CreditDebit != "CRDT" || entry.Reversal {
+				continue
+			}
+			amount, valid, err := parseAmount(entry.Amount.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: entry %d amount %q", ErrMalformed, i+1, entry.Amount.Value)
+			}
+			line := CreditLine{
+				EntryReference: firstNonEmpty(entry.ServicerRef, entry.EntryReference, fmt.Sprintf("%s-%d", stmt.ID, i+1)),
+				BookingDate:    entry.BookingDate.Time(),
+				Amount:         amount,
+				Valid:          valid,
+				Currency:       entry.Amount.Currency,
+			}
+			references := []string{entry.AdditionalInfo}
+			for _, details := range entry.Details {
+				for _, tx := range details.Transactions {
+					references = append(references, tx.References.EndToEndID)
+					references = append(references, tx.Remittance.Unstructured...)
+					for _, structured := range tx.Remittance.Structured {
+						references = append(references, structured.CreditorReference.Reference)
+					}
+					if line.SenderAccount == "" {
+						line.SenderAccount = NormalizeAccount(tx.RelatedParties.DebtorAccount.String())
+					}
+					if line.SenderName == "" {
+						line.SenderName = strings.TrimSpace(tx.RelatedParties.Debtor.Name)
+					}
+				}
+			}
+			line.Reference = joinNonEmpty(references)
+			s.Credits = append(s.Credits, line)
+		}
+	}
+	return s, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func joinNonEmpty(values []string) string {
+	var parts []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" && v != "NOTPROVIDED" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,137 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// statementLinePattern matches the :61: field: value date, optional entry date, debit/credit mark,
+// optional funds code, amount, transaction type, customer reference and optional bank reference.
+var statementLinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?([\d,]+)([A-Z][A-Z0-9]{3})([^/\n]*)(?://(.*))?`)
+
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+func parseMT940(data []byte) (*Statement, error) {
+	fields, err := splitMT940Fields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Statement{}
+	currency := ""
+	var pending *CreditLine
+	flush := func() {
+		if pending != nil {
+			s.Credits = append(s.Credits, *pending)
+			pending = nil
+		}
+	}
+
+	for i, f := range fields {
+		switch f.tag {
+		case "20":
+			if s.ID == "" {
+				s.ID = strings.TrimSpace(f.value)
+			}
+		case "25":
+			if s.Account == "" {
+				s.Account = NormalizeAccount(f.value)
+			}
+		case "60F", "60M":
+			// opening balance: D/C mark, YYMMDD, currency, amount
+			if len(f.value) >= 10 {
+				currency = f.value[7:10]
+			}
+		case "61":
+			flush()
+			m := statementLinePattern.FindStringSubmatch(f.value)
+			if m == nil {
+				return nil, fmt.Errorf("%w: field %d :61:%s", ErrMalformed, i+1, f.value)
+			}
+			if m[3] != "C" {
+				// debits and reversals are not incoming payments
+				continue
+			}
+			amount, valid, err := parseAmount(m[5])
+			if err != nil {
+				return nil, fmt.Errorf("%w: field %d amount %q", ErrMalformed, i+1, m[5])
+			}
+			bookingDate, _ := time.Parse("060102", m[1])
+			customerRef := strings.TrimSpace(m[7])
+			pending = &CreditLine{
+				EntryReference: firstNonEmpty(strings.TrimSpace(m[8]), fmt.Sprintf("%s-%d", s.ID, i+1)),
+				BookingDate:    bookingDate,
+				Amount:         amount,
+				Valid:          valid,
+				Currency:       currency,
+				Reference:      joinNonEmpty([]string{customerRef}),
+			}
+		case "86":
+			if pending == nil {
+				continue
+			}
+			info := strings.ReplaceAll(f.value, "\n", "")
+			pending.Reference = joinNonEmpty([]string{pending.Reference, info})
+			pending.SenderAccount = NormalizeAccount(firstNonEmpty(subfield(info, "/ACCT/"), subfield(info, "?31")))
+			pending.SenderName = firstNonEmpty(subfield(info, "/NAME/"), subfield(info, "?32")+subfield(info, "?33"))
+		case "62F", "62M":
+			flush()
+		}
+	}
+	flush()
+
+	if s.ID == "" {
+		return nil, fmt.Errorf("%w: missing :20: reference", ErrMalformed)
+	}
+	return s, nil
+}
+
+// splitMT940Fields splits the message into tagged fields, joining continuation lines.
+func splitMT940Fields(data []byte) ([]mt940Field, error) {
+	var fields []mt940Field
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || line == "-" || strings.HasPrefix(line, "{") || strings.HasPrefix(line, "-}") {
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			tag, value, ok := strings.Cut(line[1:], ":")
+			if ok {
+				fields = append(fields, mt940Field{tag: tag, value: value})
+				continue
+			}
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%w: content before first field", ErrMalformed)
+		}
+		fields[len(fields)-1].value += "\n" + line
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: no fields found", ErrMalformed)
+	}
+	return fields, nil
+}
+
+// subfield returns the value following key in :86: information, up to the next subfield marker.
+func subfield(info, key string) string {
+	i := strings.Index(info, key)
+	if i < 0 {
+		return ""
+	}
+	rest := info[i+len(key):]
+	if end := strings.IndexAny(rest, "/?"); end >= 0 {
+		rest = rest[:end]
+	}
+	return strings.TrimSpace(rest)
+}
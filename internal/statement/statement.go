@@ -0,0 +1,82 @@
+// Package statement parses partner-bank account statements.
+package statement
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown statement format")
+	ErrMalformed     = errors.New("malformed statement")
+)
+
+type Format string
+
+const (
+	FormatCAMT053 Format = "camt053"
+	FormatMT940   Format = "mt940"
+)
+
+// Statement is a parsed account statement. Only credit entries are kept.
+type Statement struct {
+	ID      string
+	Account string
+	Credits []CreditLine
+}
+
+// CreditLine is an incoming payment booked on the statement account.
+type CreditLine struct {
+	EntryReference string
+	BookingDate    time.Time
+	// Amount is the credited amount in whole currency units. Valid is false when the
+	// booked amount has a fractional part and cannot be represented.
+	Amount        int
+	Valid         bool
+	Currency      string
+	Reference     string
+	SenderAccount string
+	SenderName    string
+}
+
+// Parse parses a statement in the given format.
+func Parse(format Format, data []byte) (*Statement, error) {
+	switch format {
+	case FormatCAMT053:
+		return parseCAMT053(data)
+	case FormatMT940:
+		return parseMT940(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// NormalizeAccount strips whitespace and dashes and upper-cases an account number for comparison.
+func NormalizeAccount(account string) string {
+	return strings.ToUpper(strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '\t' {
+			return -1
+		}
+		return r
+	}, account))
+}
+
+// parseAmount parses a decimal amount using either '.' or ',' as decimal separator.
+// The second result is false when the amount has a non-zero fractional part.
+func parseAmount(s string) (int, bool, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, ",", "."))
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	amount, err := strconv.Atoi(whole)
+	if err != nil {
+		return 0, false, ErrMalformed
+	}
+	if strings.Trim(fraction, "0") != "" {
+		return amount, false, nil
+	}
+	return amount, true, nil
+}
@@ -0,0 +1,252 @@
+package statement
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const mt940Sample = `{1:F01PAIMIDJAXXXX0000000000}{2:I940PAIMIDJAXXXXN}{4:
+:20:STMT240301
+:25:1234-5678 90
+:28C:1/1
+:60F:C240229IDR1000000,00
+:61:2403010301C150000,00NTRFVA8801234567//BANKREF1
+:86:/ACCT/9876543210/NAME/Budi Santoso/REMI/top up
+:61:240301D5000,00NTRFFEE
+:86:/REMI/admin fee
+:61:240302C1000,50NTRFNONREF
+:86:?20Invoice 7?31 111-222
+?32Siti Aminah
+:62F:C240302IDR1145000,50
+-}`
+
+const camt053Sample = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Id>CAMT240301</Id>
+      <Acct><Id><Othr><Id>1234-567890</Id></Othr></Id></Acct>
+      <Ntry>
+        <NtryRef>E1</NtryRef>
+        <Amt Ccy="IDR">250000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <BookgDt><Dt>2024-03-01</Dt></BookgDt>
+        <AcctSvcrRef>SVC1</AcctSvcrRef>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>E2E1</EndToEndId></Refs>
+            <RltdPties>
+              <Dbtr><Nm>Budi Santoso</Nm></Dbtr>
+              <DbtrAcct><Id><IBAN>ID12 3456</IBAN></Id></DbtrAcct>
+            </RltdPties>
+            <RmtInf><Ustrd>VA8801234567</Ustrd></RmtInf>
+          </TxDtls>
+        </NtryDtls>
+        <AddtlNtryInf>Transfer</AddtlNtryInf>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="IDR">5000.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <BookgDt><Dt>2024-03-01</Dt></BookgDt>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="IDR">7000.00</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <RvslInd>true</RvslInd>
+        <BookgDt><Dt>2024-03-01</Dt></BookgDt>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="USD">100.25</Amt>
+        <CdtDbtInd>CRDT</CdtDbtInd>
+        <BookgDt><DtTm>2024-03-02T10:00:00+07:00</DtTm></BookgDt>
+        <NtryDtls>
+          <TxDtls>
+            <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+            <RmtInf><Strd><CdtrRefInf><Ref>RF18INV7</Ref></CdtrRefInf></Strd></RmtInf>
+          </TxDtls>
+        </NtryDtls>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		data    string
+		want    *Statement
+		wantErr error
+	}{
+		{
+			name:   "mt940",
+			format: FormatMT940,
+			data:   mt940Sample,
+			want: &Statement{
+				ID:      "STMT240301",
+				Account: "1234567890",
+				Credits: []CreditLine{
+					{
+						EntryReference: "BANKREF1",
+						BookingDate:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+						Amount:         150000,
+						Valid:          true,
+						Currency:       "IDR",
+						Reference:      "VA8801234567 /ACCT/9876543210/NAME/Budi Santoso/REMI/top up",
+						SenderAccount:  "9876543210",
+						SenderName:     "Budi Santoso",
+					},
+					{
+						EntryReference: "STMT240301-9",
+						BookingDate:    time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+						Amount:         1000,
+						Valid:          false,
+						Currency:       "IDR",
+						Reference:      "NONREF ?20Invoice 7?31 111-222?32Siti Aminah",
+						SenderAccount:  "111222",
+						SenderName:     "Siti Aminah",
+					},
+				},
+			},
+		},
+		{
+			name:    "mt940 without fields",
+			format:  FormatMT940,
+			data:    "",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "mt940 with content before the first field",
+			format:  FormatMT940,
+			data:    "hello\n:20:STMT1",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "mt940 with a malformed statement line",
+			format:  FormatMT940,
+			data:    ":20:STMT1\n:61:garbage",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "mt940 without reference",
+			format:  FormatMT940,
+			data:    ":25:1234567890\n:61:240301C100,00NTRFREF",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:   "camt.053",
+			format: FormatCAMT053,
+			data:   camt053Sample,
+			want: &Statement{
+				ID:      "CAMT240301",
+				Account: "1234567890",
+				Credits: []CreditLine{
+					{
+						EntryReference: "SVC1",
+						BookingDate:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+						Amount:         250000,
+						Valid:          true,
+						Currency:       "IDR",
+						Reference:      "Transfer E2E1 VA8801234567",
+						SenderAccount:  "ID123456",
+						SenderName:     "Budi Santoso",
+					},
+					{
+						EntryReference: "CAMT240301-4",
+						BookingDate:    time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+						Amount:         100,
+						Valid:          false,
+						Currency:       "USD",
+						Reference:      "RF18INV7",
+					},
+				},
+			},
+		},
+		{
+			name:    "camt.053 that is not XML",
+			format:  FormatCAMT053,
+			data:    "not xml",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "camt.053 without statement",
+			format:  FormatCAMT053,
+			data:    `<Document><BkToCstmrStmt></BkToCstmrStmt></Document>`,
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "camt.053 with a malformed amount",
+			format:  FormatCAMT053,
+			data:    `<Document><BkToCstmrStmt><Stmt><Id>S1</Id><Ntry><Amt Ccy="IDR">1O0</Amt><CdtDbtInd>CRDT</CdtDbtInd></Ntry></Stmt></BkToCstmrStmt></Document>`,
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "unknown format",
+			format:  "bai2",
+			data:    mt940Sample,
+			wantErr: ErrUnknownFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.format, []byte(tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in        string
+		want      int
+		wantValid bool
+		wantErr   bool
+	}{
+		{in: "150000,00", want: 150000, wantValid: true},
+		{in: "150000.00", want: 150000, wantValid: true},
+		{in: "150000", want: 150000, wantValid: true},
+		{in: " 42, ", want: 42, wantValid: true},
+		{in: "1000,50", want: 1000, wantValid: false},
+		{in: ",5", want: 0, wantValid: false},
+		// grouping separators are not supported, the line is left for manual review
+		{in: "1.000,00", want: 1, wantValid: false},
+		{in: "12x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, valid, err := parseAmount(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAmount(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want || valid != tt.wantValid {
+				t.Errorf("parseAmount(%q) = %d, %v, want %d, %v", tt.in, got, valid, tt.want, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestNormalizeAccount(t *testing.T) {
+	tests := map[string]string{
+		"1234-5678 90":   "1234567890",
+		"id12\t3456":     "ID123456",
+		"":               "",
+		"NL91ABNA041716": "NL91ABNA041716",
+	}
+	for in, want := range tests {
+		if got := NormalizeAccount(in); got != want {
+			t.Errorf("NormalizeAccount(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
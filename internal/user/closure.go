@@ -0,0 +1,34 @@
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/mailer"
+)
+
+// exportPageSize is how many transactions are read at a time while building an export.
+const exportPageSize = 500
+
+// exportImage is a proof of transfer image the user uploaded with a transaction.
+type exportImage struct {
+	TransactionID string `json:"transactionId"`
+	URL           string `json:"url"`
+}
+
+func accountClosedMessage(user *User, closedAt time.Time, paidOut bool) mailer.Message {
+	payout := ""
+	if paidOut {
+		payout = " The remaining balances are on their way to the bank account you named."
+	}
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Your Paimon Bank account is closed",
+		Body: fmt.Sprintf(`Hi %s,
+
+Your Paimon Bank account was closed on %s.%s
+
+If you did not close your account, contact us right away.
+`, user.Name, closedAt.UTC().Format("2 January 2006 15:04 MST"), payout),
+	}
+}
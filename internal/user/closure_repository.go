@@ -0,0 +1,72 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+// CloseAccount implements Repository.
+// In one transaction it marks the user closed, settles their balances through payout, revokes
+// refresh tokens and invalidates outstanding password reset and email verification tokens. It
+// returns the closing time and the payouts queued; nothing changes when the balances or open
+// escrows prevent closing.
+func (d *dbRepository) CloseAccount(ctx context.Context, userID uint64, payout *userbalance.ClosingPayout) (time.Time, []userbalance.OutgoingTransfer, error) {
+	var closedAt time.Time
+	var transfers []userbalance.OutgoingTransfer
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		closeAccountQuery := `
+			UPDATE users
+			SET closed_at = current_timestamp
+			WHERE id = $1 AND closed_at IS NULL
+			RETURNING closed_at
+		`
+		err := tx.QueryRowContext(ctx, closeAccountQuery, userID).Scan(&closedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return userbalance.ErrAccountClosed
+		}
+		if err != nil {
+			return err
+		}
+
+		var escrowsHeld bool
+		escrowsHeldQuery := `
+			SELECT EXISTS (
+				SELECT 1 FROM escrows
+				WHERE (buyer_id = $1 OR seller_id = $1) AND status = 'held'
+			)
+		`
+		err = tx.QueryRowContext(ctx, escrowsHeldQuery, userID).Scan(&escrowsHeld)
+		if err != nil {
+			return err
+		}
+		if escrowsHeld {
+			return ErrEscrowsHeld
+		}
+
+		transfers, err = userbalance.SettleBalancesForClosure(ctx, tx, fmt.Sprint(userID), payout)
+		if err != nil {
+			return err
+		}
+
+		for _, invalidateTokensQuery := range []string{
+			`UPDATE refresh_tokens SET revoked_at = current_timestamp WHERE user_id = $1 AND revoked_at IS NULL`,
+			`UPDATE password_resets SET used_at = current_timestamp WHERE user_id = $1 AND used_at IS NULL`,
+			`UPDATE email_verifications SET used_at = current_timestamp WHERE user_id = $1 AND used_at IS NULL`,
+		} {
+			_, err = tx.ExecContext(ctx, invalidateTokensQuery, userID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	return closedAt, transfers, nil
+}
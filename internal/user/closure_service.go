@@ -0,0 +1,126 @@
+package user
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+func (s *userService) CloseAccount(ctx context.Context, req CloseAccountPayload) (*ClosureResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	user, err := s.repository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.confirmIdentity(ctx, user, req.Password, req.SecondFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	var payout *userbalance.ClosingPayout
+	if req.PayoutBankAccountNumber != "" {
+		payout = &userbalance.ClosingPayout{
+			BankAccountNumber: req.PayoutBankAccountNumber,
+			BankName:          req.PayoutBankName,
+		}
+	}
+	closedAt, transfers, err := s.repository.CloseAccount(ctx, user.ID, payout)
+	if err != nil {
+		return nil, err
+	}
+
+	// the account is closed and its refresh tokens revoked by now, so a failure here only leaves
+	// access tokens working until they expire; the closure is still reported
+	err = s.revoker.RevokeUser(ctx, fmt.Sprint(user.ID))
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot revoke access tokens of closed account %d: %v", user.ID, err))
+	}
+	go s.sendMail(context.WithoutCancel(ctx), accountClosedMessage(user, closedAt, len(transfers) > 0))
+
+	resp := &ClosureResponse{
+		ClosedAt: closedAt.UnixMilli(),
+		Payouts:  make([]ClosurePayoutResponse, len(transfers)),
+	}
+	for i, t := range transfers {
+		resp.Payouts[i] = ClosurePayoutResponse{
+			TransactionID: t.TransactionID,
+			Amount:        t.Amount,
+			Currency:      t.Currency,
+			Status:        t.Status,
+		}
+	}
+	return resp, nil
+}
+
+func (s *userService) ExportData(ctx context.Context, userID uint64) (*DataExport, error) {
+	profile, err := s.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.balances.FindByUserID(ctx, fmt.Sprint(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := []userbalance.UserTransactionResponse{}
+	images := []exportImage{}
+	for offset := 0; ; offset += exportPageSize {
+		page, _, err := s.balances.ListTransactions(ctx, userbalance.ListUserTransactionPayload{
+			UserID: fmt.Sprint(userID),
+			Limit:  exportPageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, ut := range page {
+			transactions = append(transactions, userbalance.ToTransactionResponse(ut))
+			if ut.ImageURL != nil && *ut.ImageURL != "" {
+				images = append(images, exportImage{TransactionID: ut.TransactionID, URL: *ut.ImageURL})
+			}
+		}
+		if len(page) < exportPageSize {
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range []struct {
+		name string
+		data any
+	}{
+		{"profile.json", profile},
+		{"balances.json", balances},
+		{"transactions.json", transactions},
+		{"images.json", images},
+	} {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(file.data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = archive.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &DataExport{
+		Filename: fmt.Sprintf("paimon-bank-export-%d-%s.zip", userID, time.Now().UTC().Format("20060102")),
+		Content:  buf.Bytes(),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+	"github.com/citadel-corp/paimon-bank/internal/mailer"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	// a user gets at most emailVerificationMaxPerHour verification emails, at least emailVerificationInterval apart
+	emailVerificationInterval    = time.Minute
+	emailVerificationMaxPerHour  = 5
+	emailVerificationTokenLength = 43
+)
+
+// EmailVerification confirms that the user owns Email, either the address they registered with
+// or one they asked to change to. Only the token's hash is stored and a newer token replaces it.
+type EmailVerification struct {
+	ID        uint64
+	UserID    uint64
+	Email     string
+	TokenHash []byte
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+func newEmailVerificationToken() (string, []byte) {
+	token := id.GenerateStringID(emailVerificationTokenLength)
+	return token, hashSecret(token)
+}
+
+func emailVerificationMessage(name, email, token string) mailer.Message {
+	return mailer.Message{
+		To:      email,
+		Subject: "Verify your Paimon Bank email address",
+		Body: fmt.Sprintf(`Hi %s,
+
+Please confirm that %s is your email address by sending this verification token within %d hours:
+
+%s
+
+Until then you can log in, but not move money. If you did not sign up for Paimon Bank, ignore this email.
+`, name, email, int(emailVerificationTTL.Hours()), token),
+	}
+}
+
+// emailChangeNotice warns the current address, in case someone else is taking the account over.
+func emailChangeNotice(user *User, newEmail string) mailer.Message {
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Your Paimon Bank email address is being changed",
+		Body: fmt.Sprintf(`Hi %s,
+
+Someone asked to change the email address of your Paimon Bank account to %s. The change takes effect once the new address is verified.
+
+If this was not you, reset your password right away.
+`, user.Name, newEmail),
+	}
+}
@@ -0,0 +1,92 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+)
+
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailPayload
+
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	resp, err := h.service.VerifyEmail(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "Email verified successfully",
+		Data:    resp,
+	})
+}
+
+func (h *Handler) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	err = h.service.ResendEmailVerification(r.Context(), userID)
+	if errors.Is(err, ErrVerificationRateLimited) {
+		response.JSON(w, http.StatusTooManyRequests, response.ResponseBody{
+			Message: "Too many requests",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, response.ResponseBody{
+		Message: "Verification email sent",
+	})
+}
+
+func (h *Handler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req ChangeEmailPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.UserID = userID
+
+	err = h.service.ChangeEmail(r.Context(), req)
+	if errors.Is(err, ErrVerificationRateLimited) {
+		response.JSON(w, http.StatusTooManyRequests, response.ResponseBody{
+			Message: "Too many requests",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, response.ResponseBody{
+		Message: "Verification email sent to the new address, the email changes once it is verified",
+	})
+}
@@ -0,0 +1,98 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// CreateEmailVerification implements Repository.
+// It replaces the user's outstanding verifications, or returns ErrVerificationRateLimited when the
+// previous one was created less than interval ago or maxPerHour were created in the last hour.
+func (d *dbRepository) CreateEmailVerification(ctx context.Context, verification *EmailVerification, interval time.Duration, maxPerHour int) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		// serializes concurrent requests for the same user
+		_, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, verification.UserID)
+		if err != nil {
+			return err
+		}
+
+		var lastHour int
+		var recent bool
+		countVerificationsQuery := `
+			SELECT count(*), coalesce(bool_or(created_at > current_timestamp - make_interval(secs => $2)), false)
+			FROM email_verifications
+			WHERE user_id = $1 AND created_at > current_timestamp - interval '1 hour'
+		`
+		err = tx.QueryRowContext(ctx, countVerificationsQuery, verification.UserID, interval.Seconds()).Scan(&lastHour, &recent)
+		if err != nil {
+			return err
+		}
+		if recent || lastHour >= maxPerHour {
+			return ErrVerificationRateLimited
+		}
+
+		invalidateVerificationsQuery := `
+			UPDATE email_verifications
+			SET used_at = current_timestamp
+			WHERE user_id = $1 AND used_at IS NULL
+		`
+		_, err = tx.ExecContext(ctx, invalidateVerificationsQuery, verification.UserID)
+		if err != nil {
+			return err
+		}
+
+		createVerificationQuery := `
+			INSERT INTO email_verifications (
+				user_id, email, token_hash, expires_at
+			) VALUES (
+				$1, $2, $3, $4
+			)
+			RETURNING id, created_at;
+		`
+		row := tx.QueryRowContext(ctx, createVerificationQuery, verification.UserID, verification.Email, verification.TokenHash, verification.ExpiresAt)
+		return row.Scan(&verification.ID, &verification.CreatedAt)
+	})
+}
+
+// VerifyEmail implements Repository.
+// It uses up the verification matching tokenHash and marks its email verified, making it the user's
+// email when it differs. Used, replaced, expired and unknown tokens are reported as ErrInvalidVerificationToken.
+func (d *dbRepository) VerifyEmail(ctx context.Context, tokenHash []byte) (*User, error) {
+	u := &User{}
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		useVerificationQuery := `
+			UPDATE email_verifications
+			SET used_at = current_timestamp
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > current_timestamp
+			RETURNING user_id, email
+		`
+		err := tx.QueryRowContext(ctx, useVerificationQuery, tokenHash).Scan(&u.ID, &u.Email)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidVerificationToken
+		}
+		if err != nil {
+			return err
+		}
+
+		verifyEmailQuery := `
+			UPDATE users
+			SET email = $2, email_verified_at = current_timestamp
+			WHERE id = $1
+			RETURNING name, email_verified_at
+		`
+		err = tx.QueryRowContext(ctx, verifyEmailQuery, u.ID, u.Email).Scan(&u.Name, &u.EmailVerifiedAt)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrEmailAlreadyExists
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
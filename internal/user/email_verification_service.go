@@ -0,0 +1,80 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (s *userService) VerifyEmail(ctx context.Context, req VerifyEmailPayload) (*EmailResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	user, err := s.repository.VerifyEmail(ctx, hashSecret(req.Token))
+	if err != nil {
+		return nil, err
+	}
+	return &EmailResponse{Email: user.Email, EmailVerified: true}, nil
+}
+
+func (s *userService) ResendEmailVerification(ctx context.Context, userID uint64) error {
+	user, err := s.repository.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	return s.startEmailVerification(ctx, user, user.Email)
+}
+
+func (s *userService) ChangeEmail(ctx context.Context, req ChangeEmailPayload) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	user, err := s.repository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	err = s.confirmPassword(ctx, user, req.Password)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(req.Email, user.Email) {
+		return fmt.Errorf("%w: email: must differ from the current email", ErrValidationFailed)
+	}
+	_, err = s.repository.GetByEmail(ctx, req.Email)
+	if err == nil {
+		return ErrEmailAlreadyExists
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+
+	err = s.startEmailVerification(ctx, user, req.Email)
+	if err != nil {
+		return err
+	}
+	go s.sendMail(context.WithoutCancel(ctx), emailChangeNotice(user, req.Email))
+	return nil
+}
+
+// startEmailVerification emails a new verification token for email to the user.
+func (s *userService) startEmailVerification(ctx context.Context, user *User, email string) error {
+	token, hash := newEmailVerificationToken()
+	err := s.repository.CreateEmailVerification(ctx, &EmailVerification{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
+	}, emailVerificationInterval, emailVerificationMaxPerHour)
+	if err != nil {
+		return err
+	}
+	go s.sendMail(context.WithoutCancel(ctx), emailVerificationMessage(user.Name, email, token))
+	return nil
+}
@@ -7,4 +7,23 @@ var (
 	ErrWrongPassword      = errors.New("wrong password")
 	ErrEmailAlreadyExists = errors.New("email already exists")
 	ErrValidationFailed   = errors.New("validation failed")
+	ErrInvalidToken       = errors.New("refresh token is invalid or expired")
+	ErrTokenReused        = errors.New("refresh token was already used, all sessions from it have been revoked")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled     = errors.New("two-factor authentication is not set up")
+	ErrInvalidMFACode     = errors.New("code is invalid or was already used")
+	ErrInvalidChallenge   = errors.New("two-factor challenge is invalid or expired, log in again")
+	ErrMFACodeRequired    = errors.New("a two-factor code or recovery code is required")
+	ErrPINAlreadySet      = errors.New("transaction PIN is already set, change or reset it instead")
+	// ErrInvalidCredentials is returned for an unknown email and a wrong password alike.
+	ErrInvalidCredentials   = errors.New("email or password is incorrect")
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+	ErrInvalidResetToken    = errors.New("password reset token is invalid or expired")
+	ErrPasswordResetTooSoon = errors.New("a password reset was requested moments ago")
+	// ErrInvalidVerificationToken also covers tokens replaced by a newer verification email.
+	ErrInvalidVerificationToken = errors.New("verification token is invalid or expired")
+	ErrEmailAlreadyVerified     = errors.New("email address is already verified")
+	ErrVerificationRateLimited  = errors.New("a verification email was sent recently, try again later")
+	ErrEscrowsHeld              = errors.New("release or cancel held escrows before closing the account")
+	ErrLastAdmin                = errors.New("at least one user must keep the admin role")
 )
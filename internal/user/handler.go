@@ -2,10 +2,14 @@ package user
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
+	"github.com/citadel-corp/paimon-bank/internal/common/middleware"
 	"github.com/citadel-corp/paimon-bank/internal/common/request"
 	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
 )
 
 type Handler struct {
@@ -66,17 +70,22 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	req.IP = request.ClientIP(r)
 	userResp, err := h.service.Login(r.Context(), req)
-	if errors.Is(err, ErrUserNotFound) {
-		response.JSON(w, http.StatusNotFound, response.ResponseBody{
-			Message: "Not found",
+	if errors.Is(err, ErrInvalidCredentials) {
+		response.JSON(w, http.StatusUnauthorized, response.ResponseBody{
+			Message: "Unauthorized",
 			Error:   err.Error(),
 		})
 		return
 	}
-	if errors.Is(err, ErrWrongPassword) {
-		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
-			Message: "Bad request",
+	if errors.Is(err, ErrTooManyLoginAttempts) {
+		writeLoginThrottled(w, err)
+		return
+	}
+	if errors.Is(err, userbalance.ErrAccountClosed) {
+		response.JSON(w, http.StatusForbidden, response.ResponseBody{
+			Message: "Forbidden",
 			Error:   err.Error(),
 		})
 		return
@@ -100,3 +109,152 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		Data:    userResp,
 	})
 }
+
+func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req RefreshTokenPayload
+
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	tokenResp, err := h.service.Refresh(r.Context(), req)
+	if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTokenReused) {
+		response.JSON(w, http.StatusUnauthorized, response.ResponseBody{
+			Message: "Unauthorized",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, ErrValidationFailed) {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Bad request",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, response.ResponseBody{
+			Message: "Internal server error",
+			Error:   err.Error(),
+		})
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "Token refreshed successfully",
+		Data:    tokenResp,
+	})
+}
+
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaims(r)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req LogoutPayload
+
+	// the body is optional, a bare request logs out the current access token only
+	if r.ContentLength != 0 {
+		err := request.DecodeJSON(w, r, &req)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+				Message: "Failed to decode JSON",
+				Error:   err.Error(),
+			})
+			return
+		}
+	}
+	req.Claims = claims
+
+	err := h.service.Logout(r.Context(), req)
+	if errors.Is(err, ErrValidationFailed) {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Bad request",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, response.ResponseBody{
+			Message: "Internal server error",
+			Error:   err.Error(),
+		})
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "User logged out successfully",
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrTooManyLoginAttempts) {
+		writeLoginThrottled(w, err)
+		return
+	}
+	status := http.StatusInternalServerError
+	message := "Internal server error"
+	switch {
+	case errors.Is(err, ErrValidationFailed),
+		errors.Is(err, ErrInvalidMFACode),
+		errors.Is(err, ErrMFACodeRequired),
+		errors.Is(err, ErrInvalidResetToken),
+		errors.Is(err, ErrInvalidVerificationToken),
+		errors.Is(err, userbalance.ErrBalanceNotEmpty),
+		errors.Is(err, userbalance.ErrBalanceOverdrawn):
+		status = http.StatusBadRequest
+		message = "Bad request"
+	case errors.Is(err, ErrWrongPassword),
+		errors.Is(err, userbalance.ErrWrongPIN),
+		errors.Is(err, userbalance.ErrAccountClosed):
+		status = http.StatusForbidden
+		message = "Forbidden"
+	case errors.Is(err, ErrMFANotEnrolled),
+		errors.Is(err, ErrUserNotFound),
+		errors.Is(err, userbalance.ErrPINNotSet):
+		status = http.StatusNotFound
+		message = "Not found"
+	case errors.Is(err, ErrMFAAlreadyEnabled),
+		errors.Is(err, ErrPINAlreadySet),
+		errors.Is(err, ErrEmailAlreadyExists),
+		errors.Is(err, ErrEmailAlreadyVerified),
+		errors.Is(err, ErrEscrowsHeld),
+		errors.Is(err, ErrLastAdmin),
+		errors.Is(err, userbalance.ErrTransfersInFlight):
+		status = http.StatusConflict
+		message = "Conflict"
+	case errors.Is(err, userbalance.ErrPINLocked):
+		status = http.StatusLocked
+		message = "Locked"
+	}
+	response.JSON(w, status, response.ResponseBody{
+		Message: message,
+		Error:   err.Error(),
+	})
+}
+
+func getUserID(r *http.Request) (uint64, error) {
+	if authValue, ok := r.Context().Value(middleware.ContextAuthKey{}).(string); ok {
+		return strconv.ParseUint(authValue, 10, 64)
+	}
+
+	return 0, errors.New("unauthorized")
+}
+
+// writeLoginThrottled tells the client how long to wait before the next login attempt.
+func writeLoginThrottled(w http.ResponseWriter, err error) {
+	headers := http.Header{}
+	var throttled *LoginThrottledError
+	if errors.As(err, &throttled) {
+		headers.Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+	}
+	response.JSONWithHeaders(w, http.StatusTooManyRequests, response.ResponseBody{
+		Message: "Too many requests",
+		Error:   err.Error(),
+	}, headers)
+}
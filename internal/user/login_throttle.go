@@ -0,0 +1,178 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/metrics"
+	"github.com/citadel-corp/paimon-bank/internal/common/password"
+)
+
+const (
+	LoginScopeAccount = "account"
+	LoginScopeIP      = "ip"
+)
+
+// loginPolicy throttles failed logins for one scope. The first freeFailures failures cost nothing,
+// each further one makes the next attempt wait twice as long, starting at a second, and reaching
+// lockAfter failures locks the scope for lockDuration. Failures are forgotten after window without one.
+type loginPolicy struct {
+	freeFailures int
+	lockAfter    int
+	lockDuration time.Duration
+	window       time.Duration
+}
+
+var loginPolicies = map[string]loginPolicy{
+	LoginScopeAccount: {freeFailures: 3, lockAfter: 10, lockDuration: 15 * time.Minute, window: time.Hour},
+	// an IP may be shared by many users behind NAT, so it gets more room before delays start
+	LoginScopeIP: {freeFailures: 10, lockAfter: 50, lockDuration: 15 * time.Minute, window: time.Hour},
+}
+
+// delay returns how long the scope is blocked after its failures-th failure and whether that is a lockout.
+func (p loginPolicy) delay(failures int) (time.Duration, bool) {
+	if failures >= p.lockAfter {
+		return p.lockDuration, true
+	}
+	if failures <= p.freeFailures {
+		return 0, false
+	}
+	// doubling stops at the lock duration, long before the shift could overflow
+	delay := time.Second
+	for i := p.freeFailures + 1; i < failures && delay < p.lockDuration; i++ {
+		delay *= 2
+	}
+	return min(delay, p.lockDuration), false
+}
+
+// LoginKey identifies what a failed login counts against: the email as typed, registered or not, or the client IP.
+type LoginKey struct {
+	Scope string
+	Key   string
+}
+
+// LoginAttempt is the outcome of counting an attempt against a LoginKey.
+type LoginAttempt struct {
+	// Failures within the window, including this attempt.
+	Failures int
+	// BlockedUntil is set when the key was blocked already; the attempt was then not counted.
+	BlockedUntil *time.Time
+}
+
+func loginKeys(email, ip string) []LoginKey {
+	keys := []LoginKey{{Scope: LoginScopeAccount, Key: strings.ToLower(email)}}
+	if ip != "" {
+		keys = append(keys, LoginKey{Scope: LoginScopeIP, Key: ip})
+	}
+	return keys
+}
+
+// LoginThrottledError is returned while the account or IP address must wait before trying again.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrTooManyLoginAttempts
+}
+
+// attemptLogin counts the attempt against keys as failed before the credentials are checked, the
+// way AttemptPIN does, so concurrent guesses cannot get past the throttle. It refuses the attempt
+// while any of keys is blocked by earlier failures. loginSucceeded takes the attempt back.
+func (s *userService) attemptLogin(ctx context.Context, keys []LoginKey) error {
+	var until time.Time
+	for _, key := range keys {
+		policy := loginPolicies[key.Scope]
+		attempt, err := s.repository.AttemptLogin(ctx, key, policy)
+		if err != nil {
+			return err
+		}
+		if attempt.BlockedUntil != nil {
+			metrics.LoginThrottled.WithLabelValues(key.Scope).Inc()
+			if attempt.BlockedUntil.After(until) {
+				until = *attempt.BlockedUntil
+			}
+			continue
+		}
+		if _, locked := policy.delay(attempt.Failures); locked {
+			metrics.LoginLockouts.WithLabelValues(key.Scope).Inc()
+		}
+	}
+	if until.IsZero() {
+		return nil
+	}
+	return &LoginThrottledError{RetryAfter: time.Until(until)}
+}
+
+// releaseLoginAttempt takes back an attempt whose credentials were right.
+func (s *userService) releaseLoginAttempt(ctx context.Context, keys []LoginKey) error {
+	for _, key := range keys {
+		err := s.repository.ReleaseLoginAttempt(ctx, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loginSucceeded takes back the attempt and clears the account's failures. The IP address keeps
+// its other failures, so one valid account does not let it guess others.
+func (s *userService) loginSucceeded(ctx context.Context, keys []LoginKey) error {
+	err := s.releaseLoginAttempt(ctx, keys)
+	if err != nil {
+		return err
+	}
+	return s.repository.ClearLoginFailures(ctx, keys[0])
+}
+
+// loginFailed returns the error shown for every kind of failed login; attemptLogin already counted it.
+func loginFailed() error {
+	metrics.LoginFailures.Inc()
+	return ErrInvalidCredentials
+}
+
+// confirmCredentials runs check, a password or second factor check of a signed-in user, as an
+// attempt on their account's login key. A stolen session then cannot guess the credentials any
+// faster than the login form can: wrong ones stay counted and lock the account like failed logins.
+func (s *userService) confirmCredentials(ctx context.Context, email string, check func() error) error {
+	keys := loginKeys(email, "")
+	err := s.attemptLogin(ctx, keys)
+	if err != nil {
+		return err
+	}
+	err = check()
+	if errors.Is(err, ErrWrongPassword) || errors.Is(err, ErrInvalidMFACode) {
+		metrics.LoginFailures.Inc()
+		return err
+	}
+	if err != nil {
+		releaseErr := s.releaseLoginAttempt(ctx, keys)
+		if releaseErr != nil {
+			return releaseErr
+		}
+		return err
+	}
+	return s.loginSucceeded(ctx, keys)
+}
+
+var (
+	dummyPasswordHash     string
+	dummyPasswordHashOnce sync.Once
+)
+
+// matchDummyPassword spends as long as checking a real password, so the response time of a
+// login does not tell whether the email is registered.
+func matchDummyPassword(plaintext string) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = password.Hash("not-a-real-password")
+	})
+	_, _ = password.Matches(plaintext, dummyPasswordHash)
+}
@@ -0,0 +1,82 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// AttemptLogin implements Repository.
+// The attempt is counted as failed and the key blocked as policy says in one transaction holding
+// the key's row, so concurrent attempts see each other's count and block. A key that is blocked
+// already is left as it is.
+func (d *dbRepository) AttemptLogin(ctx context.Context, key LoginKey, policy loginPolicy) (*LoginAttempt, error) {
+	attempt := &LoginAttempt{}
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		attemptLoginQuery := `
+			INSERT INTO login_failures (
+				scope, key, failures
+			) VALUES (
+				$1, $2, 1
+			)
+			ON CONFLICT (scope, key) DO UPDATE
+			SET failures = CASE
+					WHEN login_failures.last_failure_at < current_timestamp - make_interval(secs => $3) THEN 1
+					ELSE login_failures.failures + 1
+				END,
+				last_failure_at = current_timestamp
+			WHERE login_failures.blocked_until IS NULL OR login_failures.blocked_until <= current_timestamp
+			RETURNING failures
+		`
+		err := tx.QueryRowContext(ctx, attemptLoginQuery, key.Scope, key.Key, policy.window.Seconds()).Scan(&attempt.Failures)
+		if errors.Is(err, sql.ErrNoRows) {
+			getBlockQuery := `
+				SELECT blocked_until
+				FROM login_failures
+				WHERE scope = $1 AND key = $2
+			`
+			return tx.QueryRowContext(ctx, getBlockQuery, key.Scope, key.Key).Scan(&attempt.BlockedUntil)
+		}
+		if err != nil {
+			return err
+		}
+
+		delay, _ := policy.delay(attempt.Failures)
+		if delay == 0 {
+			return nil
+		}
+		blockLoginQuery := `
+			UPDATE login_failures
+			SET blocked_until = current_timestamp + make_interval(secs => $3)
+			WHERE scope = $1 AND key = $2
+		`
+		_, err = tx.ExecContext(ctx, blockLoginQuery, key.Scope, key.Key, delay.Seconds())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// ReleaseLoginAttempt implements Repository.
+// It uncounts one attempt. A block the attempt set stays until it runs out.
+func (d *dbRepository) ReleaseLoginAttempt(ctx context.Context, key LoginKey) error {
+	releaseAttemptQuery := `
+		UPDATE login_failures
+		SET failures = GREATEST(failures - 1, 0)
+		WHERE scope = $1 AND key = $2
+	`
+	_, err := d.db.DB().ExecContext(ctx, releaseAttemptQuery, key.Scope, key.Key)
+	return err
+}
+
+// ClearLoginFailures implements Repository.
+func (d *dbRepository) ClearLoginFailures(ctx context.Context, key LoginKey) error {
+	clearFailuresQuery := `
+		DELETE FROM login_failures
+		WHERE scope = $1 AND key = $2
+	`
+	_, err := d.db.DB().ExecContext(ctx, clearFailuresQuery, key.Scope, key.Key)
+	return err
+}
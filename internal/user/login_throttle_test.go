@@ -0,0 +1,176 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoginPolicyDelay(t *testing.T) {
+	policy := loginPolicy{freeFailures: 3, lockAfter: 10, lockDuration: 15 * time.Minute, window: time.Hour}
+
+	tests := []struct {
+		failures   int
+		wantDelay  time.Duration
+		wantLocked bool
+	}{
+		{failures: 0},
+		{failures: 1},
+		{failures: 3},
+		{failures: 4, wantDelay: time.Second},
+		{failures: 5, wantDelay: 2 * time.Second},
+		{failures: 6, wantDelay: 4 * time.Second},
+		{failures: 9, wantDelay: 32 * time.Second},
+		{failures: 10, wantDelay: 15 * time.Minute, wantLocked: true},
+		{failures: 25, wantDelay: 15 * time.Minute, wantLocked: true},
+	}
+
+	for _, tt := range tests {
+		delay, locked := policy.delay(tt.failures)
+		if delay != tt.wantDelay || locked != tt.wantLocked {
+			t.Errorf("delay(%d) = %s, %v, want %s, %v", tt.failures, delay, locked, tt.wantDelay, tt.wantLocked)
+		}
+	}
+}
+
+func TestLoginPolicyDelayIsCapped(t *testing.T) {
+	for scope, policy := range loginPolicies {
+		previous := time.Duration(0)
+		for failures := 0; failures < policy.lockAfter; failures++ {
+			delay, locked := policy.delay(failures)
+			if locked {
+				t.Errorf("%s: delay(%d) locks before %d failures", scope, failures, policy.lockAfter)
+			}
+			if delay < previous || delay > policy.lockDuration {
+				t.Errorf("%s: delay(%d) = %s, want between %s and %s", scope, failures, delay, previous, policy.lockDuration)
+			}
+			previous = delay
+		}
+	}
+}
+
+// attemptRepository counts login attempts in memory.
+// Methods attemptLogin does not call are left to the embedded nil Repository.
+type attemptRepository struct {
+	Repository
+	failures map[LoginKey]int
+	blocked  map[LoginKey]time.Time
+}
+
+func (r *attemptRepository) AttemptLogin(ctx context.Context, key LoginKey, policy loginPolicy) (*LoginAttempt, error) {
+	if until, ok := r.blocked[key]; ok {
+		return &LoginAttempt{Failures: r.failures[key], BlockedUntil: &until}, nil
+	}
+	r.failures[key]++
+	return &LoginAttempt{Failures: r.failures[key]}, nil
+}
+
+func TestAttemptLogin(t *testing.T) {
+	account := LoginKey{Scope: LoginScopeAccount, Key: "budi@example.com"}
+	ip := LoginKey{Scope: LoginScopeIP, Key: "203.0.113.7"}
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		blocked      map[LoginKey]time.Time
+		wantErr      error
+		wantRetry    time.Duration
+		wantFailures map[LoginKey]int
+	}{
+		{
+			name:         "nothing blocked",
+			wantFailures: map[LoginKey]int{account: 1, ip: 1},
+		},
+		{
+			name:         "account blocked",
+			blocked:      map[LoginKey]time.Time{account: now.Add(time.Minute)},
+			wantErr:      ErrTooManyLoginAttempts,
+			wantRetry:    time.Minute,
+			wantFailures: map[LoginKey]int{ip: 1},
+		},
+		{
+			name:         "longest block wins",
+			blocked:      map[LoginKey]time.Time{account: now.Add(time.Minute), ip: now.Add(time.Hour)},
+			wantErr:      ErrTooManyLoginAttempts,
+			wantRetry:    time.Hour,
+			wantFailures: map[LoginKey]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &attemptRepository{failures: map[LoginKey]int{}, blocked: tt.blocked}
+			service := &userService{repository: repository}
+
+			err := service.attemptLogin(context.Background(), []LoginKey{account, ip})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("attemptLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			var throttled *LoginThrottledError
+			if errors.As(err, &throttled) && (throttled.RetryAfter > tt.wantRetry || throttled.RetryAfter < tt.wantRetry-time.Second) {
+				t.Errorf("RetryAfter = %s, want %s", throttled.RetryAfter, tt.wantRetry)
+			}
+			for _, key := range []LoginKey{account, ip} {
+				if repository.failures[key] != tt.wantFailures[key] {
+					t.Errorf("%s failures = %d, want %d", key.Scope, repository.failures[key], tt.wantFailures[key])
+				}
+			}
+		})
+	}
+}
+
+func (r *attemptRepository) ReleaseLoginAttempt(ctx context.Context, key LoginKey) error {
+	r.failures[key]--
+	return nil
+}
+
+func (r *attemptRepository) ClearLoginFailures(ctx context.Context, key LoginKey) error {
+	delete(r.failures, key)
+	return nil
+}
+
+func TestConfirmCredentials(t *testing.T) {
+	account := LoginKey{Scope: LoginScopeAccount, Key: "budi@example.com"}
+	errLookup := errors.New("lookup failed")
+
+	tests := []struct {
+		name         string
+		blocked      bool
+		checkErr     error
+		wantErr      error
+		wantChecked  bool
+		wantFailures int
+	}{
+		{name: "right credentials", wantChecked: true, wantFailures: 0},
+		{name: "wrong password", checkErr: ErrWrongPassword, wantErr: ErrWrongPassword, wantChecked: true, wantFailures: 3},
+		{name: "wrong code", checkErr: ErrInvalidMFACode, wantErr: ErrInvalidMFACode, wantChecked: true, wantFailures: 3},
+		{name: "other error", checkErr: errLookup, wantErr: errLookup, wantChecked: true, wantFailures: 2},
+		{name: "locked", blocked: true, wantErr: ErrTooManyLoginAttempts, wantFailures: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &attemptRepository{failures: map[LoginKey]int{account: 2}, blocked: map[LoginKey]time.Time{}}
+			if tt.blocked {
+				repository.blocked[account] = time.Now().Add(time.Minute)
+			}
+			service := &userService{repository: repository}
+
+			checked := false
+			err := service.confirmCredentials(context.Background(), "Budi@example.com", func() error {
+				checked = true
+				return tt.checkErr
+			})
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("confirmCredentials() error = %v, want %v", err, tt.wantErr)
+			}
+			if checked != tt.wantChecked {
+				t.Errorf("check ran = %v, want %v", checked, tt.wantChecked)
+			}
+			if repository.failures[account] != tt.wantFailures {
+				t.Errorf("failures = %d, want %d", repository.failures[account], tt.wantFailures)
+			}
+		})
+	}
+}
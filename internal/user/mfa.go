@@ -0,0 +1,66 @@
+package user
+
+import (
+	"crypto/sha256"
+	"strings"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+)
+
+const (
+	totpIssuer = "Paimon Bank"
+	// mfaChallengeTTL is how long a user has to enter the code after the password was accepted.
+	mfaChallengeTTL         = 5 * time.Minute
+	mfaChallengeMaxAttempts = 5
+	mfaChallengeTokenLength = 43
+	recoveryCodeCount       = 10
+	recoveryCodeLength      = 10
+)
+
+// TOTP is a user's authenticator enrollment. It only takes effect once EnabledAt is set,
+// after the user proved they can generate codes. LastUsedStep stops a code being used twice.
+type TOTP struct {
+	UserID          uint64
+	EncryptedSecret []byte
+	LastUsedStep    int64
+	EnabledAt       *time.Time
+}
+
+// MFAChallenge is handed out by Login in place of tokens to users with TOTP enabled.
+// It is stored by hash and is good for one successful verification within mfaChallengeTTL.
+type MFAChallenge struct {
+	ID        uint64
+	UserID    uint64
+	TokenHash []byte
+	Attempts  int
+	ExpiresAt time.Time
+}
+
+func newMFAChallengeToken() (string, []byte) {
+	token := id.GenerateStringID(mfaChallengeTokenLength)
+	return token, hashSecret(token)
+}
+
+// newRecoveryCodes returns codes formatted as xxxxx-xxxxx for the user and the hashes to store.
+func newRecoveryCodes() ([]string, [][]byte) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([][]byte, recoveryCodeCount)
+	for i := range codes {
+		code := id.GenerateRecoveryCode(recoveryCodeLength)
+		codes[i] = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+		hashes[i] = hashRecoveryCode(code)
+	}
+	return codes, hashes
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so the code can be typed as printed or not.
+func hashRecoveryCode(code string) []byte {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	return hashSecret(normalized)
+}
+
+func hashSecret(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
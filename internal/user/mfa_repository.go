@@ -0,0 +1,215 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// GetTOTP implements Repository.
+func (d *dbRepository) GetTOTP(ctx context.Context, userID uint64) (*TOTP, error) {
+	getTOTPQuery := `
+		SELECT user_id, encrypted_secret, last_used_step, enabled_at
+		FROM user_totp
+		WHERE user_id = $1
+	`
+	t := &TOTP{}
+	err := d.db.DB().QueryRowContext(ctx, getTOTPQuery, userID).Scan(&t.UserID, &t.EncryptedSecret, &t.LastUsedStep, &t.EnabledAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SaveTOTP implements Repository.
+// It starts or restarts an enrollment; an enabled enrollment is left alone and ErrMFAAlreadyEnabled returned.
+func (d *dbRepository) SaveTOTP(ctx context.Context, totp *TOTP) error {
+	saveTOTPQuery := `
+		INSERT INTO user_totp (
+			user_id, encrypted_secret
+		) VALUES (
+			$1, $2
+		)
+		ON CONFLICT (user_id) DO UPDATE
+		SET encrypted_secret = EXCLUDED.encrypted_secret, last_used_step = 0, created_at = current_timestamp
+		WHERE user_totp.enabled_at IS NULL
+	`
+	res, err := d.db.DB().ExecContext(ctx, saveTOTPQuery, totp.UserID, totp.EncryptedSecret)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+// EnableTOTP implements Repository.
+// It enables the pending enrollment with step as its first used code and replaces the recovery codes.
+func (d *dbRepository) EnableTOTP(ctx context.Context, userID uint64, step int64, recoveryCodeHashes [][]byte) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		enableTOTPQuery := `
+			UPDATE user_totp
+			SET enabled_at = current_timestamp, last_used_step = $2
+			WHERE user_id = $1 AND enabled_at IS NULL AND last_used_step < $2
+		`
+		res, err := tx.ExecContext(ctx, enableTOTPQuery, userID, step)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrMFAAlreadyEnabled
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+		createCodeQuery := `
+			INSERT INTO mfa_recovery_codes (
+				user_id, code_hash
+			) VALUES (
+				$1, $2
+			)
+		`
+		for _, hash := range recoveryCodeHashes {
+			_, err = tx.ExecContext(ctx, createCodeQuery, userID, hash)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteTOTP implements Repository.
+func (d *dbRepository) DeleteTOTP(ctx context.Context, userID uint64) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+		return err
+	})
+}
+
+// UseTOTPStep implements Repository.
+// A step at or before the last used one means the code was replayed and ErrInvalidMFACode is returned.
+func (d *dbRepository) UseTOTPStep(ctx context.Context, userID uint64, step int64) error {
+	useStepQuery := `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1 AND last_used_step < $2
+	`
+	res, err := d.db.DB().ExecContext(ctx, useStepQuery, userID, step)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// UseRecoveryCode implements Repository.
+func (d *dbRepository) UseRecoveryCode(ctx context.Context, userID uint64, codeHash []byte) error {
+	useCodeQuery := `
+		UPDATE mfa_recovery_codes
+		SET used_at = current_timestamp
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	res, err := d.db.DB().ExecContext(ctx, useCodeQuery, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// CreateMFAChallenge implements Repository.
+func (d *dbRepository) CreateMFAChallenge(ctx context.Context, challenge *MFAChallenge) error {
+	createChallengeQuery := `
+		INSERT INTO mfa_challenges (
+			user_id, token_hash, expires_at
+		) VALUES (
+			$1, $2, $3
+		)
+		RETURNING id;
+	`
+	row := d.db.DB().QueryRowContext(ctx, createChallengeQuery, challenge.UserID, challenge.TokenHash, challenge.ExpiresAt)
+	return row.Scan(&challenge.ID)
+}
+
+// GetMFAChallenge implements Repository.
+// Used, expired and exhausted challenges are reported as ErrInvalidChallenge.
+func (d *dbRepository) GetMFAChallenge(ctx context.Context, tokenHash []byte) (*MFAChallenge, error) {
+	getChallengeQuery := `
+		SELECT id, user_id, token_hash, attempts, expires_at
+		FROM mfa_challenges
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > current_timestamp AND attempts < $2
+	`
+	c := &MFAChallenge{}
+	err := d.db.DB().QueryRowContext(ctx, getChallengeQuery, tokenHash, mfaChallengeMaxAttempts).Scan(&c.ID, &c.UserID, &c.TokenHash, &c.Attempts, &c.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidChallenge
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// FailMFAChallenge implements Repository.
+func (d *dbRepository) FailMFAChallenge(ctx context.Context, id uint64) error {
+	failChallengeQuery := `
+		UPDATE mfa_challenges
+		SET attempts = attempts + 1
+		WHERE id = $1
+	`
+	_, err := d.db.DB().ExecContext(ctx, failChallengeQuery, id)
+	return err
+}
+
+// CompleteMFAChallenge implements Repository.
+// It returns ErrInvalidChallenge when a concurrent request already completed the challenge.
+func (d *dbRepository) CompleteMFAChallenge(ctx context.Context, id uint64) error {
+	completeChallengeQuery := `
+		UPDATE mfa_challenges
+		SET used_at = current_timestamp
+		WHERE id = $1 AND used_at IS NULL
+	`
+	res, err := d.db.DB().ExecContext(ctx, completeChallengeQuery, id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrInvalidChallenge
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package user
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/metrics"
+	"github.com/citadel-corp/paimon-bank/internal/common/totp"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	"github.com/skip2/go-qrcode"
+)
+
+const totpQRCodeSize = 256
+
+// startMFAChallenge returns a challenge token when the user has TOTP enabled, or "" when the
+// password alone is enough.
+func (s *userService) startMFAChallenge(ctx context.Context, userID uint64) (string, error) {
+	t, err := s.repository.GetTOTP(ctx, userID)
+	if errors.Is(err, ErrMFANotEnrolled) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if t.EnabledAt == nil {
+		return "", nil
+	}
+	token, hash := newMFAChallengeToken()
+	err = s.repository.CreateMFAChallenge(ctx, &MFAChallenge{
+		UserID:    userID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(mfaChallengeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *userService) LoginMFA(ctx context.Context, req MFALoginPayload) (*UserResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	challenge, err := s.repository.GetMFAChallenge(ctx, hashSecret(req.MFAToken))
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.repository.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ClosedAt != nil {
+		return nil, userbalance.ErrAccountClosed
+	}
+
+	// wrong codes count as failed logins too, otherwise new challenges would allow unlimited guesses
+	keys := loginKeys(user.Email, req.IP)
+	err = s.attemptLogin(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkSecondFactor(ctx, challenge.UserID, req.SecondFactor)
+	if errors.Is(err, ErrInvalidMFACode) {
+		metrics.LoginFailures.Inc()
+		failErr := s.repository.FailMFAChallenge(ctx, challenge.ID)
+		if failErr != nil {
+			return nil, failErr
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = s.repository.CompleteMFAChallenge(ctx, challenge.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.loginSucceeded(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.issueTokens(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &UserResponse{
+		Email:         user.Email,
+		Name:          user.Name,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		AccessToken:   tokens.AccessToken,
+		RefreshToken:  tokens.RefreshToken,
+		ExpiresIn:     tokens.ExpiresIn,
+	}, nil
+}
+
+func (s *userService) EnrollTOTP(ctx context.Context, userID uint64) (*TOTPEnrollmentResponse, error) {
+	user, err := s.repository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.secrets.Seal(secret, totpAdditionalData(userID))
+	if err != nil {
+		return nil, err
+	}
+	err = s.repository.SaveTOTP(ctx, &TOTP{
+		UserID:          userID,
+		EncryptedSecret: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	uri := totp.URI(totpIssuer, user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, totpQRCodeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &TOTPEnrollmentResponse{
+		Secret: totp.EncodeSecret(secret),
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+func (s *userService) ConfirmTOTP(ctx context.Context, req ConfirmTOTPPayload) (*RecoveryCodesResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	t, err := s.repository.GetTOTP(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if t.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	step, err := s.validateTOTP(t, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	codes, hashes := newRecoveryCodes()
+	err = s.repository.EnableTOTP(ctx, req.UserID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *userService) DisableTOTP(ctx context.Context, req DisableTOTPPayload) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	enabled, err := s.totpEnabled(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return ErrMFANotEnrolled
+	}
+	user, err := s.repository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	err = s.confirmIdentity(ctx, user, req.Password, req.SecondFactor)
+	if err != nil {
+		return err
+	}
+	return s.repository.DeleteTOTP(ctx, req.UserID)
+}
+
+func (s *userService) totpEnabled(ctx context.Context, userID uint64) (bool, error) {
+	t, err := s.repository.GetTOTP(ctx, userID)
+	if errors.Is(err, ErrMFANotEnrolled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return t.EnabledAt != nil, nil
+}
+
+// checkSecondFactor accepts a current authenticator code or an unused recovery code of a user
+// with TOTP enabled, and marks it used.
+func (s *userService) checkSecondFactor(ctx context.Context, userID uint64, factor SecondFactor) error {
+	t, err := s.repository.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if t.EnabledAt == nil {
+		return ErrMFANotEnrolled
+	}
+	if factor.RecoveryCode != "" {
+		return s.repository.UseRecoveryCode(ctx, userID, hashRecoveryCode(factor.RecoveryCode))
+	}
+	step, err := s.validateTOTP(t, factor.Code)
+	if err != nil {
+		return err
+	}
+	return s.repository.UseTOTPStep(ctx, userID, step)
+}
+
+func (s *userService) validateTOTP(t *TOTP, code string) (int64, error) {
+	secret, err := s.secrets.Open(t.EncryptedSecret, totpAdditionalData(t.UserID))
+	if err != nil {
+		return 0, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), t.LastUsedStep)
+	if !ok {
+		return 0, ErrInvalidMFACode
+	}
+	return step, nil
+}
+
+// totpAdditionalData binds an encrypted secret to its user so it cannot be copied to another account.
+func totpAdditionalData(userID uint64) []byte {
+	return []byte(fmt.Sprintf("totp:%d", userID))
+}
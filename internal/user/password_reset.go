@@ -0,0 +1,46 @@
+package user
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+	"github.com/citadel-corp/paimon-bank/internal/mailer"
+)
+
+const (
+	passwordResetTTL = 30 * time.Minute
+	// passwordResetInterval is the least time between two reset emails to the same user.
+	passwordResetInterval    = time.Minute
+	passwordResetTokenLength = 43
+)
+
+// PasswordReset lets the holder of the emailed token set a new password once within passwordResetTTL.
+// Only the token's hash is stored, and requesting a new token invalidates the earlier ones.
+type PasswordReset struct {
+	ID        uint64
+	UserID    uint64
+	TokenHash []byte
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+func newPasswordResetToken() (string, []byte) {
+	token := id.GenerateStringID(passwordResetTokenLength)
+	return token, hashSecret(token)
+}
+
+func passwordResetMessage(user *User, token string) mailer.Message {
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Paimon Bank password",
+		Body: fmt.Sprintf(`Hi %s,
+
+Someone asked to reset the password of your Paimon Bank account. To choose a new password, use this reset token within %d minutes:
+
+%s
+
+If this was not you, ignore this email; your password stays the same.
+`, user.Name, int(passwordResetTTL.Minutes()), token),
+	}
+}
@@ -0,0 +1,115 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// CreatePasswordReset implements Repository.
+// It invalidates the user's earlier reset tokens. When one was issued less than interval ago no token
+// is created and ErrPasswordResetTooSoon is returned.
+func (d *dbRepository) CreatePasswordReset(ctx context.Context, reset *PasswordReset, interval time.Duration) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		// serializes concurrent requests for the same user
+		_, err := tx.ExecContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, reset.UserID)
+		if err != nil {
+			return err
+		}
+
+		var recent bool
+		recentResetQuery := `
+			SELECT EXISTS (
+				SELECT 1 FROM password_resets
+				WHERE user_id = $1 AND created_at > current_timestamp - make_interval(secs => $2)
+			)
+		`
+		err = tx.QueryRowContext(ctx, recentResetQuery, reset.UserID, interval.Seconds()).Scan(&recent)
+		if err != nil {
+			return err
+		}
+		if recent {
+			return ErrPasswordResetTooSoon
+		}
+
+		invalidateResetsQuery := `
+			UPDATE password_resets
+			SET used_at = current_timestamp
+			WHERE user_id = $1 AND used_at IS NULL
+		`
+		_, err = tx.ExecContext(ctx, invalidateResetsQuery, reset.UserID)
+		if err != nil {
+			return err
+		}
+
+		createResetQuery := `
+			INSERT INTO password_resets (
+				user_id, token_hash, expires_at
+			) VALUES (
+				$1, $2, $3
+			)
+			RETURNING id, created_at;
+		`
+		row := tx.QueryRowContext(ctx, createResetQuery, reset.UserID, reset.TokenHash, reset.ExpiresAt)
+		return row.Scan(&reset.ID, &reset.CreatedAt)
+	})
+}
+
+// ResetPassword implements Repository.
+// It uses up the reset token matching tokenHash, together with every other outstanding token of its
+// user, sets the new password and revokes the user's refresh tokens. Used, expired and unknown tokens,
+// and tokens of closed accounts, are reported as ErrInvalidResetToken.
+func (d *dbRepository) ResetPassword(ctx context.Context, tokenHash []byte, hashedPassword string) (*User, error) {
+	u := &User{}
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		useResetQuery := `
+			UPDATE password_resets
+			SET used_at = current_timestamp
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > current_timestamp
+				AND user_id IN (SELECT id FROM users WHERE closed_at IS NULL)
+			RETURNING user_id
+		`
+		err := tx.QueryRowContext(ctx, useResetQuery, tokenHash).Scan(&u.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		if err != nil {
+			return err
+		}
+
+		invalidateResetsQuery := `
+			UPDATE password_resets
+			SET used_at = current_timestamp
+			WHERE user_id = $1 AND used_at IS NULL
+		`
+		_, err = tx.ExecContext(ctx, invalidateResetsQuery, u.ID)
+		if err != nil {
+			return err
+		}
+
+		updatePasswordQuery := `
+			UPDATE users
+			SET hashed_password = $2
+			WHERE id = $1
+			RETURNING email, name
+		`
+		err = tx.QueryRowContext(ctx, updatePasswordQuery, u.ID, hashedPassword).Scan(&u.Email, &u.Name)
+		if err != nil {
+			return err
+		}
+
+		revokeTokensQuery := `
+			UPDATE refresh_tokens
+			SET revoked_at = current_timestamp
+			WHERE user_id = $1 AND revoked_at IS NULL
+		`
+		_, err = tx.ExecContext(ctx, revokeTokensQuery, u.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.HashedPassword = hashedPassword
+	return u, nil
+}
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/password"
+	"github.com/citadel-corp/paimon-bank/internal/mailer"
+)
+
+func (s *userService) ForgotPassword(ctx context.Context, req ForgotPasswordPayload) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	// unknown emails and repeated requests look like success, so the answer does not tell who is registered
+	user, err := s.repository.GetByEmail(ctx, req.Email)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if user.ClosedAt != nil {
+		return nil
+	}
+	token, hash := newPasswordResetToken()
+	err = s.repository.CreatePasswordReset(ctx, &PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}, passwordResetInterval)
+	if errors.Is(err, ErrPasswordResetTooSoon) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// sent in the background, as waiting for the mail server would give registered emails away too
+	go s.sendMail(context.WithoutCancel(ctx), passwordResetMessage(user, token))
+	return nil
+}
+
+func (s *userService) ResetPassword(ctx context.Context, req ResetPasswordPayload) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	hashedPassword, err := password.Hash(req.Password)
+	if err != nil {
+		return err
+	}
+	user, err := s.repository.ResetPassword(ctx, hashSecret(req.Token), hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	// the password is changed and the refresh tokens revoked by now, so a failure here only leaves
+	// access tokens working until they expire; the reset still succeeded
+	err = s.revoker.RevokeUser(ctx, fmt.Sprint(user.ID))
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot revoke access tokens of user %d after a password reset: %v", user.ID, err))
+	}
+	// the token proves control of the email, so failed logins before the reset no longer count
+	return s.repository.ClearLoginFailures(ctx, loginKeys(user.Email, "")[0])
+}
+
+func (s *userService) sendMail(ctx context.Context, msg mailer.Message) {
+	err := s.mailer.Send(ctx, msg)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot send email to %s: %v", msg.To, err))
+	}
+}
@@ -0,0 +1,46 @@
+package user
+
+import (
+	"errors"
+	"time"
+
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	// pinMaxAttempts wrong PINs in a row lock the PIN for pinLockDuration. The count is only
+	// reset by a correct PIN, so after the lock expires a single wrong PIN locks it again.
+	pinMaxAttempts  = 5
+	pinLockDuration = 30 * time.Minute
+)
+
+// PIN authorizes money leaving the user's balance. It is hashed like the password.
+type PIN struct {
+	UserID         uint64
+	HashedPIN      string
+	FailedAttempts int
+	LockedUntil    *time.Time
+}
+
+// pinRules are userbalance.PINRules plus a check that the PIN is not trivially guessable.
+var pinRules = append(append([]validation.Rule{}, userbalance.PINRules...), validation.By(notWeakPIN))
+
+// notWeakPIN rejects PINs made of one repeated digit or a run of consecutive digits.
+func notWeakPIN(value interface{}) error {
+	pin, _ := value.(string)
+	if len(pin) != userbalance.PINLength {
+		return nil
+	}
+	repeated, ascending, descending := true, true, true
+	for i := 1; i < len(pin); i++ {
+		step := int(pin[i]) - int(pin[i-1])
+		repeated = repeated && step == 0
+		ascending = ascending && step == 1
+		descending = descending && step == -1
+	}
+	if repeated || ascending || descending {
+		return errors.New("must not be a repeated digit or a sequence")
+	}
+	return nil
+}
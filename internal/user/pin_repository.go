@@ -0,0 +1,95 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+// CreatePIN implements Repository.
+func (d *dbRepository) CreatePIN(ctx context.Context, pin *PIN) error {
+	createPINQuery := `
+		INSERT INTO user_pins (
+			user_id, hashed_pin
+		) VALUES (
+			$1, $2
+		)
+		ON CONFLICT (user_id) DO NOTHING
+	`
+	res, err := d.db.DB().ExecContext(ctx, createPINQuery, pin.UserID, pin.HashedPIN)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrPINAlreadySet
+	}
+	return nil
+}
+
+// SavePIN implements Repository.
+// It sets the PIN whether or not one exists, clearing failed attempts and any lock.
+func (d *dbRepository) SavePIN(ctx context.Context, pin *PIN) error {
+	savePINQuery := `
+		INSERT INTO user_pins (
+			user_id, hashed_pin
+		) VALUES (
+			$1, $2
+		)
+		ON CONFLICT (user_id) DO UPDATE
+		SET hashed_pin = EXCLUDED.hashed_pin, failed_attempts = 0, locked_until = NULL, updated_at = current_timestamp
+	`
+	_, err := d.db.DB().ExecContext(ctx, savePINQuery, pin.UserID, pin.HashedPIN)
+	return err
+}
+
+// AttemptPIN implements Repository.
+// The attempt is counted as failed before the PIN is compared, so concurrent guesses cannot get
+// past the limit; the attempt that reaches maxAttempts locks the PIN for lockFor.
+// ClearPINAttempts undoes both once the PIN matches.
+func (d *dbRepository) AttemptPIN(ctx context.Context, userID uint64, maxAttempts int, lockFor time.Duration) (*PIN, error) {
+	attemptPINQuery := `
+		UPDATE user_pins
+		SET failed_attempts = failed_attempts + 1,
+			locked_until = CASE
+				WHEN failed_attempts + 1 >= $2 THEN current_timestamp + make_interval(secs => $3)
+				ELSE locked_until
+			END
+		WHERE user_id = $1 AND (locked_until IS NULL OR locked_until <= current_timestamp)
+		RETURNING user_id, hashed_pin, failed_attempts, locked_until
+	`
+	p := &PIN{}
+	err := d.db.DB().QueryRowContext(ctx, attemptPINQuery, userID, maxAttempts, lockFor.Seconds()).Scan(&p.UserID, &p.HashedPIN, &p.FailedAttempts, &p.LockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		err = d.db.DB().QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM user_pins WHERE user_id = $1)`, userID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, userbalance.ErrPINLocked
+		}
+		return nil, userbalance.ErrPINNotSet
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ClearPINAttempts implements Repository.
+func (d *dbRepository) ClearPINAttempts(ctx context.Context, userID uint64) error {
+	clearAttemptsQuery := `
+		UPDATE user_pins
+		SET failed_attempts = 0, locked_until = NULL
+		WHERE user_id = $1
+	`
+	_, err := d.db.DB().ExecContext(ctx, clearAttemptsQuery, userID)
+	return err
+}
@@ -0,0 +1,140 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/password"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+// VerifyPIN implements userbalance.PINVerifier.
+func (s *userService) VerifyPIN(ctx context.Context, userID, pin string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+	user, err := s.repository.GetByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if user.ClosedAt != nil {
+		return userbalance.ErrAccountClosed
+	}
+	if user.EmailVerifiedAt == nil {
+		return userbalance.ErrEmailNotVerified
+	}
+	return s.verifyPIN(ctx, uid, pin)
+}
+
+func (s *userService) verifyPIN(ctx context.Context, userID uint64, pin string) error {
+	stored, err := s.repository.AttemptPIN(ctx, userID, pinMaxAttempts, pinLockDuration)
+	if err != nil {
+		return err
+	}
+	match, err := password.Matches(pin, stored.HashedPIN)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return userbalance.ErrWrongPIN
+	}
+	return s.repository.ClearPINAttempts(ctx, userID)
+}
+
+// confirmIdentity checks the password and, when two-factor authentication is on, a second factor,
+// before sensitive changes to the account. Wrong ones count as failed logins of the account.
+func (s *userService) confirmIdentity(ctx context.Context, user *User, plaintext string, factor SecondFactor) error {
+	return s.confirmCredentials(ctx, user.Email, func() error {
+		return s.checkIdentity(ctx, user, plaintext, factor)
+	})
+}
+
+// confirmPassword checks the password of a signed-in user; wrong ones count as failed logins of the account.
+func (s *userService) confirmPassword(ctx context.Context, user *User, plaintext string) error {
+	return s.confirmCredentials(ctx, user.Email, func() error {
+		return checkPassword(user, plaintext)
+	})
+}
+
+func (s *userService) checkIdentity(ctx context.Context, user *User, plaintext string, factor SecondFactor) error {
+	err := checkPassword(user, plaintext)
+	if err != nil {
+		return err
+	}
+
+	enabled, err := s.totpEnabled(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+	if factor.Code == "" && factor.RecoveryCode == "" {
+		return ErrMFACodeRequired
+	}
+	return s.checkSecondFactor(ctx, user.ID, factor)
+}
+
+func checkPassword(user *User, plaintext string) error {
+	match, err := password.Matches(plaintext, user.HashedPassword)
+	if err != nil {
+		return err
+	}
+	if !match {
+		return ErrWrongPassword
+	}
+	return nil
+}
+
+func (s *userService) SetPIN(ctx context.Context, req SetPINPayload) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	hashedPIN, err := password.Hash(req.PIN)
+	if err != nil {
+		return err
+	}
+	return s.repository.CreatePIN(ctx, &PIN{UserID: req.UserID, HashedPIN: hashedPIN})
+}
+
+func (s *userService) ChangePIN(ctx context.Context, req ChangePINPayload) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	err = s.verifyPIN(ctx, req.UserID, req.CurrentPIN)
+	if err != nil {
+		return err
+	}
+	hashedPIN, err := password.Hash(req.NewPIN)
+	if err != nil {
+		return err
+	}
+	return s.repository.SavePIN(ctx, &PIN{UserID: req.UserID, HashedPIN: hashedPIN})
+}
+
+// ResetPIN replaces a forgotten or locked PIN after the user proves who they are with the
+// password and, when two-factor authentication is enabled, a code.
+func (s *userService) ResetPIN(ctx context.Context, req ResetPINPayload) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	user, err := s.repository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return err
+	}
+	err = s.confirmIdentity(ctx, user, req.Password, req.SecondFactor)
+	if err != nil {
+		return err
+	}
+
+	hashedPIN, err := password.Hash(req.PIN)
+	if err != nil {
+		return err
+	}
+	return s.repository.SavePIN(ctx, &PIN{UserID: req.UserID, HashedPIN: hashedPIN})
+}
@@ -0,0 +1,112 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/password"
+	"github.com/citadel-corp/paimon-bank/internal/currency"
+)
+
+func (s *userService) GetProfile(ctx context.Context, userID uint64) (*ProfileResponse, error) {
+	user, err := s.repository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.profile(ctx, user)
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, req UpdateProfilePayload) (*ProfileResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	user, err := s.repository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+	if req.Phone != nil {
+		user.Phone = emptyToNil(*req.Phone)
+	}
+	if req.PreferredCurrency != nil {
+		user.PreferredCurrency = emptyToNil(*req.PreferredCurrency)
+	}
+	if req.PreferredCurrency != nil && user.PreferredCurrency != nil {
+		err = s.currencies.ValidateCode(ctx, *user.PreferredCurrency)
+		if errors.Is(err, currency.ErrCurrencyNotSupported) {
+			return nil, fmt.Errorf("%w: preferredCurrency: %w", ErrValidationFailed, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if req.Timezone != nil {
+		user.Timezone = *req.Timezone
+	}
+
+	err = s.repository.UpdateProfile(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return s.profile(ctx, user)
+}
+
+func (s *userService) ChangePassword(ctx context.Context, req ChangePasswordPayload) (*TokenResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	user, err := s.repository.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.confirmPassword(ctx, user, req.CurrentPassword)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := password.Hash(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	err = s.repository.UpdatePassword(ctx, user.ID, hashedPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	// every session ends, and the one changing the password continues with the new tokens;
+	// the refresh tokens went with the password update
+	err = s.revoker.RevokeUser(ctx, fmt.Sprint(user.ID))
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, user.ID)
+}
+
+func (s *userService) profile(ctx context.Context, user *User) (*ProfileResponse, error) {
+	mfaEnabled, err := s.totpEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &ProfileResponse{
+		ID:                user.ID,
+		Email:             user.Email,
+		EmailVerified:     user.EmailVerifiedAt != nil,
+		Name:              user.Name,
+		Phone:             user.Phone,
+		PreferredCurrency: user.PreferredCurrency,
+		Timezone:          user.Timezone,
+		MFAEnabled:        mfaEnabled,
+	}, nil
+}
+
+func emptyToNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
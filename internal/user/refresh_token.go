@@ -0,0 +1,39 @@
+package user
+
+import (
+	"crypto/sha256"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+	// refreshTokenLength gives about 256 bits of randomness from the nanoid alphabet.
+	refreshTokenLength = 43
+)
+
+// RefreshToken is stored by hash only. Each use replaces it with a new token in the same family,
+// so a token presented twice means it was copied and the whole family is revoked.
+type RefreshToken struct {
+	ID        uint64
+	UserID    uint64
+	FamilyID  string
+	TokenHash []byte
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+// newRefreshToken returns an opaque token for the client and the hash to store.
+func newRefreshToken() (string, []byte) {
+	token := id.GenerateStringID(refreshTokenLength)
+	return token, hashRefreshToken(token)
+}
+
+func hashRefreshToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/citadel-corp/paimon-bank/internal/common/db"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
@@ -13,6 +15,39 @@ type Repository interface {
 	Create(ctx context.Context, user *User) error
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	GetByID(ctx context.Context, id uint64) (*User, error)
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) error
+	RotateRefreshToken(ctx context.Context, oldHash []byte, next *RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, userID string, tokenHash []byte) error
+	RevokeSession(ctx context.Context, userID, familyID string) error
+	RevokeRefreshTokens(ctx context.Context, userID string) error
+	GetTOTP(ctx context.Context, userID uint64) (*TOTP, error)
+	SaveTOTP(ctx context.Context, totp *TOTP) error
+	EnableTOTP(ctx context.Context, userID uint64, step int64, recoveryCodeHashes [][]byte) error
+	DeleteTOTP(ctx context.Context, userID uint64) error
+	UseTOTPStep(ctx context.Context, userID uint64, step int64) error
+	UseRecoveryCode(ctx context.Context, userID uint64, codeHash []byte) error
+	CreateMFAChallenge(ctx context.Context, challenge *MFAChallenge) error
+	GetMFAChallenge(ctx context.Context, tokenHash []byte) (*MFAChallenge, error)
+	FailMFAChallenge(ctx context.Context, id uint64) error
+	CompleteMFAChallenge(ctx context.Context, id uint64) error
+	CreatePIN(ctx context.Context, pin *PIN) error
+	SavePIN(ctx context.Context, pin *PIN) error
+	AttemptPIN(ctx context.Context, userID uint64, maxAttempts int, lockFor time.Duration) (*PIN, error)
+	ClearPINAttempts(ctx context.Context, userID uint64) error
+	AttemptLogin(ctx context.Context, key LoginKey, policy loginPolicy) (*LoginAttempt, error)
+	ReleaseLoginAttempt(ctx context.Context, key LoginKey) error
+	ClearLoginFailures(ctx context.Context, key LoginKey) error
+	CreatePasswordReset(ctx context.Context, reset *PasswordReset, interval time.Duration) error
+	ResetPassword(ctx context.Context, tokenHash []byte, hashedPassword string) (*User, error)
+	CreateEmailVerification(ctx context.Context, verification *EmailVerification, interval time.Duration, maxPerHour int) error
+	VerifyEmail(ctx context.Context, tokenHash []byte) (*User, error)
+	UpdateProfile(ctx context.Context, user *User) error
+	UpdatePassword(ctx context.Context, userID uint64, hashedPassword string) error
+	CloseAccount(ctx context.Context, userID uint64, payout *userbalance.ClosingPayout) (time.Time, []userbalance.OutgoingTransfer, error)
+	ListRoles(ctx context.Context, userID uint64) ([]string, error)
+	// SetRoles replaces the roles of a user, refusing to leave nobody with the admin role.
+	SetRoles(ctx context.Context, userID uint64, roles []string, grantedBy uint64) error
+	GrantFirstAdmin(ctx context.Context, userID uint64) (bool, error)
 }
 
 type dbRepository struct {
@@ -55,12 +90,12 @@ func (d *dbRepository) Create(ctx context.Context, user *User) error {
 // GetByEmail implements Repository.
 func (d *dbRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
 	getUserQuery := `
-		SELECT id, email, name, hashed_password FROM users
+		SELECT id, email, name, hashed_password, email_verified_at, phone, preferred_currency, timezone, closed_at FROM users
 		WHERE email = $1;
 	`
 	row := d.db.DB().QueryRowContext(ctx, getUserQuery, email)
 	u := &User{}
-	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.HashedPassword)
+	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.HashedPassword, &u.EmailVerifiedAt, &u.Phone, &u.PreferredCurrency, &u.Timezone, &u.ClosedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrUserNotFound
 	}
@@ -72,12 +107,12 @@ func (d *dbRepository) GetByEmail(ctx context.Context, email string) (*User, err
 
 func (d *dbRepository) GetByID(ctx context.Context, id uint64) (*User, error) {
 	getUserQuery := `
-		SELECT id, email, name, product_sold_total, hashed_password FROM users
+		SELECT id, email, name, hashed_password, email_verified_at, phone, preferred_currency, timezone, closed_at FROM users
 		WHERE id = $1;
 	`
 	row := d.db.DB().QueryRowContext(ctx, getUserQuery, id)
 	u := &User{}
-	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.HashedPassword)
+	err := row.Scan(&u.ID, &u.Email, &u.Name, &u.HashedPassword, &u.EmailVerifiedAt, &u.Phone, &u.PreferredCurrency, &u.Timezone, &u.ClosedAt)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, ErrUserNotFound
 	}
@@ -86,3 +121,177 @@ func (d *dbRepository) GetByID(ctx context.Context, id uint64) (*User, error) {
 	}
 	return u, nil
 }
+
+// CreateRefreshToken implements Repository.
+func (d *dbRepository) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	createTokenQuery := `
+		INSERT INTO refresh_tokens (
+			user_id, family_id, token_hash, expires_at
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		RETURNING id, created_at;
+	`
+	row := d.db.DB().QueryRowContext(ctx, createTokenQuery, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt)
+	return row.Scan(&token.ID, &token.CreatedAt)
+}
+
+// RotateRefreshToken implements Repository.
+// The token matching oldHash is marked used and next joins its family. Presenting a used or revoked
+// token revokes every token in the family and returns ErrTokenReused, with next.UserID and
+// next.FamilyID set to the family's; the revocation is committed.
+func (d *dbRepository) RotateRefreshToken(ctx context.Context, oldHash []byte, next *RefreshToken) error {
+	var reused bool
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		selectTokenQuery := `
+			SELECT id, user_id, family_id, expires_at, used_at, revoked_at
+			FROM refresh_tokens
+			WHERE token_hash = $1
+			FOR UPDATE
+		`
+		old := &RefreshToken{}
+		err := tx.QueryRowContext(ctx, selectTokenQuery, oldHash).Scan(&old.ID, &old.UserID, &old.FamilyID, &old.ExpiresAt, &old.UsedAt, &old.RevokedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		if err != nil {
+			return err
+		}
+
+		next.UserID = old.UserID
+		next.FamilyID = old.FamilyID
+		if old.UsedAt != nil || old.RevokedAt != nil {
+			reused = true
+			revokeFamilyQuery := `
+				UPDATE refresh_tokens
+				SET revoked_at = current_timestamp
+				WHERE family_id = $1 AND revoked_at IS NULL
+			`
+			_, err = tx.ExecContext(ctx, revokeFamilyQuery, old.FamilyID)
+			return err
+		}
+		if !old.ExpiresAt.After(time.Now()) {
+			return ErrInvalidToken
+		}
+
+		useTokenQuery := `
+			UPDATE refresh_tokens
+			SET used_at = current_timestamp
+			WHERE id = $1
+		`
+		_, err = tx.ExecContext(ctx, useTokenQuery, old.ID)
+		if err != nil {
+			return err
+		}
+
+		createTokenQuery := `
+			INSERT INTO refresh_tokens (
+				user_id, family_id, token_hash, expires_at
+			) VALUES (
+				$1, $2, $3, $4
+			)
+			RETURNING id, created_at;
+		`
+		row := tx.QueryRowContext(ctx, createTokenQuery, next.UserID, next.FamilyID, next.TokenHash, next.ExpiresAt)
+		return row.Scan(&next.ID, &next.CreatedAt)
+	})
+	if err != nil {
+		return err
+	}
+	if reused {
+		return ErrTokenReused
+	}
+	return nil
+}
+
+// RevokeRefreshTokenFamily implements Repository.
+// It revokes the family of the user's token matching tokenHash; unknown tokens are ignored.
+func (d *dbRepository) RevokeRefreshTokenFamily(ctx context.Context, userID string, tokenHash []byte) error {
+	revokeFamilyQuery := `
+		UPDATE refresh_tokens
+		SET revoked_at = current_timestamp
+		WHERE revoked_at IS NULL AND family_id = (
+			SELECT family_id FROM refresh_tokens
+			WHERE token_hash = $1 AND user_id = $2
+		)
+	`
+	_, err := d.db.DB().ExecContext(ctx, revokeFamilyQuery, tokenHash, userID)
+	return err
+}
+
+// RevokeSession implements Repository.
+// It revokes the user's refresh token family that access tokens of the session name in their sid claim.
+func (d *dbRepository) RevokeSession(ctx context.Context, userID, familyID string) error {
+	revokeFamilyQuery := `
+		UPDATE refresh_tokens
+		SET revoked_at = current_timestamp
+		WHERE user_id = $1 AND family_id = $2 AND revoked_at IS NULL
+	`
+	_, err := d.db.DB().ExecContext(ctx, revokeFamilyQuery, userID, familyID)
+	return err
+}
+
+// RevokeRefreshTokens implements Repository.
+func (d *dbRepository) RevokeRefreshTokens(ctx context.Context, userID string) error {
+	revokeTokensQuery := `
+		UPDATE refresh_tokens
+		SET revoked_at = current_timestamp
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+	_, err := d.db.DB().ExecContext(ctx, revokeTokensQuery, userID)
+	return err
+}
+
+// UpdateProfile implements Repository.
+func (d *dbRepository) UpdateProfile(ctx context.Context, user *User) error {
+	updateProfileQuery := `
+		UPDATE users
+		SET name = $2, phone = $3, preferred_currency = $4, timezone = $5
+		WHERE id = $1
+	`
+	res, err := d.db.DB().ExecContext(ctx, updateProfileQuery, user.ID, user.Name, user.Phone, user.PreferredCurrency, user.Timezone)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdatePassword implements Repository.
+// Outstanding password reset tokens are invalidated, as they were meant for the old password, and the
+// user's refresh tokens are revoked with the change, so no session started with the old password survives it.
+func (d *dbRepository) UpdatePassword(ctx context.Context, userID uint64, hashedPassword string) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		updatePasswordQuery := `
+			UPDATE users
+			SET hashed_password = $2
+			WHERE id = $1
+		`
+		_, err := tx.ExecContext(ctx, updatePasswordQuery, userID, hashedPassword)
+		if err != nil {
+			return err
+		}
+		invalidateResetsQuery := `
+			UPDATE password_resets
+			SET used_at = current_timestamp
+			WHERE user_id = $1 AND used_at IS NULL
+		`
+		_, err = tx.ExecContext(ctx, invalidateResetsQuery, userID)
+		if err != nil {
+			return err
+		}
+		revokeTokensQuery := `
+			UPDATE refresh_tokens
+			SET revoked_at = current_timestamp
+			WHERE user_id = $1 AND revoked_at IS NULL
+		`
+		_, err = tx.ExecContext(ctx, revokeTokensQuery, userID)
+		return err
+	})
+}
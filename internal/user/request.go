@@ -1,6 +1,16 @@
 package user
 
 import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/jwt"
+	"github.com/citadel-corp/paimon-bank/internal/common/rbac"
+	"github.com/citadel-corp/paimon-bank/internal/common/totp"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 )
@@ -22,6 +32,7 @@ func (p CreateUserPayload) Validate() error {
 type LoginPayload struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	IP       string `json:"-"`
 }
 
 func (p LoginPayload) Validate() error {
@@ -30,3 +41,259 @@ func (p LoginPayload) Validate() error {
 		validation.Field(&p.Password, validation.Required, validation.Length(5, 15)),
 	)
 }
+
+type ForgotPasswordPayload struct {
+	Email string `json:"email"`
+}
+
+func (p ForgotPasswordPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Email, validation.Required, is.EmailFormat),
+	)
+}
+
+type ResetPasswordPayload struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (p ResetPasswordPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Token, validation.Required, validation.Length(passwordResetTokenLength, passwordResetTokenLength)),
+		validation.Field(&p.Password, validation.Required, validation.Length(5, 15)),
+	)
+}
+
+type VerifyEmailPayload struct {
+	Token string `json:"token"`
+}
+
+func (p VerifyEmailPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Token, validation.Required, validation.Length(emailVerificationTokenLength, emailVerificationTokenLength)),
+	)
+}
+
+// ChangeEmailPayload asks for the password, so a session left open is not enough to take the account over.
+type ChangeEmailPayload struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	UserID   uint64 `json:"-"`
+}
+
+func (p ChangeEmailPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Email, validation.Required, is.EmailFormat),
+		validation.Field(&p.Password, validation.Required, validation.Length(5, 15)),
+	)
+}
+
+// UpdateProfilePayload changes only the fields present. An empty phone or preferredCurrency clears it.
+type UpdateProfilePayload struct {
+	Name              *string `json:"name"`
+	Phone             *string `json:"phone"`
+	PreferredCurrency *string `json:"preferredCurrency"`
+	Timezone          *string `json:"timezone"`
+	UserID            uint64  `json:"-"`
+}
+
+var phonePattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+func (p UpdateProfilePayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Name, validation.NilOrNotEmpty, validation.Length(5, 50)),
+		validation.Field(&p.Phone, validation.Match(phonePattern).Error("must be in international format, such as +6281234567890")),
+		validation.Field(&p.PreferredCurrency, validation.Length(3, 3), is.UpperCase),
+		validation.Field(&p.Timezone, validation.NilOrNotEmpty, validation.By(validTimezone)),
+	)
+}
+
+func validTimezone(value interface{}) error {
+	tz, _ := value.(*string)
+	if tz == nil {
+		return nil
+	}
+	_, err := time.LoadLocation(*tz)
+	if err != nil || *tz == "Local" {
+		return errors.New("must be an IANA time zone, such as Asia/Jakarta")
+	}
+	return nil
+}
+
+// ChangePasswordPayload ends every other session once the password changes.
+type ChangePasswordPayload struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+	UserID          uint64 `json:"-"`
+}
+
+func (p ChangePasswordPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CurrentPassword, validation.Required, validation.Length(5, 15)),
+		validation.Field(&p.NewPassword, validation.Required, validation.Length(5, 15)),
+	)
+}
+
+// CloseAccountPayload names the bank account remaining balances are paid out to.
+// Without one, every balance must already be zero.
+type CloseAccountPayload struct {
+	SecondFactor
+	Password                string `json:"password"`
+	PayoutBankAccountNumber string `json:"payoutBankAccountNumber"`
+	PayoutBankName          string `json:"payoutBankName"`
+	UserID                  uint64 `json:"-"`
+}
+
+func (p CloseAccountPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Password, validation.Required, validation.Length(5, 15)),
+		validation.Field(&p.Code, validation.When(p.RecoveryCode != "", validation.Empty), validation.Length(totp.Digits, totp.Digits), is.Digit),
+		validation.Field(&p.RecoveryCode, validation.Length(recoveryCodeLength, recoveryCodeLength+1)),
+		validation.Field(&p.PayoutBankAccountNumber, validation.When(p.PayoutBankName != "", validation.Required), validation.Length(5, 30)),
+		validation.Field(&p.PayoutBankName, validation.When(p.PayoutBankAccountNumber != "", validation.Required), validation.Length(5, 30)),
+	)
+}
+
+type RefreshTokenPayload struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (p RefreshTokenPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.RefreshToken, validation.Required, validation.Length(refreshTokenLength, refreshTokenLength)),
+	)
+}
+
+// LogoutPayload ends the session of the access token in Claims and, when given, of RefreshToken.
+// With Everywhere, every session of the user ends.
+type LogoutPayload struct {
+	RefreshToken string `json:"refreshToken"`
+	Everywhere   bool   `json:"everywhere"`
+	Claims       *jwt.Claims
+}
+
+func (p LogoutPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.RefreshToken, validation.Length(refreshTokenLength, refreshTokenLength)),
+	)
+}
+
+type ConfirmTOTPPayload struct {
+	Code   string `json:"code"`
+	UserID uint64 `json:"-"`
+}
+
+func (p ConfirmTOTPPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Code, validation.Required, validation.Length(totp.Digits, totp.Digits), is.Digit),
+	)
+}
+
+// SecondFactor is either a code from the authenticator app or one of the recovery codes.
+type SecondFactor struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+func (p SecondFactor) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Code, validation.When(p.RecoveryCode == "", validation.Required).Else(validation.Empty), validation.Length(totp.Digits, totp.Digits), is.Digit),
+		validation.Field(&p.RecoveryCode, validation.Length(recoveryCodeLength, recoveryCodeLength+1)),
+	)
+}
+
+type DisableTOTPPayload struct {
+	SecondFactor
+	Password string `json:"password"`
+	UserID   uint64 `json:"-"`
+}
+
+func (p DisableTOTPPayload) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.Password, validation.Required, validation.Length(5, 15)),
+	)
+	if err != nil {
+		return err
+	}
+	return p.SecondFactor.Validate()
+}
+
+// MFALoginPayload completes a login with the challenge token returned by Login.
+type MFALoginPayload struct {
+	SecondFactor
+	MFAToken string `json:"mfaToken"`
+	IP       string `json:"-"`
+}
+
+func (p MFALoginPayload) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.MFAToken, validation.Required, validation.Length(mfaChallengeTokenLength, mfaChallengeTokenLength)),
+	)
+	if err != nil {
+		return err
+	}
+	return p.SecondFactor.Validate()
+}
+
+type SetPINPayload struct {
+	PIN    string `json:"pin"`
+	UserID uint64 `json:"-"`
+}
+
+func (p SetPINPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.PIN, pinRules...),
+	)
+}
+
+type ChangePINPayload struct {
+	CurrentPIN string `json:"currentPin"`
+	NewPIN     string `json:"newPin"`
+	UserID     uint64 `json:"-"`
+}
+
+func (p ChangePINPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.CurrentPIN, userbalance.PINRules...),
+		validation.Field(&p.NewPIN, append(append([]validation.Rule{}, pinRules...), validation.NotIn(p.CurrentPIN).Error("must differ from the current PIN"))...),
+	)
+}
+
+// ResetPINPayload sets a new PIN without the current one. Code or RecoveryCode is required
+// when the user has two-factor authentication enabled.
+type ResetPINPayload struct {
+	SecondFactor
+	Password string `json:"password"`
+	PIN      string `json:"pin"`
+	UserID   uint64 `json:"-"`
+}
+
+func (p ResetPINPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Password, validation.Required, validation.Length(5, 15)),
+		validation.Field(&p.PIN, pinRules...),
+		validation.Field(&p.Code, validation.When(p.RecoveryCode != "", validation.Empty), validation.Length(totp.Digits, totp.Digits), is.Digit),
+		validation.Field(&p.RecoveryCode, validation.Length(recoveryCodeLength, recoveryCodeLength+1)),
+	)
+}
+
+// SetRolesPayload replaces the roles of UserID. GrantedBy is the admin making the change.
+type SetRolesPayload struct {
+	Roles     []string `json:"roles"`
+	UserID    uint64   `json:"-"`
+	GrantedBy uint64   `json:"-"`
+}
+
+func (p SetRolesPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Roles, validation.NotNil, validation.Each(validation.By(validRole))),
+	)
+}
+
+func validRole(value interface{}) error {
+	role, _ := value.(string)
+	if !rbac.ValidRole(role) {
+		return fmt.Errorf("must be one of %s", strings.Join(rbac.Roles(), ", "))
+	}
+	return nil
+}
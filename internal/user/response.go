@@ -1,7 +1,76 @@
 package user
 
+import userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+
+// UserResponse carries the tokens of a new session. New users start with EmailVerified false
+// and cannot move money until they verify. For users with two-factor authentication,
+// Login returns MFARequired and an MFAToken instead, to be completed at the MFA login endpoint.
 type UserResponse struct {
-	Email       string `json:"email"`
-	Name        string `json:"name"`
-	AccessToken string `json:"accessToken"`
+	Email         string `json:"email"`
+	Name          string `json:"name"`
+	EmailVerified bool   `json:"emailVerified"`
+	AccessToken   string `json:"accessToken,omitempty"`
+	RefreshToken  string `json:"refreshToken,omitempty"`
+	ExpiresIn     int    `json:"expiresIn,omitempty"`
+	MFARequired   bool   `json:"mfaRequired"`
+	MFAToken      string `json:"mfaToken,omitempty"`
+}
+
+type ProfileResponse struct {
+	ID                uint64  `json:"id"`
+	Email             string  `json:"email"`
+	EmailVerified     bool    `json:"emailVerified"`
+	Name              string  `json:"name"`
+	Phone             *string `json:"phone"`
+	PreferredCurrency *string `json:"preferredCurrency"`
+	Timezone          string  `json:"timezone"`
+	MFAEnabled        bool    `json:"mfaEnabled"`
+}
+
+type ClosureResponse struct {
+	ClosedAt int64                   `json:"closedAt"`
+	Payouts  []ClosurePayoutResponse `json:"payouts"`
+}
+
+type ClosurePayoutResponse struct {
+	TransactionID string                     `json:"transactionId"`
+	Amount        int                        `json:"amount"`
+	Currency      string                     `json:"currency"`
+	Status        userbalance.TransferStatus `json:"status"`
+}
+
+// DataExport is a zip archive of everything the bank holds about a user.
+type DataExport struct {
+	Filename string
+	Content  []byte
+}
+
+type EmailResponse struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailVerified"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
+
+// TOTPEnrollmentResponse is shown once when setting up an authenticator app. QRCode is a PNG
+// data URL of URI; Secret is for typing in by hand.
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauthUri"`
+	QRCode string `json:"qrCode"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// RolesResponse lists the roles of a user and the permissions they add up to.
+type RolesResponse struct {
+	UserID      uint64   `json:"userId"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
 }
@@ -0,0 +1,64 @@
+package user
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/request"
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, ErrUserNotFound)
+		return
+	}
+	roles, err := h.service.GetRoles(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "success",
+		Data:    roles,
+	})
+}
+
+func (h *Handler) SetRoles(w http.ResponseWriter, r *http.Request) {
+	adminID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		writeError(w, ErrUserNotFound)
+		return
+	}
+
+	var req SetRolesPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.UserID = userID
+	req.GrantedBy = adminID
+
+	roles, err := h.service.SetRoles(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, response.ResponseBody{
+		Message: "Roles updated successfully",
+		Data:    roles,
+	})
+}
@@ -0,0 +1,123 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/rbac"
+)
+
+// ListRoles implements Repository.
+func (d *dbRepository) ListRoles(ctx context.Context, userID uint64) ([]string, error) {
+	roles := []string{}
+
+	selectQuery := `
+		SELECT role
+		FROM user_roles
+		WHERE user_id = $1
+		ORDER BY role
+	`
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role string
+		err = rows.Scan(&role)
+		if err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// SetRoles implements Repository.
+// It replaces the user's roles, keeping when roles the user already held were granted.
+// The admin rows are locked first so concurrent changes cannot together remove every admin.
+func (d *dbRepository) SetRoles(ctx context.Context, userID uint64, roles []string, grantedBy uint64) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		lockAdminsQuery := `
+			SELECT user_id
+			FROM user_roles
+			WHERE role = $1
+			FOR UPDATE
+		`
+		rows, err := tx.QueryContext(ctx, lockAdminsQuery, rbac.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		var exists bool
+		userExistsQuery := `
+			SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)
+		`
+		err = tx.QueryRowContext(ctx, userExistsQuery, userID).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrUserNotFound
+		}
+
+		deleteQuery := `
+			DELETE FROM user_roles
+			WHERE user_id = $1 AND role <> ALL($2)
+		`
+		_, err = tx.ExecContext(ctx, deleteQuery, userID, roles)
+		if err != nil {
+			return err
+		}
+
+		insertQuery := `
+			INSERT INTO user_roles (user_id, role, granted_by)
+			SELECT $1, role, $3
+			FROM unnest($2::VARCHAR[]) AS role
+			ON CONFLICT DO NOTHING
+		`
+		_, err = tx.ExecContext(ctx, insertQuery, userID, roles, grantedBy)
+		if err != nil {
+			return err
+		}
+
+		adminLeftQuery := `
+			SELECT EXISTS (SELECT 1 FROM user_roles WHERE role = $1)
+		`
+		err = tx.QueryRowContext(ctx, adminLeftQuery, rbac.RoleAdmin).Scan(&exists)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrLastAdmin
+		}
+		return nil
+	})
+}
+
+// GrantFirstAdmin implements Repository.
+// It reports whether the role was granted, which only happens while nobody is an admin.
+func (d *dbRepository) GrantFirstAdmin(ctx context.Context, userID uint64) (bool, error) {
+	grantQuery := `
+		INSERT INTO user_roles (user_id, role)
+		SELECT $1, $2
+		WHERE NOT EXISTS (SELECT 1 FROM user_roles WHERE role = $2)
+		ON CONFLICT DO NOTHING
+	`
+	result, err := d.db.DB().ExecContext(ctx, grantQuery, userID, rbac.RoleAdmin)
+	if err != nil {
+		return false, err
+	}
+	granted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return granted > 0, nil
+}
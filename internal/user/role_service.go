@@ -0,0 +1,68 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/jwt"
+	"github.com/citadel-corp/paimon-bank/internal/common/rbac"
+)
+
+func (s *userService) GetRoles(ctx context.Context, userID uint64) (*RolesResponse, error) {
+	_, err := s.repository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.repository.ListRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &RolesResponse{
+		UserID:      userID,
+		Roles:       roles,
+		Permissions: rbac.Permissions(roles),
+	}, nil
+}
+
+func (s *userService) SetRoles(ctx context.Context, req SetRolesPayload) (*RolesResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	err = s.repository.SetRoles(ctx, req.UserID, req.Roles, req.GrantedBy)
+	if err != nil {
+		return nil, err
+	}
+	// access tokens carry the roles they were issued with, revoking them makes the user refresh into the new ones
+	err = s.revoker.RevokeUser(ctx, fmt.Sprint(req.UserID))
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRoles(ctx, req.UserID)
+}
+
+func (s *userService) GrantFirstAdmin(ctx context.Context, email string) (bool, error) {
+	user, err := s.repository.GetByEmail(ctx, email)
+	if err != nil {
+		return false, err
+	}
+	granted, err := s.repository.GrantFirstAdmin(ctx, user.ID)
+	if err != nil || !granted {
+		return false, err
+	}
+	return true, s.revoker.RevokeUser(ctx, fmt.Sprint(user.ID))
+}
+
+// signAccessToken signs an access token for the session, the user's refresh token family,
+// carrying the user's current token generation, roles and their permissions.
+func (s *userService) signAccessToken(ctx context.Context, userID uint64, sessionID string) (string, error) {
+	generation, err := s.revoker.Generation(ctx, fmt.Sprint(userID))
+	if err != nil {
+		return "", err
+	}
+	roles, err := s.repository.ListRoles(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return jwt.Sign(accessTokenTTL, fmt.Sprint(userID), sessionID, generation, roles, rbac.Permissions(roles))
+}
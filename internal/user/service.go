@@ -2,24 +2,85 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
 	"github.com/citadel-corp/paimon-bank/internal/common/jwt"
 	"github.com/citadel-corp/paimon-bank/internal/common/password"
+	"github.com/citadel-corp/paimon-bank/internal/common/secretbox"
+	"github.com/citadel-corp/paimon-bank/internal/currency"
+	"github.com/citadel-corp/paimon-bank/internal/mailer"
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
 )
 
 type Service interface {
 	Create(ctx context.Context, req CreateUserPayload) (*UserResponse, error)
 	Login(ctx context.Context, req LoginPayload) (*UserResponse, error)
+	// Refresh exchanges a refresh token for a new access token and a new refresh token.
+	Refresh(ctx context.Context, req RefreshTokenPayload) (*TokenResponse, error)
+	// Logout revokes the current access token and refresh token, or every session of the user.
+	Logout(ctx context.Context, req LogoutPayload) error
+	// LoginMFA completes a login of a user with two-factor authentication.
+	LoginMFA(ctx context.Context, req MFALoginPayload) (*UserResponse, error)
+	EnrollTOTP(ctx context.Context, userID uint64) (*TOTPEnrollmentResponse, error)
+	// ConfirmTOTP enables a pending enrollment once the user enters a code from it, returning new recovery codes.
+	ConfirmTOTP(ctx context.Context, req ConfirmTOTPPayload) (*RecoveryCodesResponse, error)
+	DisableTOTP(ctx context.Context, req DisableTOTPPayload) error
+	// StepUpFactors and VerifyStepUp let other domains demand fresh proof of identity.
+	StepUpFactors(ctx context.Context, userID string) ([]string, error)
+	VerifyStepUp(ctx context.Context, userID string, factor userbalance.StepUpFactor) error
+	// VerifyPIN checks the transaction PIN for other domains, counting wrong attempts towards the lock.
+	VerifyPIN(ctx context.Context, userID, pin string) error
+	SetPIN(ctx context.Context, req SetPINPayload) error
+	ChangePIN(ctx context.Context, req ChangePINPayload) error
+	ResetPIN(ctx context.Context, req ResetPINPayload) error
+	// ForgotPassword emails a reset token to a registered user. It reports success for unknown emails too.
+	ForgotPassword(ctx context.Context, req ForgotPasswordPayload) error
+	// ResetPassword sets a new password with an emailed reset token and ends every session of the user.
+	ResetPassword(ctx context.Context, req ResetPasswordPayload) error
+	// VerifyEmail confirms the address a verification token was sent to, completing an email change if it was one.
+	VerifyEmail(ctx context.Context, req VerifyEmailPayload) (*EmailResponse, error)
+	ResendEmailVerification(ctx context.Context, userID uint64) error
+	// ChangeEmail sends a verification token to the new address; the email changes once it is verified.
+	ChangeEmail(ctx context.Context, req ChangeEmailPayload) error
+	GetProfile(ctx context.Context, userID uint64) (*ProfileResponse, error)
+	UpdateProfile(ctx context.Context, req UpdateProfilePayload) (*ProfileResponse, error)
+	// ChangePassword ends every session of the user and returns new tokens for the current one.
+	ChangePassword(ctx context.Context, req ChangePasswordPayload) (*TokenResponse, error)
+	// CloseAccount pays out or checks the balances, closes the account and ends every session.
+	CloseAccount(ctx context.Context, req CloseAccountPayload) (*ClosureResponse, error)
+	// ExportData bundles the user's profile, balances, transactions and image references.
+	ExportData(ctx context.Context, userID uint64) (*DataExport, error)
+	GetRoles(ctx context.Context, userID uint64) (*RolesResponse, error)
+	// SetRoles replaces the roles of a user and revokes their access tokens so the change applies at once.
+	SetRoles(ctx context.Context, req SetRolesPayload) (*RolesResponse, error)
+	// GrantFirstAdmin makes the user with email an admin if nobody is one yet, reporting whether it did.
+	GrantFirstAdmin(ctx context.Context, email string) (bool, error)
+}
+
+// TokenRevoker revokes access tokens before they expire.
+type TokenRevoker interface {
+	RevokeToken(ctx context.Context, claims *jwt.Claims) error
+	RevokeUser(ctx context.Context, userID string) error
+	// Generation returns the token generation new access tokens of the user are signed with.
+	Generation(ctx context.Context, userID string) (int64, error)
 }
 
 type userService struct {
 	repository Repository
+	revoker    TokenRevoker
+	secrets    *secretbox.Box
+	mailer     mailer.Mailer
+	currencies currency.Service
+	balances   userbalance.Repository
 }
 
-func NewService(repository Repository) Service {
-	return &userService{repository: repository}
+// NewService creates the user service. secrets encrypts the TOTP secrets of enrolled users,
+// and balances is read for personal data exports.
+func NewService(repository Repository, revoker TokenRevoker, secrets *secretbox.Box, mailer mailer.Mailer, currencies currency.Service, balances userbalance.Repository) Service {
+	return &userService{repository: repository, revoker: revoker, secrets: secrets, mailer: mailer, currencies: currencies, balances: balances}
 }
 
 func (s *userService) Create(ctx context.Context, req CreateUserPayload) (*UserResponse, error) {
@@ -40,15 +101,20 @@ func (s *userService) Create(ctx context.Context, req CreateUserPayload) (*UserR
 	if err != nil {
 		return nil, err
 	}
-	// create access token with signed jwt
-	accessToken, err := jwt.Sign(time.Minute*2, fmt.Sprint(user.ID))
+	err = s.startEmailVerification(ctx, user, user.Email)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.issueTokens(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
 	return &UserResponse{
-		Email:       req.Email,
-		Name:        req.Name,
-		AccessToken: accessToken,
+		Email:        req.Email,
+		Name:         req.Name,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
 	}, nil
 }
 
@@ -57,7 +123,16 @@ func (s *userService) Login(ctx context.Context, req LoginPayload) (*UserRespons
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
 	}
+	keys := loginKeys(req.Email, req.IP)
+	err = s.attemptLogin(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
 	user, err := s.repository.GetByEmail(ctx, req.Email)
+	if errors.Is(err, ErrUserNotFound) {
+		matchDummyPassword(req.Password)
+		return nil, loginFailed()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -66,16 +141,134 @@ func (s *userService) Login(ctx context.Context, req LoginPayload) (*UserRespons
 		return nil, err
 	}
 	if !match {
-		return nil, ErrWrongPassword
+		return nil, loginFailed()
+	}
+	if user.ClosedAt != nil {
+		err = s.releaseLoginAttempt(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		return nil, userbalance.ErrAccountClosed
+	}
+	challengeToken, err := s.startMFAChallenge(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	// failures are only cleared once the login is complete, after the second factor if there is one
+	if challengeToken != "" {
+		err = s.releaseLoginAttempt(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		return &UserResponse{
+			Email:         user.Email,
+			Name:          user.Name,
+			EmailVerified: user.EmailVerifiedAt != nil,
+			MFARequired:   true,
+			MFAToken:      challengeToken,
+		}, nil
 	}
-	// create access token with signed jwt
-	accessToken, err := jwt.Sign(time.Hour*8, fmt.Sprint(user.ID))
+	err = s.loginSucceeded(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.issueTokens(ctx, user.ID)
 	if err != nil {
 		return nil, err
 	}
 	return &UserResponse{
-		Email:       user.Email,
-		Name:        user.Name,
-		AccessToken: accessToken,
+		Email:         user.Email,
+		Name:          user.Name,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		AccessToken:   tokens.AccessToken,
+		RefreshToken:  tokens.RefreshToken,
+		ExpiresIn:     tokens.ExpiresIn,
+	}, nil
+}
+
+func (s *userService) Refresh(ctx context.Context, req RefreshTokenPayload) (*TokenResponse, error) {
+	err := req.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	refreshToken, hash := newRefreshToken()
+	next := &RefreshToken{
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}
+	err = s.repository.RotateRefreshToken(ctx, hashRefreshToken(req.RefreshToken), next)
+	if errors.Is(err, ErrTokenReused) {
+		// whoever holds the other copy may also hold access tokens of the family; access tokens
+		// are not tracked per family, so every access token of the user goes
+		revokeErr := s.revoker.RevokeUser(ctx, fmt.Sprint(next.UserID))
+		if revokeErr != nil {
+			return nil, revokeErr
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := s.signAccessToken(ctx, next.UserID, next.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *userService) Logout(ctx context.Context, req LogoutPayload) error {
+	err := req.Validate()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrValidationFailed, err)
+	}
+	if req.Everywhere {
+		err = s.revoker.RevokeUser(ctx, req.Claims.Subject)
+		if err != nil {
+			return err
+		}
+		return s.repository.RevokeRefreshTokens(ctx, req.Claims.Subject)
+	}
+
+	err = s.revoker.RevokeToken(ctx, req.Claims)
+	if err != nil {
+		return err
+	}
+	if req.Claims.SessionID != "" {
+		err = s.repository.RevokeSession(ctx, req.Claims.Subject, req.Claims.SessionID)
+		if err != nil {
+			return err
+		}
+	}
+	if req.RefreshToken == "" {
+		return nil
+	}
+	return s.repository.RevokeRefreshTokenFamily(ctx, req.Claims.Subject, hashRefreshToken(req.RefreshToken))
+}
+
+// issueTokens signs an access token and starts a new refresh token family for the user.
+func (s *userService) issueTokens(ctx context.Context, userID uint64) (*TokenResponse, error) {
+	familyID := id.GenerateStringID(16)
+	accessToken, err := s.signAccessToken(ctx, userID, familyID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, hash := newRefreshToken()
+	err = s.repository.CreateRefreshToken(ctx, &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
 	}, nil
 }
@@ -0,0 +1,55 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	userbalance "github.com/citadel-corp/paimon-bank/internal/user_balance"
+)
+
+// StepUpFactors implements userbalance.StepUpVerifier.
+// The password works for everyone; the authenticator app only once TOTP is enabled.
+func (s *userService) StepUpFactors(ctx context.Context, userID string) ([]string, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	factors := []string{userbalance.StepUpFactorPassword}
+	enabled, err := s.totpEnabled(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		factors = append(factors, userbalance.StepUpFactorOTP)
+	}
+	return factors, nil
+}
+
+// VerifyStepUp implements userbalance.StepUpVerifier.
+// Wrong factors count as failed logins of the account, so step-up locks together with the login.
+func (s *userService) VerifyStepUp(ctx context.Context, userID string, factor userbalance.StepUpFactor) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+	user, err := s.repository.GetByID(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	err = s.confirmCredentials(ctx, user.Email, func() error {
+		if factor.Password != "" {
+			return checkPassword(user, factor.Password)
+		}
+		return s.checkSecondFactor(ctx, uid, SecondFactor{Code: factor.Code, RecoveryCode: factor.RecoveryCode})
+	})
+	if errors.Is(err, ErrTooManyLoginAttempts) {
+		return fmt.Errorf("%w: %w", userbalance.ErrStepUpLocked, err)
+	}
+	if errors.Is(err, ErrWrongPassword) || errors.Is(err, ErrInvalidMFACode) || errors.Is(err, ErrMFANotEnrolled) {
+		return fmt.Errorf("%w: %w", userbalance.ErrStepUpFailed, err)
+	}
+	return err
+}
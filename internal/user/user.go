@@ -1,8 +1,18 @@
 package user
 
+import "time"
+
+// User is an account holder. EmailVerifiedAt is nil until the user confirms they own Email;
+// unverified accounts can log in but cannot move money. Phone and PreferredCurrency are optional.
+// Closed accounts are kept, with ClosedAt set, but can no longer log in.
 type User struct {
-	ID             uint64
-	Email          string
-	Name           string
-	HashedPassword string
+	ID                uint64
+	Email             string
+	Name              string
+	HashedPassword    string
+	EmailVerifiedAt   *time.Time
+	Phone             *string
+	PreferredCurrency *string
+	Timezone          string
+	ClosedAt          *time.Time
 }
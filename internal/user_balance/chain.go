@@ -0,0 +1,204 @@
+package userbalance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// chainKey keys the transaction hashes and chain heads, so rows cannot be rewritten
+// and rehashed by someone who can only write to the database.
+var chainKey []byte
+
+// UseChainKey sets the secret that transaction hashes and chain heads are computed with.
+func UseChainKey(secret string) error {
+	if secret == "" {
+		return ErrNoChainKey
+	}
+	chainKey = []byte(secret)
+	return nil
+}
+
+// chainContent is the part of a transaction row covered by its hash.
+// Field order is part of the hash format, do not reorder.
+type chainContent struct {
+	TransactionID     string `json:"id"`
+	UserID            string `json:"userId"`
+	Amount            int    `json:"amount"`
+	Currency          string `json:"currency"`
+	BankAccountNumber string `json:"bankAccountNumber"`
+	BankName          string `json:"bankName"`
+	ImageURL          string `json:"imageUrl"`
+	CreatedAt         int64  `json:"createdAt"`
+	ChainSeq          int64  `json:"chainSeq"`
+	PrevHash          string `json:"prevHash"`
+}
+
+func marshalChainContent(ut UserTransaction) []byte {
+	content := chainContent{
+		TransactionID:     strings.TrimSpace(ut.TransactionID),
+		UserID:            ut.UserID,
+		Amount:            ut.Amount,
+		Currency:          ut.Currency,
+		BankAccountNumber: ut.BankAccountNumber,
+		BankName:          ut.BankName,
+		CreatedAt:         ut.CreatedAt.UnixMicro(),
+	}
+	if ut.ImageURL != nil {
+		content.ImageURL = *ut.ImageURL
+	}
+	if ut.ChainSeq != nil {
+		content.ChainSeq = *ut.ChainSeq
+	}
+	if ut.PrevHash != nil {
+		content.PrevHash = strings.TrimSpace(*ut.PrevHash)
+	}
+	// marshalling a struct of plain strings and ints cannot fail
+	b, _ := json.Marshal(content)
+	return b
+}
+
+// hashTransaction computes the chained HMAC of ut. ChainSeq and PrevHash must be set.
+func hashTransaction(ut UserTransaction) string {
+	mac := hmac.New(sha256.New, chainKey)
+	mac.Write(marshalChainContent(ut))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// legacyHashTransaction is the unkeyed hash rows were chained with before the chain key.
+// It is only used to rekey those rows.
+func legacyHashTransaction(ut UserTransaction) string {
+	sum := sha256.Sum256(marshalChainContent(ut))
+	return hex.EncodeToString(sum[:])
+}
+
+// anchorChainHead authenticates the latest link of a user's chain, so deleting
+// transactions from the end of the chain is detected too.
+func anchorChainHead(userID string, seq int64, hash string) string {
+	mac := hmac.New(sha256.New, chainKey)
+	fmt.Fprintf(mac, "head:%s:%d:%s", userID, seq, strings.TrimSpace(hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyTransactionChain walks the chained transactions of a single user, ordered by
+// chain sequence, checks the last one against the user's chain head and reports the first
+// broken link. head is nil when the user has none. Rows written before the chain
+// was introduced carry no hash and must be filtered out by the caller.
+func VerifyTransactionChain(userID string, transactions []UserTransaction, head *ChainHead) ChainVerificationResponse {
+	result, lastHash := verifyLinks(userID, transactions, hashTransaction)
+	if result.Valid {
+		result.BrokenLink = verifyHead(userID, head, int64(result.Checked), lastHash)
+		result.Valid = result.BrokenLink == nil
+	}
+	return result
+}
+
+// verifyLinks checks every link of a chain with hash and returns the hash of the last one.
+func verifyLinks(userID string, transactions []UserTransaction, hash func(UserTransaction) string) (ChainVerificationResponse, string) {
+	result := ChainVerificationResponse{
+		UserID: userID,
+		Valid:  true,
+	}
+
+	var expectedSeq int64 = 1
+	prevHash := ""
+	for _, ut := range transactions {
+		var reason string
+		storedPrev := ""
+		if ut.PrevHash != nil {
+			storedPrev = strings.TrimSpace(*ut.PrevHash)
+		}
+		storedHash := ""
+		if ut.Hash != nil {
+			storedHash = strings.TrimSpace(*ut.Hash)
+		}
+
+		switch {
+		case ut.ChainSeq == nil || *ut.ChainSeq != expectedSeq:
+			reason = fmt.Sprintf("expected chain sequence %d, a transaction is missing", expectedSeq)
+		case storedPrev != prevHash:
+			reason = "previous hash does not match the preceding transaction"
+		case !hmac.Equal([]byte(storedHash), []byte(hash(ut))):
+			reason = "transaction content does not match its hash"
+		}
+
+		if reason != "" {
+			result.Valid = false
+			result.BrokenLink = &BrokenLinkResponse{
+				TransactionID: strings.TrimSpace(ut.TransactionID),
+				ChainSeq:      expectedSeq,
+				Reason:        reason,
+			}
+			return result, ""
+		}
+
+		result.Checked++
+		expectedSeq++
+		prevHash = storedHash
+	}
+
+	return result, prevHash
+}
+
+// verifyHead checks that head is authentic and points at the last link, lastSeq and lastHash.
+func verifyHead(userID string, head *ChainHead, lastSeq int64, lastHash string) *BrokenLinkResponse {
+	switch {
+	case head == nil && lastSeq == 0:
+		return nil
+	case head == nil:
+		return &BrokenLinkResponse{ChainSeq: lastSeq, Reason: "chain head is missing"}
+	case !hmac.Equal([]byte(strings.TrimSpace(head.Anchor)), []byte(anchorChainHead(userID, head.ChainSeq, head.Hash))):
+		return &BrokenLinkResponse{ChainSeq: head.ChainSeq, Reason: "chain head does not match its anchor"}
+	case head.ChainSeq > lastSeq:
+		return &BrokenLinkResponse{
+			ChainSeq: lastSeq + 1,
+			Reason:   fmt.Sprintf("chain head is at sequence %d, later transactions are missing", head.ChainSeq),
+		}
+	case head.ChainSeq < lastSeq || strings.TrimSpace(head.Hash) != lastHash:
+		return &BrokenLinkResponse{ChainSeq: head.ChainSeq, Reason: "latest transaction does not match the chain head"}
+	}
+	return nil
+}
+
+// rekeyChain recomputes the links of a chain hashed with legacyHashTransaction, or partly so,
+// with the chain key. It returns the rows to store and their new head, which is nil for an empty
+// chain, or the broken link if the chain does not verify under either hash.
+func rekeyChain(userID string, transactions []UserTransaction) ([]UserTransaction, *ChainHead, *BrokenLinkResponse) {
+	// legacy rows can only precede keyed ones, a legacy row after a keyed one was rewritten
+	keyed := false
+	result, _ := verifyLinks(userID, transactions, func(ut UserTransaction) string {
+		hash := hashTransaction(ut)
+		if keyed || (ut.Hash != nil && hmac.Equal([]byte(strings.TrimSpace(*ut.Hash)), []byte(hash))) {
+			keyed = true
+			return hash
+		}
+		return legacyHashTransaction(ut)
+	})
+	if !result.Valid {
+		return nil, nil, result.BrokenLink
+	}
+
+	rekeyed := make([]UserTransaction, len(transactions))
+	prevHash := ""
+	for i, ut := range transactions {
+		prev := prevHash
+		ut.PrevHash = &prev
+		hash := hashTransaction(ut)
+		ut.Hash = &hash
+		rekeyed[i] = ut
+		prevHash = hash
+	}
+	if len(rekeyed) == 0 {
+		return rekeyed, nil, nil
+	}
+	lastSeq := *rekeyed[len(rekeyed)-1].ChainSeq
+	return rekeyed, &ChainHead{
+		UserID:   userID,
+		ChainSeq: lastSeq,
+		Hash:     prevHash,
+		Anchor:   anchorChainHead(userID, lastSeq, prevHash),
+	}, nil
+}
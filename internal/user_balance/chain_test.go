@@ -0,0 +1,327 @@
+package userbalance
+
+import (
+	"testing"
+	"time"
+)
+
+// buildChain chains n transactions of userID with hash and returns them with their head.
+func buildChain(userID string, n int, hash func(UserTransaction) string) ([]UserTransaction, *ChainHead) {
+	var transactions []UserTransaction
+	prevHash := ""
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i := 1; i <= n; i++ {
+		seq := int64(i)
+		prev := prevHash
+		ut := UserTransaction{
+			TransactionID:     "tx" + string(rune('a'+i)),
+			UserID:            userID,
+			Amount:            -1000 * i,
+			Currency:          "IDR",
+			BankAccountNumber: "1234567890",
+			BankName:          "BCA",
+			CreatedAt:         createdAt.Add(time.Duration(i) * time.Minute),
+			ChainSeq:          &seq,
+			PrevHash:          &prev,
+		}
+		h := hash(ut)
+		ut.Hash = &h
+		transactions = append(transactions, ut)
+		prevHash = h
+	}
+	if n == 0 {
+		return transactions, nil
+	}
+	return transactions, &ChainHead{
+		UserID:   userID,
+		ChainSeq: int64(n),
+		Hash:     prevHash,
+		Anchor:   anchorChainHead(userID, int64(n), prevHash),
+	}
+}
+
+func TestUseChainKey(t *testing.T) {
+	if err := UseChainKey(""); err != ErrNoChainKey {
+		t.Fatalf("UseChainKey(\"\") = %v, want %v", err, ErrNoChainKey)
+	}
+}
+
+func TestHashTransactionIsKeyed(t *testing.T) {
+	if err := UseChainKey("first secret"); err != nil {
+		t.Fatal(err)
+	}
+	transactions, _ := buildChain("1", 1, hashTransaction)
+	first := hashTransaction(transactions[0])
+
+	if err := UseChainKey("second secret"); err != nil {
+		t.Fatal(err)
+	}
+	if second := hashTransaction(transactions[0]); second == first {
+		t.Fatalf("hash does not depend on the chain key: %s", second)
+	}
+	if legacy := legacyHashTransaction(transactions[0]); legacy == first {
+		t.Fatalf("keyed hash equals the unkeyed hash: %s", legacy)
+	}
+}
+
+func TestVerifyTransactionChain(t *testing.T) {
+	if err := UseChainKey("test secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		length      int
+		tamper      func([]UserTransaction, *ChainHead) ([]UserTransaction, *ChainHead)
+		wantValid   bool
+		wantChecked int
+		wantSeq     int64
+		wantReason  string
+	}{
+		{
+			name:        "valid chain",
+			length:      3,
+			wantValid:   true,
+			wantChecked: 3,
+		},
+		{
+			name:      "empty chain without head",
+			length:    0,
+			wantValid: true,
+		},
+		{
+			name:   "edited amount",
+			length: 3,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				txs[1].Amount = 1
+				return txs, head
+			},
+			wantChecked: 1,
+			wantSeq:     2,
+			wantReason:  "transaction content does not match its hash",
+		},
+		{
+			name:   "edited and rehashed without the key",
+			length: 3,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				txs[2].Amount = 1
+				h := legacyHashTransaction(txs[2])
+				txs[2].Hash = &h
+				return txs, head
+			},
+			wantChecked: 2,
+			wantSeq:     3,
+			wantReason:  "transaction content does not match its hash",
+		},
+		{
+			name:   "missing transaction in the middle",
+			length: 3,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				return append(txs[:1], txs[2:]...), head
+			},
+			wantChecked: 1,
+			wantSeq:     2,
+			wantReason:  "expected chain sequence 2, a transaction is missing",
+		},
+		{
+			name:   "broken previous hash",
+			length: 3,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				prev := "0000"
+				txs[1].PrevHash = &prev
+				return txs, head
+			},
+			wantChecked: 1,
+			wantSeq:     2,
+			wantReason:  "previous hash does not match the preceding transaction",
+		},
+		{
+			name:   "truncated tail",
+			length: 3,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				return txs[:2], head
+			},
+			wantChecked: 2,
+			wantSeq:     3,
+			wantReason:  "chain head is at sequence 3, later transactions are missing",
+		},
+		{
+			name:   "whole chain deleted",
+			length: 2,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				return nil, head
+			},
+			wantSeq:    1,
+			wantReason: "chain head is at sequence 2, later transactions are missing",
+		},
+		{
+			name:   "missing head",
+			length: 2,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				return txs, nil
+			},
+			wantChecked: 2,
+			wantSeq:     2,
+			wantReason:  "chain head is missing",
+		},
+		{
+			name:   "head moved back without the key",
+			length: 3,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				head.ChainSeq = 2
+				head.Hash = *txs[1].Hash
+				return txs[:2], head
+			},
+			wantChecked: 2,
+			wantSeq:     2,
+			wantReason:  "chain head does not match its anchor",
+		},
+		{
+			name:   "head behind the chain",
+			length: 3,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				return txs, &ChainHead{
+					UserID:   head.UserID,
+					ChainSeq: 2,
+					Hash:     *txs[1].Hash,
+					Anchor:   anchorChainHead(head.UserID, 2, *txs[1].Hash),
+				}
+			},
+			wantChecked: 3,
+			wantSeq:     2,
+			wantReason:  "latest transaction does not match the chain head",
+		},
+		{
+			name:   "head of another user",
+			length: 2,
+			tamper: func(txs []UserTransaction, head *ChainHead) ([]UserTransaction, *ChainHead) {
+				head.Anchor = anchorChainHead("2", head.ChainSeq, head.Hash)
+				return txs, head
+			},
+			wantChecked: 2,
+			wantSeq:     2,
+			wantReason:  "chain head does not match its anchor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions, head := buildChain("1", tt.length, hashTransaction)
+			if tt.tamper != nil {
+				transactions, head = tt.tamper(transactions, head)
+			}
+
+			got := VerifyTransactionChain("1", transactions, head)
+			if got.Valid != tt.wantValid {
+				t.Fatalf("Valid = %v, want %v (broken link %+v)", got.Valid, tt.wantValid, got.BrokenLink)
+			}
+			if got.Checked != tt.wantChecked {
+				t.Errorf("Checked = %d, want %d", got.Checked, tt.wantChecked)
+			}
+			if tt.wantValid {
+				if got.BrokenLink != nil {
+					t.Errorf("BrokenLink = %+v, want nil", got.BrokenLink)
+				}
+				return
+			}
+			if got.BrokenLink == nil {
+				t.Fatal("BrokenLink = nil")
+			}
+			if got.BrokenLink.ChainSeq != tt.wantSeq {
+				t.Errorf("BrokenLink.ChainSeq = %d, want %d", got.BrokenLink.ChainSeq, tt.wantSeq)
+			}
+			if got.BrokenLink.Reason != tt.wantReason {
+				t.Errorf("BrokenLink.Reason = %q, want %q", got.BrokenLink.Reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRekeyChain(t *testing.T) {
+	if err := UseChainKey("test secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		build      func() []UserTransaction
+		wantBroken bool
+	}{
+		{
+			name: "legacy chain",
+			build: func() []UserTransaction {
+				txs, _ := buildChain("1", 3, legacyHashTransaction)
+				return txs
+			},
+		},
+		{
+			name: "keyed chain",
+			build: func() []UserTransaction {
+				txs, _ := buildChain("1", 3, hashTransaction)
+				return txs
+			},
+		},
+		{
+			name: "legacy rows followed by keyed rows",
+			build: func() []UserTransaction {
+				legacyRows := 0
+				txs, _ := buildChain("1", 4, func(ut UserTransaction) string {
+					if legacyRows < 2 {
+						legacyRows++
+						return legacyHashTransaction(ut)
+					}
+					return hashTransaction(ut)
+				})
+				return txs
+			},
+		},
+		{
+			name:  "empty chain",
+			build: func() []UserTransaction { return nil },
+		},
+		{
+			name: "edited legacy chain",
+			build: func() []UserTransaction {
+				txs, _ := buildChain("1", 3, legacyHashTransaction)
+				txs[1].Amount = 1
+				return txs
+			},
+			wantBroken: true,
+		},
+		{
+			name: "legacy row after a keyed row",
+			build: func() []UserTransaction {
+				keyedRows := 0
+				txs, _ := buildChain("1", 3, func(ut UserTransaction) string {
+					if keyedRows < 1 {
+						keyedRows++
+						return hashTransaction(ut)
+					}
+					return legacyHashTransaction(ut)
+				})
+				return txs
+			},
+			wantBroken: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rekeyed, head, broken := rekeyChain("1", tt.build())
+			if (broken != nil) != tt.wantBroken {
+				t.Fatalf("broken link = %+v, want broken %v", broken, tt.wantBroken)
+			}
+			if tt.wantBroken {
+				return
+			}
+
+			got := VerifyTransactionChain("1", rekeyed, head)
+			if !got.Valid {
+				t.Fatalf("rekeyed chain does not verify: %+v", got.BrokenLink)
+			}
+			if got.Checked != len(rekeyed) {
+				t.Errorf("Checked = %d, want %d", got.Checked, len(rekeyed))
+			}
+		})
+	}
+}
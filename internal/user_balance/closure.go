@@ -0,0 +1,101 @@
+package userbalance
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ClosingPayout is the external bank account the balances of a closing account are paid out to.
+type ClosingPayout struct {
+	BankAccountNumber string
+	BankName          string
+}
+
+// SettleBalancesForClosure empties the user's balances inside tx so the account can be closed.
+// Without payout every balance must already be zero, otherwise ErrBalanceNotEmpty is returned;
+// with payout each positive balance is queued as an outgoing transfer to it. Overdrawn balances
+// return ErrBalanceOverdrawn and unsettled outgoing transfers ErrTransfersInFlight, as their
+// refunds would land on the closed account. The user's virtual accounts are disabled, so transfers
+// to them are refused instead of crediting the closed account.
+// It is exported so the user domain can close the account in the same transaction.
+func SettleBalancesForClosure(ctx context.Context, tx *sql.Tx, userID string, payout *ClosingPayout) ([]OutgoingTransfer, error) {
+	err := lockUser(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var inFlight bool
+	inFlightQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM outgoing_transfers
+			WHERE user_id = $1 AND status IN ($2, $3)
+		)
+	`
+	err = tx.QueryRowContext(ctx, inFlightQuery, userID, TransferStatusPending, TransferStatusSubmitted).Scan(&inFlight)
+	if err != nil {
+		return nil, err
+	}
+	if inFlight {
+		return nil, ErrTransfersInFlight
+	}
+
+	selectBalancesQuery := `
+		SELECT currency, balance
+		FROM user_balance
+		WHERE user_id = $1 AND balance <> 0
+		ORDER BY currency
+	`
+	rows, err := tx.QueryContext(ctx, selectBalancesQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	var balances []UserBalance
+	for rows.Next() {
+		var ub UserBalance
+		err = rows.Scan(&ub.Currency, &ub.Balance)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		balances = append(balances, ub)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, ub := range balances {
+		if ub.Balance < 0 {
+			return nil, ErrBalanceOverdrawn
+		}
+	}
+	if len(balances) > 0 && payout == nil {
+		return nil, ErrBalanceNotEmpty
+	}
+
+	disableVirtualAccountsQuery := `
+		UPDATE virtual_accounts
+		SET disabled_at = current_timestamp
+		WHERE user_id = $1 AND disabled_at IS NULL
+	`
+	_, err = tx.ExecContext(ctx, disableVirtualAccountsQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := make([]OutgoingTransfer, 0, len(balances))
+	for _, ub := range balances {
+		transfer, err := recordTransaction(ctx, tx, CreateTransactionPayload{
+			RecipientBankAccountNumber: payout.BankAccountNumber,
+			RecipientBankName:          payout.BankName,
+			Balances:                   ub.Balance,
+			FromCurrency:               ub.Currency,
+			UserID:                     userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *transfer)
+	}
+	return transfers, nil
+}
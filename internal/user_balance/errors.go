@@ -13,7 +13,39 @@ var (
 	ErrorBadRequest    = Response{Code: http.StatusBadRequest, Message: "Bad Request"}
 	ErrorNoRecords     = Response{Code: http.StatusOK, Message: "No records found"}
 	ErrorNotFound      = Response{Code: http.StatusNotFound, Message: "No records found"}
+	ErrorLocked        = Response{Code: http.StatusLocked, Message: "Locked"}
+	ErrorConflict      = Response{Code: http.StatusConflict, Message: "Conflict"}
 
-	ErrNotEnoughBalance         = errors.New("not enough balance")
-	ErrNoCurrencyOrUserRecorded = errors.New("no user or balance with requested currency")
+	ErrNotEnoughBalance           = errors.New("not enough balance")
+	ErrNoChainKey                 = errors.New("transaction chain key is not set")
+	ErrNoCurrencyOrUserRecorded   = errors.New("no user or balance with requested currency")
+	ErrOverdraftLimitBelowBalance = errors.New("balance is already below the requested overdraft limit")
+	ErrInvalidTransferTransition  = errors.New("transfer is not in the expected status")
+	ErrTransferInProgress         = errors.New("transfer is being processed by the payout gateway, try again later")
+	ErrTransferNotFound           = errors.New("transfer not found")
+	ErrNoTransfersToExport        = errors.New("no pending transfers to export")
+	ErrPaymentExportNotFound      = errors.New("payment export not found")
+	ErrStatementAlreadyImported   = errors.New("statement was already imported")
+	ErrStatementLineNotFound      = errors.New("statement line not found or already resolved")
+	ErrDepositNotFound            = errors.New("pending deposit not found")
+	ErrDepositCurrencyMismatch    = errors.New("deposit currency does not match the statement line")
+	ErrVirtualAccountNotFound     = errors.New("virtual account not found")
+	ErrVirtualAccountInvalid      = errors.New("virtual account number has an invalid check digit")
+	ErrVirtualAccountLimit        = errors.New("virtual account limit for this currency reached")
+	ErrVirtualAccountExists       = errors.New("virtual account number already exists")
+	ErrSelfTransfer               = errors.New("cannot transfer to yourself")
+	ErrQRAmountRequired           = errors.New("amount is required for a static QR payload")
+	ErrQRAmountMismatch           = errors.New("amount does not match the dynamic QR payload")
+	ErrTransferChallengeNotFound  = errors.New("transfer challenge not found, expired or already confirmed")
+	ErrStepUpFailed               = errors.New("proof of identity was not accepted")
+	ErrStepUpRequired             = errors.New("confirm a payment of this amount with a step-up factor")
+	ErrStepUpLocked               = errors.New("step-up is locked after too many wrong attempts, try again later")
+	ErrPINNotSet                  = errors.New("set a transaction PIN before moving money")
+	ErrEmailNotVerified           = errors.New("verify your email address before moving money")
+	ErrAccountClosed              = errors.New("account is closed")
+	ErrBalanceNotEmpty            = errors.New("balances must be zero, or name a bank account to pay them out to, before closing the account")
+	ErrBalanceOverdrawn           = errors.New("repay overdrawn balances before closing the account")
+	ErrTransfersInFlight          = errors.New("wait for outgoing transfers to settle before closing the account")
+	ErrWrongPIN                   = errors.New("wrong transaction PIN")
+	ErrPINLocked                  = errors.New("transaction PIN is locked after too many wrong attempts, try again later or reset it")
 )
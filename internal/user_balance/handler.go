@@ -2,13 +2,21 @@ package userbalance
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/citadel-corp/paimon-bank/internal/common/middleware"
 	"github.com/citadel-corp/paimon-bank/internal/common/request"
 	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/citadel-corp/paimon-bank/internal/statement"
+	"github.com/gorilla/mux"
+	"github.com/skip2/go-qrcode"
 )
 
+const qrImageSize = 512
+
 type Handler struct {
 	service Service
 }
@@ -100,6 +108,52 @@ func (h *Handler) Transaction(w http.ResponseWriter, r *http.Request) {
 
 	response.JSON(w, resp.Code, response.ResponseBody{
 		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
+
+func (h *Handler) ConfirmTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req ConfirmTransactionPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	req.ChallengeID = mux.Vars(r)["challengeId"]
+	req.UserID = userID
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := h.service.ConfirmTransaction(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
 	})
 }
 
@@ -177,6 +231,651 @@ func (h *Handler) ListTransaction(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) VerifyChain(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req VerifyChainPayload
+
+	req.UserID = userID
+
+	resp := h.service.VerifyChain(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
+
+func (h *Handler) ListOverdraft(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req ListOverdraftPayload
+	var params = r.URL.Query()
+	if v, ok := request.CheckPositiveInt(params, "limit"); ok {
+		req.Limit = v
+		if v == 0 {
+			req.Limit = 5
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if v, ok := request.CheckPositiveInt(params, "offset"); ok {
+		req.Offset = v
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	req.UserID = userID
+
+	resp := h.service.ListOverdraft(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+		Meta:    resp.Meta,
+	})
+}
+
+func (h *Handler) SetOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	var req SetOverdraftLimitPayload
+
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := h.service.SetOverdraftLimit(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+	})
+}
+
+func (h *Handler) ExportTransfers(w http.ResponseWriter, r *http.Request) {
+	var req ExportTransfersPayload
+	var params = r.URL.Query()
+	if v, ok := request.CheckPositiveInt(params, "limit"); ok {
+		req.Limit = v
+		if v == 0 {
+			req.Limit = 500
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	resp := h.service.ExportTransfers(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	writePaymentExport(w, resp.Code, resp.Data.(*PaymentExport))
+}
+
+func (h *Handler) GetPaymentExport(w http.ResponseWriter, r *http.Request) {
+	var req GetPaymentExportPayload
+
+	req.MessageID = mux.Vars(r)["messageId"]
+
+	resp := h.service.GetPaymentExport(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	writePaymentExport(w, resp.Code, resp.Data.(*PaymentExport))
+}
+
+func (h *Handler) UpdateTransferStatus(w http.ResponseWriter, r *http.Request) {
+	var req UpdateTransferStatusPayload
+
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	req.TransactionID = mux.Vars(r)["transactionId"]
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := h.service.UpdateTransferStatus(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+	})
+}
+
+func (h *Handler) CreateDeposit(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateDepositPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	req.UserID = userID
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := h.service.CreateDeposit(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
+
+func (h *Handler) ListDeposit(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req ListDepositPayload
+	var params = r.URL.Query()
+	if v, ok := request.CheckPositiveInt(params, "limit"); ok {
+		req.Limit = v
+		if v == 0 {
+			req.Limit = 5
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if v, ok := request.CheckPositiveInt(params, "offset"); ok {
+		req.Offset = v
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	req.UserID = userID
+
+	resp := h.service.ListDeposit(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+		Meta:    resp.Meta,
+	})
+}
+
+func (h *Handler) ImportStatement(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 10*1024*1024) // 10 MB
+
+	var req ImportStatementPayload
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Statement must be smaller than 10 MB",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	req.Format = statement.Format(r.URL.Query().Get("format"))
+	req.Data = data
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := h.service.ImportStatement(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
+
+func (h *Handler) ListStatementException(w http.ResponseWriter, r *http.Request) {
+	var req ListStatementLinePayload
+	var params = r.URL.Query()
+	if v, ok := request.CheckPositiveInt(params, "limit"); ok {
+		req.Limit = v
+		if v == 0 {
+			req.Limit = 20
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if v, ok := request.CheckPositiveInt(params, "offset"); ok {
+		req.Offset = v
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	req.Status = StatementLineStatusUnmatched
+
+	resp := h.service.ListStatementLine(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+		Meta:    resp.Meta,
+	})
+}
+
+func (h *Handler) ResolveStatementException(w http.ResponseWriter, r *http.Request) {
+	var req ResolveStatementLinePayload
+
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	req.LineID, err = strconv.ParseUint(mux.Vars(r)["lineId"], 10, 64)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, response.ResponseBody{
+			Message: "Not found",
+			Error:   ErrStatementLineNotFound.Error(),
+		})
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := h.service.ResolveStatementLine(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+	})
+}
+
+func (h *Handler) CreateVirtualAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateVirtualAccountPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	req.UserID = userID
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := h.service.CreateVirtualAccount(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
+
+func (h *Handler) ListVirtualAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req ListVirtualAccountPayload
+
+	req.UserID = userID
+
+	resp := h.service.ListVirtualAccount(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
+
+func (h *Handler) InboundTransfer(w http.ResponseWriter, r *http.Request) {
+	var req InboundTransferPayload
+
+	err := request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := h.service.ReceiveInboundTransfer(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
+
+// GenerateQR returns a QR payload paying the current user, dynamic when amount is set.
+func (h *Handler) GenerateQR(w http.ResponseWriter, r *http.Request) {
+	req, ok := generateQRRequest(w, r)
+	if !ok {
+		return
+	}
+
+	resp := h.service.GenerateQR(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
+
+// GenerateQRImage renders the same payload as GenerateQR as a PNG download.
+func (h *Handler) GenerateQRImage(w http.ResponseWriter, r *http.Request) {
+	req, ok := generateQRRequest(w, r)
+	if !ok {
+		return
+	}
+
+	resp := h.service.GenerateQR(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	png, err := qrcode.Encode(resp.Data.(QRResponse).Payload, qrcode.Medium, qrImageSize)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, response.ResponseBody{
+			Message: ErrorInternal.Message,
+			Error:   err.Error(),
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Disposition", `attachment; filename="paimon-qr.png"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(png)
+}
+
+func generateQRRequest(w http.ResponseWriter, r *http.Request) (GenerateQRPayload, bool) {
+	var req GenerateQRPayload
+
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return req, false
+	}
+
+	var params = r.URL.Query()
+	if v, ok := request.CheckPositiveInt(params, "amount"); ok {
+		req.Amount = v
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		return req, false
+	}
+	req.Currency = params.Get("currency")
+	req.Reference = params.Get("reference")
+	req.UserID = userID
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return req, false
+	}
+	return req, true
+}
+
+func (h *Handler) PayQR(w http.ResponseWriter, r *http.Request) {
+	userID, err := getUserID(r)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req PayQRPayload
+
+	err = request.DecodeJSON(w, r, &req)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: "Failed to decode JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	req.UserID = userID
+
+	err = req.Validate()
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, response.ResponseBody{
+			Message: err.Error(),
+		})
+		return
+	}
+
+	resp := h.service.PayQR(r.Context(), req)
+	if resp.Error != "" {
+		response.JSON(w, resp.Code, response.ResponseBody{
+			Message: resp.Message,
+			Error:   resp.Error,
+		})
+		return
+	}
+
+	response.JSON(w, resp.Code, response.ResponseBody{
+		Message: resp.Message,
+		Data:    resp.Data,
+	})
+}
+
+// writePaymentExport sends the pain.001 document as a file download.
+func writePaymentExport(w http.ResponseWriter, status int, export *PaymentExport) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.MessageID+".xml"))
+	w.Header().Set("X-Number-Of-Transactions", strconv.Itoa(export.NumberOfTransactions))
+	w.Header().Set("X-Control-Sum", strconv.Itoa(export.ControlSum))
+	w.WriteHeader(status)
+	w.Write(export.Document)
+}
+
 func getUserID(r *http.Request) (string, error) {
 	if authValue, ok := r.Context().Value(middleware.ContextAuthKey{}).(string); ok {
 		return authValue, nil
@@ -0,0 +1,166 @@
+package userbalance
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+)
+
+// InternalBankName is recorded as the bank on both sides of a transfer between Paimon users.
+const InternalBankName = "Paimon Bank"
+
+// InternalTransfer moves money from one Paimon user to another without leaving the bank.
+// DebitTransactionID and CreditTransactionID are filled in once the transfer is recorded.
+type InternalTransfer struct {
+	FromUserID          string
+	ToUserID            string
+	Amount              int
+	Currency            string
+	DebitTransactionID  string
+	CreditTransactionID string
+}
+
+// LockOpenUsers locks two different users in ID order, so opposite moves between the same pair
+// cannot deadlock. It returns ErrAccountClosed when either account is closed, and is exported for
+// other domains that move money between two users in their own transaction.
+func LockOpenUsers(ctx context.Context, tx *sql.Tx, userID, otherUserID string) error {
+	lockUsersQuery := `
+		SELECT closed_at IS NOT NULL FROM users
+		WHERE id IN ($1, $2)
+		ORDER BY id
+		FOR UPDATE
+	`
+	rows, err := tx.QueryContext(ctx, lockUsersQuery, userID, otherUserID)
+	if err != nil {
+		return err
+	}
+	locked := 0
+	anyClosed := false
+	for rows.Next() {
+		var closed bool
+		err = rows.Scan(&closed)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		locked++
+		anyClosed = anyClosed || closed
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if locked != 2 {
+		return ErrNoCurrencyOrUserRecorded
+	}
+	if anyClosed {
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// RecordInternalTransfer debits the sender and credits the recipient inside tx, appending a
+// transaction to both users' chains with the counterparty's user ID as the account number.
+// Both users are locked with LockOpenUsers first. Closed accounts can neither send nor receive, returning ErrAccountClosed.
+// It is exported so other domains can move money atomically with their own state changes.
+func RecordInternalTransfer(ctx context.Context, tx *sql.Tx, t *InternalTransfer) error {
+	if t.FromUserID == t.ToUserID {
+		return ErrSelfTransfer
+	}
+
+	err := LockOpenUsers(ctx, tx, t.FromUserID, t.ToUserID)
+	if err != nil {
+		return err
+	}
+
+	err = debitBalance(ctx, tx, t.FromUserID, t.Currency, t.Amount)
+	if err != nil {
+		return err
+	}
+	debit := &UserTransaction{
+		TransactionID:     id.GenerateStringID(16),
+		UserID:            t.FromUserID,
+		Amount:            -t.Amount,
+		Currency:          t.Currency,
+		BankAccountNumber: t.ToUserID,
+		BankName:          InternalBankName,
+	}
+	err = insertChainedTransaction(ctx, tx, debit)
+	if err != nil {
+		return err
+	}
+
+	err = creditBalance(ctx, tx, t.ToUserID, t.Currency, t.Amount)
+	if err != nil {
+		return err
+	}
+	credit := &UserTransaction{
+		TransactionID:     id.GenerateStringID(16),
+		UserID:            t.ToUserID,
+		Amount:            t.Amount,
+		Currency:          t.Currency,
+		BankAccountNumber: t.FromUserID,
+		BankName:          InternalBankName,
+	}
+	err = insertChainedTransaction(ctx, tx, credit)
+	if err != nil {
+		return err
+	}
+
+	t.DebitTransactionID = debit.TransactionID
+	t.CreditTransactionID = credit.TransactionID
+	return nil
+}
+
+// HoldingMove moves money between a user's balance and a holding account inside the bank,
+// such as an escrow, identified by Account. TransactionID is filled in once the move is recorded.
+type HoldingMove struct {
+	UserID        string
+	Amount        int
+	Currency      string
+	Account       string
+	BankName      string
+	TransactionID string
+}
+
+// RecordHoldingDebit takes Amount out of the user's balance into the holding account inside tx.
+// Closed accounts cannot fund a holding account and return ErrAccountClosed.
+func RecordHoldingDebit(ctx context.Context, tx *sql.Tx, m *HoldingMove) error {
+	err := lockOpenUser(ctx, tx, m.UserID)
+	if err != nil {
+		return err
+	}
+	err = debitBalance(ctx, tx, m.UserID, m.Currency, m.Amount)
+	if err != nil {
+		return err
+	}
+	return recordHoldingMove(ctx, tx, m, -m.Amount)
+}
+
+// RecordHoldingCredit pays Amount out of the holding account into the user's balance inside tx.
+// Closed accounts cannot receive it and return ErrAccountClosed.
+func RecordHoldingCredit(ctx context.Context, tx *sql.Tx, m *HoldingMove) error {
+	err := creditBalance(ctx, tx, m.UserID, m.Currency, m.Amount)
+	if err != nil {
+		return err
+	}
+	return recordHoldingMove(ctx, tx, m, m.Amount)
+}
+
+func recordHoldingMove(ctx context.Context, tx *sql.Tx, m *HoldingMove, amount int) error {
+	ut := &UserTransaction{
+		TransactionID:     id.GenerateStringID(16),
+		UserID:            m.UserID,
+		Amount:            amount,
+		Currency:          m.Currency,
+		BankAccountNumber: m.Account,
+		BankName:          m.BankName,
+	}
+	err := insertChainedTransaction(ctx, tx, ut)
+	if err != nil {
+		return err
+	}
+	m.TransactionID = ut.TransactionID
+	return nil
+}
@@ -0,0 +1,46 @@
+package userbalance
+
+import (
+	"context"
+	"errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+const PINLength = 6
+
+// PINRules validate the pin field every money-moving request carries.
+var PINRules = []validation.Rule{validation.Required, validation.Length(PINLength, PINLength), is.Digit}
+
+// PINVerifier checks the transaction PIN authorizing money to leave a user's balance.
+// It is implemented by the user domain and returns ErrEmailNotVerified, ErrPINNotSet, ErrWrongPIN or ErrPINLocked.
+type PINVerifier interface {
+	VerifyPIN(ctx context.Context, userID, pin string) error
+}
+
+// verifyPIN returns the error response when pin does not authorize the user's transfer.
+func (s *userBalanceService) verifyPIN(ctx context.Context, userID, pin string) (Response, bool) {
+	err := s.pins.VerifyPIN(ctx, userID, pin)
+	if errors.Is(err, ErrPINNotSet) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp, false
+	}
+	if errors.Is(err, ErrWrongPIN) || errors.Is(err, ErrEmailNotVerified) {
+		resp := ErrorForbidden
+		resp.Error = err.Error()
+		return resp, false
+	}
+	if errors.Is(err, ErrPINLocked) {
+		resp := ErrorLocked
+		resp.Error = err.Error()
+		return resp, false
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp, false
+	}
+	return Response{}, true
+}
@@ -4,18 +4,51 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/citadel-corp/paimon-bank/internal/common/db"
 	"github.com/citadel-corp/paimon-bank/internal/common/id"
 	"github.com/citadel-corp/paimon-bank/internal/common/response"
+	"github.com/citadel-corp/paimon-bank/internal/iso20022"
 	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type Repository interface {
 	RecordBalance(ctx context.Context, payload CreateUserBalancePayload) error
-	RecordTransaction(ctx context.Context, payload CreateTransactionPayload) error
+	RecordTransaction(ctx context.Context, payload CreateTransactionPayload) (*OutgoingTransfer, error)
 	FindByUserID(ctx context.Context, userID string) ([]UserBalanceResponse, error)
 	ListTransactions(ctx context.Context, payload ListUserTransactionPayload) ([]UserTransaction, *response.Pagination, error)
+	ListChainUserIDs(ctx context.Context) ([]string, error)
+	ListChain(ctx context.Context, userID string) ([]UserTransaction, *ChainHead, error)
+	RekeyChain(ctx context.Context, userID string) (*BrokenLinkResponse, error)
+	SetOverdraftLimit(ctx context.Context, payload SetOverdraftLimitPayload) error
+	ListOverdrafts(ctx context.Context, payload ListOverdraftPayload) ([]Overdraft, *response.Pagination, error)
+	ClaimTransfers(ctx context.Context, status TransferStatus, limit int, lease time.Duration) ([]OutgoingTransfer, error)
+	MarkTransferSubmitted(ctx context.Context, transactionID, gatewayReference string) error
+	MarkTransferSettled(ctx context.Context, transactionID string) error
+	FailTransfer(ctx context.Context, transfer OutgoingTransfer, reason string) error
+	// FailUnclaimedTransfer is FailTransfer for operators: it returns ErrTransferInProgress while a processor holds the transfer.
+	FailUnclaimedTransfer(ctx context.Context, transfer OutgoingTransfer, reason string) error
+	GetTransfer(ctx context.Context, transactionID string) (*OutgoingTransfer, error)
+	ExportTransfers(ctx context.Context, limit int, build func([]OutgoingTransfer) (*iso20022.Pain001, error)) (*PaymentExport, error)
+	GetPaymentExport(ctx context.Context, messageID string) (*PaymentExport, error)
+	CreateDeposit(ctx context.Context, deposit *Deposit) error
+	ListDeposits(ctx context.Context, payload ListDepositPayload) ([]Deposit, *response.Pagination, error)
+	ImportStatement(ctx context.Context, statement *BankStatement, lines []StatementLine) ([]StatementLine, error)
+	MatchStatementLine(ctx context.Context, line StatementLine) (bool, error)
+	ConfirmDepositWithLine(ctx context.Context, lineID uint64, depositID string) error
+	DismissStatementLine(ctx context.Context, lineID uint64, note string) error
+	ListStatementLines(ctx context.Context, payload ListStatementLinePayload) ([]StatementLine, *response.Pagination, error)
+	CreateVirtualAccount(ctx context.Context, va *VirtualAccount, limit int) error
+	ListVirtualAccounts(ctx context.Context, userID string) ([]VirtualAccount, error)
+	GetVirtualAccount(ctx context.Context, accountNumber string) (*VirtualAccount, error)
+	RecordInternalTransfer(ctx context.Context, transfer *InternalTransfer) error
+	GetAccountName(ctx context.Context, userID string) (string, error)
+	CreateTransferChallenge(ctx context.Context, challenge *TransferChallenge) error
+	GetTransferChallenge(ctx context.Context, id, userID string) (*TransferChallenge, error)
+	FailTransferChallenge(ctx context.Context, id string) error
+	ConfirmTransferChallenge(ctx context.Context, challenge *TransferChallenge) (*OutgoingTransfer, error)
 }
 
 type dbRepository struct {
@@ -29,98 +62,302 @@ func NewRepository(db *db.DB) Repository {
 // Create implements Repository.
 func (d *dbRepository) RecordBalance(ctx context.Context, payload CreateUserBalancePayload) error {
 	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
-		// upsert balance
-		upsertBalanceQuery := `
-			INSERT INTO user_balance (
-				balance, currency, user_id
-			) VALUES (
-				$1, $2, $3
-			)
-			ON CONFLICT ON CONSTRAINT user_balance_user_id_currency_unique
-			DO UPDATE
-				SET balance = user_balance.balance + $1;
-		`
-		_, err := tx.ExecContext(ctx, upsertBalanceQuery, payload.AddedBalance, payload.Currency, payload.UserID)
+		err := creditBalance(ctx, tx, payload.UserID, payload.Currency, payload.AddedBalance)
 		if err != nil {
 			return err
 		}
 
 		// insert into transactions
-		createTransactionQuery := `
-			INSERT INTO user_transactions (
-				id, user_id, amount, currency, bank_account_number, bank_name, image_url
-			) VALUES (
-				$1, $2, $3, $4, $5, $6, $7
-			)
-		`
-		_, err = tx.ExecContext(ctx, createTransactionQuery, id.GenerateStringID(16), payload.UserID, payload.AddedBalance, payload.Currency, payload.SenderBankAccountNumber, payload.SenderBankName, payload.TransferProofImg)
-		if err != nil {
-			return err
+		ut := &UserTransaction{
+			TransactionID:     id.GenerateStringID(16),
+			UserID:            payload.UserID,
+			Amount:            payload.AddedBalance,
+			Currency:          payload.Currency,
+			BankAccountNumber: payload.SenderBankAccountNumber,
+			BankName:          payload.SenderBankName,
+		}
+		if payload.TransferProofImg != "" {
+			ut.ImageURL = &payload.TransferProofImg
 		}
+		return insertChainedTransaction(ctx, tx, ut)
+	})
+}
 
-		return nil
+func (d *dbRepository) RecordTransaction(ctx context.Context, payload CreateTransactionPayload) (*OutgoingTransfer, error) {
+	var transfer *OutgoingTransfer
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		transfer, err = recordTransaction(ctx, tx, payload)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
 }
 
-func (d *dbRepository) RecordTransaction(ctx context.Context, payload CreateTransactionPayload) error {
-	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
-		updateBalanceQuery := `
-			UPDATE user_balance
-			SET balance = balance - $1
-			WHERE user_id = $2 and currency = $3
-			RETURNING id
-		`
-		row := tx.QueryRowContext(ctx, updateBalanceQuery, payload.Balances, payload.UserID, payload.FromCurrency)
-		var userBalanceID uint64
-		err := row.Scan(&userBalanceID)
-		var pgErr *pgconn.PgError
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return ErrNoCurrencyOrUserRecorded
-			}
-			if errors.As(err, &pgErr) {
-				switch pgErr.Code {
-				case "23514":
-					if pgErr.ConstraintName == "balance_non_negative" {
-						return ErrNotEnoughBalance
-					}
-					return err
-				default:
-					return err
+// recordTransaction debits the sender and queues the payout to the recipient bank inside tx.
+func recordTransaction(ctx context.Context, tx *sql.Tx, payload CreateTransactionPayload) (*OutgoingTransfer, error) {
+	err := debitBalance(ctx, tx, payload.UserID, payload.FromCurrency, payload.Balances)
+	if err != nil {
+		return nil, err
+	}
+
+	// insert into transactions
+	ut := &UserTransaction{
+		TransactionID:     id.GenerateStringID(16),
+		UserID:            payload.UserID,
+		Amount:            -payload.Balances,
+		Currency:          payload.FromCurrency,
+		BankAccountNumber: payload.RecipientBankAccountNumber,
+		BankName:          payload.RecipientBankName,
+	}
+	err = insertChainedTransaction(ctx, tx, ut)
+	if err != nil {
+		return nil, err
+	}
+
+	// queue the payout to the recipient bank
+	createTransferQuery := `
+		INSERT INTO outgoing_transfers (
+			transaction_id, user_id, amount, currency, bank_account_number, bank_name, status
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+		RETURNING created_at, updated_at
+	`
+	transfer := &OutgoingTransfer{
+		TransactionID:     ut.TransactionID,
+		UserID:            ut.UserID,
+		Amount:            payload.Balances,
+		Currency:          ut.Currency,
+		BankAccountNumber: ut.BankAccountNumber,
+		BankName:          ut.BankName,
+		Status:            TransferStatusPending,
+	}
+	row := tx.QueryRowContext(ctx, createTransferQuery, transfer.TransactionID, transfer.UserID, transfer.Amount, transfer.Currency, transfer.BankAccountNumber, transfer.BankName, transfer.Status)
+	err = row.Scan(&transfer.CreatedAt, &transfer.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// lockUser takes a row lock on the user so balance and chain writes for them are serialized.
+// Every money path locks the user before touching user_balance, keeping lock order consistent.
+func lockUser(ctx context.Context, tx *sql.Tx, userID string) error {
+	lockUserQuery := `
+		SELECT id FROM users
+		WHERE id = $1
+		FOR UPDATE
+	`
+	var lockedUserID uint64
+	err := tx.QueryRowContext(ctx, lockUserQuery, userID).Scan(&lockedUserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoCurrencyOrUserRecorded
+	}
+	return err
+}
+
+// lockOpenUser locks the user like lockUser, returning ErrAccountClosed when the account is closed.
+func lockOpenUser(ctx context.Context, tx *sql.Tx, userID string) error {
+	lockUserQuery := `
+		SELECT closed_at IS NOT NULL FROM users
+		WHERE id = $1
+		FOR UPDATE
+	`
+	var closed bool
+	err := tx.QueryRowContext(ctx, lockUserQuery, userID).Scan(&closed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoCurrencyOrUserRecorded
+	}
+	if err != nil {
+		return err
+	}
+	if closed {
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// debitBalance subtracts amount from the user's balance in currency, within the overdraft limit.
+func debitBalance(ctx context.Context, tx *sql.Tx, userID, currency string, amount int) error {
+	err := lockUser(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	updateBalanceQuery := `
+		UPDATE user_balance
+		SET balance = balance - $1
+		WHERE user_id = $2 and currency = $3
+		RETURNING id, balance
+	`
+	row := tx.QueryRowContext(ctx, updateBalanceQuery, amount, userID, currency)
+	var userBalanceID uint64
+	var balance int
+	err = row.Scan(&userBalanceID, &balance)
+	var pgErr *pgconn.PgError
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoCurrencyOrUserRecorded
+		}
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23514":
+				if pgErr.ConstraintName == "balance_within_overdraft_limit" {
+					return ErrNotEnoughBalance
 				}
+				return err
+			default:
+				return err
 			}
-			return err
 		}
+		return err
+	}
 
-		if userBalanceID == 0 {
-			return ErrNoCurrencyOrUserRecorded
-		}
+	if userBalanceID == 0 {
+		return ErrNoCurrencyOrUserRecorded
+	}
 
-		// insert into transactions
-		createTransactionQuery := `
-			INSERT INTO user_transactions (
-				id, user_id, amount, currency, bank_account_number, bank_name
-			) VALUES (
-				$1, $2, $3, $4, $5, $6
-			)
+	return trackOverdraft(ctx, tx, userBalanceID, userID, currency, balance)
+}
+
+// creditBalance adds amount to the user's balance in currency, creating the balance if needed.
+// Closed accounts cannot receive money and return ErrAccountClosed; refunds of their own payouts
+// go through failTransfer instead.
+func creditBalance(ctx context.Context, tx *sql.Tx, userID, currency string, amount int) error {
+	err := lockOpenUser(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	upsertBalanceQuery := `
+		INSERT INTO user_balance (
+			balance, currency, user_id
+		) VALUES (
+			$1, $2, $3
+		)
+		ON CONFLICT ON CONSTRAINT user_balance_user_id_currency_unique
+		DO UPDATE
+			SET balance = user_balance.balance + $1
+		RETURNING id, balance;
+	`
+	row := tx.QueryRowContext(ctx, upsertBalanceQuery, amount, currency, userID)
+	var userBalanceID uint64
+	var balance int
+	err = row.Scan(&userBalanceID, &balance)
+	if err != nil {
+		return err
+	}
+
+	return trackOverdraft(ctx, tx, userBalanceID, userID, currency, balance)
+}
+
+// trackOverdraft opens an overdraft period when a balance goes negative, records the
+// lowest balance reached while it stays negative, and closes the period once it recovers.
+func trackOverdraft(ctx context.Context, tx *sql.Tx, userBalanceID uint64, userID, currency string, balance int) error {
+	if balance >= 0 {
+		closeOverdraftQuery := `
+			UPDATE user_balance_overdrafts
+			SET ended_at = current_timestamp
+			WHERE user_balance_id = $1 AND ended_at IS NULL
 		`
-		_, err = tx.ExecContext(ctx, createTransactionQuery, id.GenerateStringID(16), payload.UserID, -payload.Balances, payload.FromCurrency, payload.RecipientBankAccountNumber, payload.RecipientBankName)
-		if err != nil {
-			return err
-		}
+		_, err := tx.ExecContext(ctx, closeOverdraftQuery, userBalanceID)
+		return err
+	}
 
-		return nil
+	upsertOverdraftQuery := `
+		INSERT INTO user_balance_overdrafts (
+			user_balance_id, user_id, currency, lowest_balance
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (user_balance_id) WHERE ended_at IS NULL
+		DO UPDATE
+			SET lowest_balance = LEAST(user_balance_overdrafts.lowest_balance, $4);
+	`
+	_, err := tx.ExecContext(ctx, upsertOverdraftQuery, userBalanceID, userID, currency, balance)
+	return err
+}
+
+// insertChainedTransaction appends ut to the user's hash chain inside tx.
+// The user row is locked so concurrent writes for the same user cannot fork the chain.
+func insertChainedTransaction(ctx context.Context, tx *sql.Tx, ut *UserTransaction) error {
+	err := lockUser(ctx, tx, ut.UserID)
+	if err != nil {
+		return err
+	}
+
+	lastLinkQuery := `
+		SELECT chain_seq, hash
+		FROM user_transactions
+		WHERE user_id = $1 AND chain_seq IS NOT NULL
+		ORDER BY chain_seq DESC
+		LIMIT 1
+	`
+	var lastSeq int64
+	var lastHash string
+	err = tx.QueryRowContext(ctx, lastLinkQuery, ut.UserID).Scan(&lastSeq, &lastHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	seq := lastSeq + 1
+	prevHash := strings.TrimSpace(lastHash)
+	ut.ChainSeq = &seq
+	ut.PrevHash = &prevHash
+	// TIMESTAMP keeps microseconds, truncate so the hash survives the round trip
+	ut.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	hash := hashTransaction(*ut)
+	ut.Hash = &hash
+
+	createTransactionQuery := `
+		INSERT INTO user_transactions (
+			id, user_id, amount, currency, bank_account_number, bank_name, image_url, created_at, chain_seq, prev_hash, hash
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		)
+	`
+	_, err = tx.ExecContext(ctx, createTransactionQuery, ut.TransactionID, ut.UserID, ut.Amount, ut.Currency, ut.BankAccountNumber, ut.BankName, ut.ImageURL, ut.CreatedAt, ut.ChainSeq, ut.PrevHash, ut.Hash)
+	if err != nil {
+		return err
+	}
+
+	return upsertChainHead(ctx, tx, ChainHead{
+		UserID:   ut.UserID,
+		ChainSeq: seq,
+		Hash:     hash,
+		Anchor:   anchorChainHead(ut.UserID, seq, hash),
 	})
 }
 
+// upsertChainHead moves the user's chain head to head inside tx.
+func upsertChainHead(ctx context.Context, tx *sql.Tx, head ChainHead) error {
+	upsertHeadQuery := `
+		INSERT INTO transaction_chain_heads (
+			user_id, chain_seq, hash, anchor
+		) VALUES (
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (user_id)
+		DO UPDATE
+			SET chain_seq = EXCLUDED.chain_seq, hash = EXCLUDED.hash, anchor = EXCLUDED.anchor, updated_at = current_timestamp;
+	`
+	_, err := tx.ExecContext(ctx, upsertHeadQuery, head.UserID, head.ChainSeq, head.Hash, head.Anchor)
+	return err
+}
+
 func (d *dbRepository) FindByUserID(ctx context.Context, userID string) ([]UserBalanceResponse, error) {
 	response := []UserBalanceResponse{}
 
 	selectQuery := `
-		SELECT balance, currency
-		FROM user_balance
-		WHERE user_id = $1
-		ORDER BY balance desc
+		SELECT ub.balance, ub.currency, ub.overdraft_limit, o.started_at
+		FROM user_balance ub
+		LEFT JOIN user_balance_overdrafts o
+			ON o.user_balance_id = ub.id AND o.ended_at IS NULL
+		WHERE ub.user_id = $1
+		ORDER BY ub.balance desc
 	`
 
 	rows, err := d.db.DB().QueryContext(ctx, selectQuery, userID)
@@ -130,10 +367,15 @@ func (d *dbRepository) FindByUserID(ctx context.Context, userID string) ([]UserB
 
 	for rows.Next() {
 		var ub UserBalanceResponse
-		err = rows.Scan(&ub.Balance, &ub.Currency)
+		var overdrawnSince *time.Time
+		err = rows.Scan(&ub.Balance, &ub.Currency, &ub.OverdraftLimit, &overdrawnSince)
 		if err != nil {
 			return nil, err
 		}
+		if overdrawnSince != nil {
+			ms := overdrawnSince.UnixMilli()
+			ub.OverdrawnSince = &ms
+		}
 
 		response = append(response, ub)
 	}
@@ -150,10 +392,11 @@ func (d *dbRepository) ListTransactions(ctx context.Context, payload ListUserTra
 	}
 
 	selectQuery := `
-		SELECT COUNT(*) OVER() AS total_count, *
-		FROM user_transactions
-		WHERE user_id = $1
-		ORDER BY created_at DESC
+		SELECT COUNT(*) OVER() AS total_count, ut.id, ut.user_id, ut.amount, ut.currency, ut.bank_account_number, ut.bank_name, ut.image_url, ut.created_at, ot.status
+		FROM user_transactions ut
+		LEFT JOIN outgoing_transfers ot ON ot.transaction_id = ut.id
+		WHERE ut.user_id = $1
+		ORDER BY ut.created_at DESC
 		LIMIT $2
 		OFFSET $3
 	`
@@ -165,7 +408,7 @@ func (d *dbRepository) ListTransactions(ctx context.Context, payload ListUserTra
 
 	for rows.Next() {
 		var ut UserTransaction
-		err = rows.Scan(&pagination.Total, &ut.TransactionID, &ut.UserID, &ut.Amount, &ut.Currency, &ut.BankAccountNumber, &ut.BankName, &ut.ImageURL, &ut.CreatedAt)
+		err = rows.Scan(&pagination.Total, &ut.TransactionID, &ut.UserID, &ut.Amount, &ut.Currency, &ut.BankAccountNumber, &ut.BankName, &ut.ImageURL, &ut.CreatedAt, &ut.TransferStatus)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -175,3 +418,887 @@ func (d *dbRepository) ListTransactions(ctx context.Context, payload ListUserTra
 
 	return resp, pagination, nil
 }
+
+// ListChainUserIDs implements Repository.
+func (d *dbRepository) ListChainUserIDs(ctx context.Context) ([]string, error) {
+	var resp []string
+
+	// a user whose whole chain was deleted still has a head
+	selectQuery := `
+		SELECT user_id::TEXT
+		FROM user_transactions
+		WHERE chain_seq IS NOT NULL
+		UNION
+		SELECT user_id::TEXT
+		FROM transaction_chain_heads
+		ORDER BY user_id
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		err = rows.Scan(&userID)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, userID)
+	}
+
+	return resp, rows.Err()
+}
+
+// ListChain implements Repository.
+// The user row is share locked so the chain and its head are read at the same link.
+func (d *dbRepository) ListChain(ctx context.Context, userID string) ([]UserTransaction, *ChainHead, error) {
+	var transactions []UserTransaction
+	var head *ChainHead
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		shareLockUserQuery := `
+			SELECT id FROM users
+			WHERE id = $1
+			FOR SHARE
+		`
+		var lockedUserID uint64
+		err := tx.QueryRowContext(ctx, shareLockUserQuery, userID).Scan(&lockedUserID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		transactions, err = listChain(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		head, err = getChainHead(ctx, tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return transactions, head, nil
+}
+
+func listChain(ctx context.Context, tx *sql.Tx, userID string) ([]UserTransaction, error) {
+	var resp []UserTransaction
+
+	selectQuery := `
+		SELECT id, user_id, amount, currency, bank_account_number, bank_name, image_url, created_at, chain_seq, prev_hash, hash
+		FROM user_transactions
+		WHERE user_id = $1 AND chain_seq IS NOT NULL
+		ORDER BY chain_seq ASC
+	`
+
+	rows, err := tx.QueryContext(ctx, selectQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ut UserTransaction
+		err = rows.Scan(&ut.TransactionID, &ut.UserID, &ut.Amount, &ut.Currency, &ut.BankAccountNumber, &ut.BankName, &ut.ImageURL, &ut.CreatedAt, &ut.ChainSeq, &ut.PrevHash, &ut.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, ut)
+	}
+
+	return resp, rows.Err()
+}
+
+// getChainHead returns the user's chain head, or nil if the user has none.
+func getChainHead(ctx context.Context, tx *sql.Tx, userID string) (*ChainHead, error) {
+	selectQuery := `
+		SELECT user_id, chain_seq, hash, anchor
+		FROM transaction_chain_heads
+		WHERE user_id = $1
+	`
+	head := &ChainHead{}
+	err := tx.QueryRowContext(ctx, selectQuery, userID).Scan(&head.UserID, &head.ChainSeq, &head.Hash, &head.Anchor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return head, nil
+}
+
+// RekeyChain implements Repository.
+func (d *dbRepository) RekeyChain(ctx context.Context, userID string) (*BrokenLinkResponse, error) {
+	var brokenLink *BrokenLinkResponse
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		err := lockUser(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		transactions, err := listChain(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		rekeyed, head, broken := rekeyChain(userID, transactions)
+		if broken != nil {
+			brokenLink = broken
+			return nil
+		}
+
+		updateLinkQuery := `
+			UPDATE user_transactions
+			SET prev_hash = $3, hash = $4
+			WHERE user_id = $1 AND id = $2
+		`
+		for _, ut := range rekeyed {
+			_, err = tx.ExecContext(ctx, updateLinkQuery, userID, ut.TransactionID, ut.PrevHash, ut.Hash)
+			if err != nil {
+				return err
+			}
+		}
+		if head == nil {
+			return nil
+		}
+		return upsertChainHead(ctx, tx, *head)
+	})
+	return brokenLink, err
+}
+
+// SetOverdraftLimit implements Repository.
+func (d *dbRepository) SetOverdraftLimit(ctx context.Context, payload SetOverdraftLimitPayload) error {
+	upsertLimitQuery := `
+		INSERT INTO user_balance (
+			overdraft_limit, currency, user_id
+		) VALUES (
+			$1, $2, $3
+		)
+		ON CONFLICT ON CONSTRAINT user_balance_user_id_currency_unique
+		DO UPDATE
+			SET overdraft_limit = $1;
+	`
+	_, err := d.db.DB().ExecContext(ctx, upsertLimitQuery, payload.Limit, payload.Currency, payload.UserID)
+	var pgErr *pgconn.PgError
+	if err != nil {
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23503":
+				return ErrNoCurrencyOrUserRecorded
+			case "23514":
+				if pgErr.ConstraintName == "balance_within_overdraft_limit" {
+					return ErrOverdraftLimitBelowBalance
+				}
+				return err
+			default:
+				return err
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// ListOverdrafts implements Repository.
+func (d *dbRepository) ListOverdrafts(ctx context.Context, payload ListOverdraftPayload) ([]Overdraft, *response.Pagination, error) {
+	var resp []Overdraft
+	pagination := &response.Pagination{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	}
+
+	selectQuery := `
+		SELECT COUNT(*) OVER() AS total_count, id, user_id, currency, lowest_balance, started_at, ended_at
+		FROM user_balance_overdrafts
+		WHERE user_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+		OFFSET $3
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, payload.UserID, payload.Limit, payload.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o Overdraft
+		err = rows.Scan(&pagination.Total, &o.ID, &o.UserID, &o.Currency, &o.LowestBalance, &o.StartedAt, &o.EndedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp = append(resp, o)
+	}
+
+	return resp, pagination, rows.Err()
+}
+
+// ClaimTransfers implements Repository.
+// Claimed transfers are leased so that concurrent processors do not pick them up again until the lease expires.
+func (d *dbRepository) ClaimTransfers(ctx context.Context, status TransferStatus, limit int, lease time.Duration) ([]OutgoingTransfer, error) {
+	var resp []OutgoingTransfer
+
+	claimQuery := `
+		UPDATE outgoing_transfers
+		SET locked_until = current_timestamp + $3 * interval '1 millisecond'
+		WHERE transaction_id IN (
+			SELECT transaction_id
+			FROM outgoing_transfers
+			WHERE status = $1 AND (locked_until IS NULL OR locked_until < current_timestamp)
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING transaction_id, user_id, amount, currency, bank_account_number, bank_name, status, gateway_reference, failure_reason, refund_transaction_id, export_id, created_at, updated_at
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, claimQuery, status, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ot OutgoingTransfer
+		err = rows.Scan(&ot.TransactionID, &ot.UserID, &ot.Amount, &ot.Currency, &ot.BankAccountNumber, &ot.BankName, &ot.Status, &ot.GatewayReference, &ot.FailureReason, &ot.RefundTransactionID, &ot.ExportID, &ot.CreatedAt, &ot.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, ot)
+	}
+
+	return resp, rows.Err()
+}
+
+// MarkTransferSubmitted implements Repository.
+func (d *dbRepository) MarkTransferSubmitted(ctx context.Context, transactionID, gatewayReference string) error {
+	updateQuery := `
+		UPDATE outgoing_transfers
+		SET status = $1, gateway_reference = $2, locked_until = NULL, updated_at = current_timestamp
+		WHERE transaction_id = $3 AND status = $4
+	`
+	return execTransition(d.db.DB().ExecContext(ctx, updateQuery, TransferStatusSubmitted, gatewayReference, transactionID, TransferStatusPending))
+}
+
+// MarkTransferSettled implements Repository.
+func (d *dbRepository) MarkTransferSettled(ctx context.Context, transactionID string) error {
+	updateQuery := `
+		UPDATE outgoing_transfers
+		SET status = $1, locked_until = NULL, updated_at = current_timestamp
+		WHERE transaction_id = $2 AND status = $3
+	`
+	return execTransition(d.db.DB().ExecContext(ctx, updateQuery, TransferStatusSettled, transactionID, TransferStatusSubmitted))
+}
+
+// FailTransfer implements Repository.
+// The transfer is marked failed and its amount is refunded to the user's balance with a new transaction.
+func (d *dbRepository) FailTransfer(ctx context.Context, transfer OutgoingTransfer, reason string) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		return failTransfer(ctx, tx, transfer, reason)
+	})
+}
+
+// FailUnclaimedTransfer implements Repository.
+// A transfer a processor holds is refused, since it may already be with the gateway. The row stays
+// locked until the refund commits, so no processor can claim it in between.
+func (d *dbRepository) FailUnclaimedTransfer(ctx context.Context, transfer OutgoingTransfer, reason string) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		claimedQuery := `
+			SELECT COALESCE(locked_until >= current_timestamp, false)
+			FROM outgoing_transfers
+			WHERE transaction_id = $1
+			FOR UPDATE
+		`
+		var claimed bool
+		err := tx.QueryRowContext(ctx, claimedQuery, transfer.TransactionID).Scan(&claimed)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTransferNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if claimed {
+			return ErrTransferInProgress
+		}
+		return failTransfer(ctx, tx, transfer, reason)
+	})
+}
+
+// failTransfer marks the transfer failed and refunds it within tx.
+func failTransfer(ctx context.Context, tx *sql.Tx, transfer OutgoingTransfer, reason string) error {
+	refundID := id.GenerateStringID(16)
+	updateQuery := `
+		UPDATE outgoing_transfers
+		SET status = $1, failure_reason = $2, refund_transaction_id = $3, locked_until = NULL, updated_at = current_timestamp
+		WHERE transaction_id = $4 AND status = $5
+	`
+	err := execTransition(tx.ExecContext(ctx, updateQuery, TransferStatusFailed, reason, refundID, transfer.TransactionID, transfer.Status))
+	if err != nil {
+		return err
+	}
+
+	err = lockUser(ctx, tx, transfer.UserID)
+	if err != nil {
+		return err
+	}
+
+	refundBalanceQuery := `
+		UPDATE user_balance
+		SET balance = balance + $1
+		WHERE user_id = $2 and currency = $3
+		RETURNING id, balance
+	`
+	row := tx.QueryRowContext(ctx, refundBalanceQuery, transfer.Amount, transfer.UserID, transfer.Currency)
+	var userBalanceID uint64
+	var balance int
+	err = row.Scan(&userBalanceID, &balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoCurrencyOrUserRecorded
+	}
+	if err != nil {
+		return err
+	}
+
+	err = trackOverdraft(ctx, tx, userBalanceID, transfer.UserID, transfer.Currency, balance)
+	if err != nil {
+		return err
+	}
+
+	return insertChainedTransaction(ctx, tx, &UserTransaction{
+		TransactionID:     refundID,
+		UserID:            transfer.UserID,
+		Amount:            transfer.Amount,
+		Currency:          transfer.Currency,
+		BankAccountNumber: transfer.BankAccountNumber,
+		BankName:          transfer.BankName,
+	})
+}
+
+// GetTransfer implements Repository.
+func (d *dbRepository) GetTransfer(ctx context.Context, transactionID string) (*OutgoingTransfer, error) {
+	selectQuery := `
+		SELECT transaction_id, user_id, amount, currency, bank_account_number, bank_name, status, gateway_reference, failure_reason, refund_transaction_id, export_id, created_at, updated_at
+		FROM outgoing_transfers
+		WHERE transaction_id = $1
+	`
+	row := d.db.DB().QueryRowContext(ctx, selectQuery, transactionID)
+	ot := &OutgoingTransfer{}
+	err := row.Scan(&ot.TransactionID, &ot.UserID, &ot.Amount, &ot.Currency, &ot.BankAccountNumber, &ot.BankName, &ot.Status, &ot.GatewayReference, &ot.FailureReason, &ot.RefundTransactionID, &ot.ExportID, &ot.CreatedAt, &ot.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ot, nil
+}
+
+// ExportTransfers implements Repository.
+// Pending transfers that were never exported are locked, passed to build, stored as a payment export
+// and marked submitted in the same transaction, so a transfer can only ever be part of one export.
+func (d *dbRepository) ExportTransfers(ctx context.Context, limit int, build func([]OutgoingTransfer) (*iso20022.Pain001, error)) (*PaymentExport, error) {
+	var export *PaymentExport
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		selectQuery := `
+			SELECT transaction_id, user_id, amount, currency, bank_account_number, bank_name, status, created_at, updated_at
+			FROM outgoing_transfers
+			WHERE status = $1 AND export_id IS NULL AND (locked_until IS NULL OR locked_until < current_timestamp)
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		`
+		rows, err := tx.QueryContext(ctx, selectQuery, TransferStatusPending, limit)
+		if err != nil {
+			return err
+		}
+		var transfers []OutgoingTransfer
+		for rows.Next() {
+			var ot OutgoingTransfer
+			err = rows.Scan(&ot.TransactionID, &ot.UserID, &ot.Amount, &ot.Currency, &ot.BankAccountNumber, &ot.BankName, &ot.Status, &ot.CreatedAt, &ot.UpdatedAt)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			transfers = append(transfers, ot)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		if len(transfers) == 0 {
+			return ErrNoTransfersToExport
+		}
+
+		pain001, err := build(transfers)
+		if err != nil {
+			return err
+		}
+
+		createExportQuery := `
+			INSERT INTO payment_exports (
+				message_id, number_of_transactions, control_sum, document
+			) VALUES (
+				$1, $2, $3, $4
+			)
+			RETURNING id, created_at
+		`
+		export = &PaymentExport{
+			MessageID:            pain001.MessageID,
+			NumberOfTransactions: pain001.NumberOfTransactions,
+			ControlSum:           pain001.ControlSum,
+			Document:             pain001.Document,
+		}
+		row := tx.QueryRowContext(ctx, createExportQuery, export.MessageID, export.NumberOfTransactions, export.ControlSum, string(export.Document))
+		err = row.Scan(&export.ID, &export.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		for _, ot := range transfers {
+			updateQuery := `
+				UPDATE outgoing_transfers
+				SET status = $1, export_id = $2, gateway_reference = $3, updated_at = current_timestamp
+				WHERE transaction_id = $4 AND status = $5 AND export_id IS NULL
+			`
+			err = execTransition(tx.ExecContext(ctx, updateQuery, TransferStatusSubmitted, export.ID, export.MessageID, ot.TransactionID, TransferStatusPending))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// GetPaymentExport implements Repository.
+func (d *dbRepository) GetPaymentExport(ctx context.Context, messageID string) (*PaymentExport, error) {
+	selectQuery := `
+		SELECT id, message_id, number_of_transactions, control_sum, document, created_at
+		FROM payment_exports
+		WHERE message_id = $1
+	`
+	row := d.db.DB().QueryRowContext(ctx, selectQuery, messageID)
+	pe := &PaymentExport{}
+	var document string
+	err := row.Scan(&pe.ID, &pe.MessageID, &pe.NumberOfTransactions, &pe.ControlSum, &document, &pe.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPaymentExportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	pe.Document = []byte(document)
+	return pe, nil
+}
+
+// CreateDeposit implements Repository.
+func (d *dbRepository) CreateDeposit(ctx context.Context, deposit *Deposit) error {
+	createDepositQuery := `
+		INSERT INTO deposits (
+			id, reference, user_id, amount, currency, sender_bank_account_number, sender_bank_name, status
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8
+		)
+		RETURNING created_at
+	`
+	row := d.db.DB().QueryRowContext(ctx, createDepositQuery, deposit.ID, deposit.Reference, deposit.UserID, deposit.Amount, deposit.Currency, deposit.SenderBankAccountNumber, deposit.SenderBankName, deposit.Status)
+	return row.Scan(&deposit.CreatedAt)
+}
+
+// ListDeposits implements Repository.
+func (d *dbRepository) ListDeposits(ctx context.Context, payload ListDepositPayload) ([]Deposit, *response.Pagination, error) {
+	var resp []Deposit
+	pagination := &response.Pagination{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	}
+
+	selectQuery := `
+		SELECT COUNT(*) OVER() AS total_count, id, reference, user_id, amount, currency, sender_bank_account_number, sender_bank_name, status, transaction_id, created_at, confirmed_at
+		FROM deposits
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+		OFFSET $3
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, payload.UserID, payload.Limit, payload.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dp Deposit
+		err = rows.Scan(&pagination.Total, &dp.ID, &dp.Reference, &dp.UserID, &dp.Amount, &dp.Currency, &dp.SenderBankAccountNumber, &dp.SenderBankName, &dp.Status, &dp.TransactionID, &dp.CreatedAt, &dp.ConfirmedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp = append(resp, dp)
+	}
+
+	return resp, pagination, rows.Err()
+}
+
+// ImportStatement implements Repository.
+// The statement and its credit lines are stored as unmatched, ready to be matched against deposits.
+func (d *dbRepository) ImportStatement(ctx context.Context, statement *BankStatement, lines []StatementLine) ([]StatementLine, error) {
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		createStatementQuery := `
+			INSERT INTO bank_statements (
+				format, statement_id, account
+			) VALUES (
+				$1, $2, $3
+			)
+			RETURNING id, imported_at
+		`
+		row := tx.QueryRowContext(ctx, createStatementQuery, statement.Format, statement.StatementID, statement.Account)
+		err := row.Scan(&statement.ID, &statement.ImportedAt)
+		var pgErr *pgconn.PgError
+		if err != nil {
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return ErrStatementAlreadyImported
+			}
+			return err
+		}
+
+		createLineQuery := `
+			INSERT INTO bank_statement_lines (
+				statement_id, entry_reference, booking_date, amount, amount_valid, currency, reference, sender_account, sender_name, status
+			) VALUES (
+				$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			)
+			RETURNING id, created_at
+		`
+		for i := range lines {
+			lines[i].StatementID = statement.ID
+			lines[i].Status = StatementLineStatusUnmatched
+			l := lines[i]
+			row := tx.QueryRowContext(ctx, createLineQuery, l.StatementID, l.EntryReference, l.BookingDate, l.Amount, l.AmountValid, l.Currency, l.Reference, l.SenderAccount, l.SenderName, l.Status)
+			err = row.Scan(&lines[i].ID, &lines[i].CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// MatchStatementLine implements Repository.
+// It looks for a pending deposit with the same amount and currency whose reference appears on the line
+// and, when the line names a sender account, the same sender account. A match is confirmed right away.
+func (d *dbRepository) MatchStatementLine(ctx context.Context, line StatementLine) (bool, error) {
+	if !line.AmountValid {
+		return false, nil
+	}
+	matched := false
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		selectQuery := `
+			SELECT id, reference, user_id, amount, currency, sender_bank_account_number, sender_bank_name, status, created_at
+			FROM deposits
+			WHERE status = $1 AND amount = $2 AND currency = $3
+				AND position(reference in upper($4)) > 0
+				AND ($5 = '' OR upper(regexp_replace(sender_bank_account_number, '[\s-]', '', 'g')) = $5)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		`
+		row := tx.QueryRowContext(ctx, selectQuery, DepositStatusPending, line.Amount, line.Currency, line.Reference, line.SenderAccount)
+		var dp Deposit
+		err := row.Scan(&dp.ID, &dp.Reference, &dp.UserID, &dp.Amount, &dp.Currency, &dp.SenderBankAccountNumber, &dp.SenderBankName, &dp.Status, &dp.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = confirmDeposit(ctx, tx, line.ID, dp)
+		if err != nil {
+			return err
+		}
+		matched = true
+		return nil
+	})
+	return matched, err
+}
+
+// ConfirmDepositWithLine implements Repository.
+func (d *dbRepository) ConfirmDepositWithLine(ctx context.Context, lineID uint64, depositID string) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		selectLineQuery := `
+			SELECT currency
+			FROM bank_statement_lines
+			WHERE id = $1 AND status = $2
+			FOR UPDATE
+		`
+		var lineCurrency string
+		err := tx.QueryRowContext(ctx, selectLineQuery, lineID, StatementLineStatusUnmatched).Scan(&lineCurrency)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrStatementLineNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		selectDepositQuery := `
+			SELECT id, reference, user_id, amount, currency, sender_bank_account_number, sender_bank_name, status, created_at
+			FROM deposits
+			WHERE id = $1 AND status = $2
+			FOR UPDATE
+		`
+		row := tx.QueryRowContext(ctx, selectDepositQuery, depositID, DepositStatusPending)
+		var dp Deposit
+		err = row.Scan(&dp.ID, &dp.Reference, &dp.UserID, &dp.Amount, &dp.Currency, &dp.SenderBankAccountNumber, &dp.SenderBankName, &dp.Status, &dp.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDepositNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if dp.Currency != lineCurrency {
+			return ErrDepositCurrencyMismatch
+		}
+
+		return confirmDeposit(ctx, tx, lineID, dp)
+	})
+}
+
+// confirmDeposit credits the amount booked on the statement line to the deposit's user
+// and links the line and the deposit to each other.
+func confirmDeposit(ctx context.Context, tx *sql.Tx, lineID uint64, dp Deposit) error {
+	updateLineQuery := `
+		UPDATE bank_statement_lines
+		SET status = $1, deposit_id = $2, resolved_at = current_timestamp
+		WHERE id = $3 AND status = $4
+		RETURNING amount
+	`
+	var amount int
+	err := tx.QueryRowContext(ctx, updateLineQuery, StatementLineStatusMatched, dp.ID, lineID, StatementLineStatusUnmatched).Scan(&amount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrStatementLineNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = creditBalance(ctx, tx, dp.UserID, dp.Currency, amount)
+	if err != nil {
+		return err
+	}
+
+	ut := &UserTransaction{
+		TransactionID:     id.GenerateStringID(16),
+		UserID:            dp.UserID,
+		Amount:            amount,
+		Currency:          dp.Currency,
+		BankAccountNumber: dp.SenderBankAccountNumber,
+		BankName:          dp.SenderBankName,
+	}
+	err = insertChainedTransaction(ctx, tx, ut)
+	if err != nil {
+		return err
+	}
+
+	updateDepositQuery := `
+		UPDATE deposits
+		SET status = $1, transaction_id = $2, confirmed_at = current_timestamp
+		WHERE id = $3
+	`
+	_, err = tx.ExecContext(ctx, updateDepositQuery, DepositStatusConfirmed, ut.TransactionID, dp.ID)
+	return err
+}
+
+// DismissStatementLine implements Repository.
+func (d *dbRepository) DismissStatementLine(ctx context.Context, lineID uint64, note string) error {
+	updateQuery := `
+		UPDATE bank_statement_lines
+		SET status = $1, note = $2, resolved_at = current_timestamp
+		WHERE id = $3 AND status = $4
+	`
+	err := execTransition(d.db.DB().ExecContext(ctx, updateQuery, StatementLineStatusDismissed, note, lineID, StatementLineStatusUnmatched))
+	if errors.Is(err, ErrInvalidTransferTransition) {
+		return ErrStatementLineNotFound
+	}
+	return err
+}
+
+// ListStatementLines implements Repository.
+func (d *dbRepository) ListStatementLines(ctx context.Context, payload ListStatementLinePayload) ([]StatementLine, *response.Pagination, error) {
+	var resp []StatementLine
+	pagination := &response.Pagination{
+		Limit:  payload.Limit,
+		Offset: payload.Offset,
+	}
+
+	selectQuery := `
+		SELECT COUNT(*) OVER() AS total_count, id, statement_id, entry_reference, booking_date, amount, amount_valid, currency, reference, sender_account, sender_name, status, deposit_id, note, created_at, resolved_at
+		FROM bank_statement_lines
+		WHERE status = $1
+		ORDER BY created_at ASC
+		LIMIT $2
+		OFFSET $3
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, payload.Status, payload.Limit, payload.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l StatementLine
+		err = rows.Scan(&pagination.Total, &l.ID, &l.StatementID, &l.EntryReference, &l.BookingDate, &l.Amount, &l.AmountValid, &l.Currency, &l.Reference, &l.SenderAccount, &l.SenderName, &l.Status, &l.DepositID, &l.Note, &l.CreatedAt, &l.ResolvedAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp = append(resp, l)
+	}
+
+	return resp, pagination, rows.Err()
+}
+
+// CreateVirtualAccount implements Repository.
+// The user row is locked while counting so concurrent requests cannot exceed limit.
+func (d *dbRepository) CreateVirtualAccount(ctx context.Context, va *VirtualAccount, limit int) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		err := lockUser(ctx, tx, va.UserID)
+		if err != nil {
+			return err
+		}
+
+		countQuery := `
+			SELECT COUNT(*)
+			FROM virtual_accounts
+			WHERE user_id = $1 AND currency = $2
+		`
+		var count int
+		err = tx.QueryRowContext(ctx, countQuery, va.UserID, va.Currency).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count >= limit {
+			return ErrVirtualAccountLimit
+		}
+
+		createQuery := `
+			INSERT INTO virtual_accounts (
+				account_number, user_id, currency
+			) VALUES (
+				$1, $2, $3
+			)
+			RETURNING created_at
+		`
+		err = tx.QueryRowContext(ctx, createQuery, va.AccountNumber, va.UserID, va.Currency).Scan(&va.CreatedAt)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrVirtualAccountExists
+		}
+		return err
+	})
+}
+
+// ListVirtualAccounts implements Repository.
+func (d *dbRepository) ListVirtualAccounts(ctx context.Context, userID string) ([]VirtualAccount, error) {
+	resp := []VirtualAccount{}
+
+	selectQuery := `
+		SELECT account_number, user_id, currency, created_at
+		FROM virtual_accounts
+		WHERE user_id = $1 AND disabled_at IS NULL
+		ORDER BY currency, created_at
+	`
+
+	rows, err := d.db.DB().QueryContext(ctx, selectQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var va VirtualAccount
+		err = rows.Scan(&va.AccountNumber, &va.UserID, &va.Currency, &va.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp = append(resp, va)
+	}
+
+	return resp, rows.Err()
+}
+
+// GetVirtualAccount implements Repository.
+// Virtual accounts disabled when their user closed the account are reported as ErrVirtualAccountNotFound.
+func (d *dbRepository) GetVirtualAccount(ctx context.Context, accountNumber string) (*VirtualAccount, error) {
+	selectQuery := `
+		SELECT account_number, user_id, currency, created_at
+		FROM virtual_accounts
+		WHERE account_number = $1 AND disabled_at IS NULL
+	`
+	row := d.db.DB().QueryRowContext(ctx, selectQuery, accountNumber)
+	va := &VirtualAccount{}
+	err := row.Scan(&va.AccountNumber, &va.UserID, &va.Currency, &va.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrVirtualAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return va, nil
+}
+
+// RecordInternalTransfer implements Repository.
+func (d *dbRepository) RecordInternalTransfer(ctx context.Context, transfer *InternalTransfer) error {
+	return d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		return RecordInternalTransfer(ctx, tx, transfer)
+	})
+}
+
+// GetAccountName implements Repository.
+func (d *dbRepository) GetAccountName(ctx context.Context, userID string) (string, error) {
+	selectQuery := `
+		SELECT name
+		FROM users
+		WHERE id = $1 AND closed_at IS NULL
+	`
+	var name string
+	err := d.db.DB().QueryRowContext(ctx, selectQuery, userID).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNoCurrencyOrUserRecorded
+	}
+	return name, err
+}
+
+// execTransition turns a conditional status update that matched no row into ErrInvalidTransferTransition.
+func execTransition(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInvalidTransferTransition
+	}
+	return nil
+}
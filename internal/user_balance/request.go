@@ -1,8 +1,10 @@
 package userbalance
 
 import (
+	"errors"
 	"regexp"
 
+	"github.com/citadel-corp/paimon-bank/internal/statement"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 )
@@ -38,6 +40,7 @@ type CreateTransactionPayload struct {
 	RecipientBankName          string `json:"recipientBankName"`
 	Balances                   int    `json:"balances"`
 	FromCurrency               string `json:"fromCurrency"`
+	PIN                        string `json:"pin"`
 	UserID                     string
 }
 
@@ -47,9 +50,24 @@ func (p CreateTransactionPayload) Validate() error {
 		validation.Field(&p.RecipientBankName, validation.Required, validation.Length(5, 30)),
 		validation.Field(&p.Balances, validation.Required, validation.Min(0)),
 		validation.Field(&p.FromCurrency, validation.Required, is.CurrencyCode),
+		validation.Field(&p.PIN, PINRules...),
 	)
 }
 
+// ConfirmTransactionPayload confirms a challenged transfer with exactly one step-up factor.
+type ConfirmTransactionPayload struct {
+	StepUpFactor
+	ChallengeID string `json:"-"`
+	UserID      string `json:"-"`
+}
+
+func (p ConfirmTransactionPayload) Validate() error {
+	if p.given() != 1 {
+		return errors.New("exactly one of password, code or recoveryCode is required")
+	}
+	return nil
+}
+
 type ListUserBalancePayload struct {
 	UserID string
 }
@@ -59,3 +77,172 @@ type ListUserTransactionPayload struct {
 	Limit  int
 	Offset int
 }
+
+type VerifyChainPayload struct {
+	UserID string
+}
+
+type SetOverdraftLimitPayload struct {
+	UserID   string `json:"userId"`
+	Currency string `json:"currency"`
+	Limit    int    `json:"limit"`
+}
+
+func (p SetOverdraftLimitPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.UserID, validation.Required, is.Digit),
+		validation.Field(&p.Currency, validation.Required, is.CurrencyCode),
+		validation.Field(&p.Limit, validation.Min(0)),
+	)
+}
+
+type ListOverdraftPayload struct {
+	UserID string
+	Limit  int
+	Offset int
+}
+
+type ExportTransfersPayload struct {
+	Limit int
+}
+
+type GetPaymentExportPayload struct {
+	MessageID string
+}
+
+type UpdateTransferStatusPayload struct {
+	TransactionID string         `json:"-"`
+	Status        TransferStatus `json:"status"`
+	Reason        string         `json:"reason"`
+}
+
+func (p UpdateTransferStatusPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.TransactionID, validation.Required, validation.Length(16, 16)),
+		validation.Field(&p.Status, validation.Required, validation.In(TransferStatusSettled, TransferStatusFailed)),
+		validation.Field(&p.Reason, validation.When(p.Status == TransferStatusFailed, validation.Required), validation.Length(0, 255)),
+	)
+}
+
+type CreateDepositPayload struct {
+	SenderBankAccountNumber string `json:"senderBankAccountNumber"`
+	SenderBankName          string `json:"senderBankName"`
+	Amount                  int    `json:"amount"`
+	Currency                string `json:"currency"`
+	UserID                  string
+}
+
+func (p CreateDepositPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.SenderBankAccountNumber, validation.Required, validation.Length(5, 30)),
+		validation.Field(&p.SenderBankName, validation.Required, validation.Length(5, 30)),
+		validation.Field(&p.Amount, validation.Required, validation.Min(0)),
+		validation.Field(&p.Currency, validation.Required, is.CurrencyCode),
+	)
+}
+
+type ListDepositPayload struct {
+	UserID string
+	Limit  int
+	Offset int
+}
+
+type ImportStatementPayload struct {
+	Format statement.Format
+	Data   []byte
+}
+
+func (p ImportStatementPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Format, validation.Required, validation.In(statement.FormatCAMT053, statement.FormatMT940)),
+		validation.Field(&p.Data, validation.Required),
+	)
+}
+
+type ListStatementLinePayload struct {
+	Status StatementLineStatus
+	Limit  int
+	Offset int
+}
+
+type ResolveStatementLinePayload struct {
+	LineID    uint64 `json:"-"`
+	Action    string `json:"action"`
+	DepositID string `json:"depositId"`
+	Note      string `json:"note"`
+}
+
+func (p ResolveStatementLinePayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Action, validation.Required, validation.In("match", "dismiss")),
+		validation.Field(&p.DepositID, validation.When(p.Action == "match", validation.Required, validation.Length(16, 16))),
+		validation.Field(&p.Note, validation.When(p.Action == "dismiss", validation.Required), validation.Length(0, 255)),
+	)
+}
+
+type CreateVirtualAccountPayload struct {
+	Currency string `json:"currency"`
+	UserID   string
+}
+
+func (p CreateVirtualAccountPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Currency, validation.Required, is.CurrencyCode),
+	)
+}
+
+type ListVirtualAccountPayload struct {
+	UserID string
+}
+
+type InboundTransferPayload struct {
+	VirtualAccountNumber    string `json:"virtualAccountNumber"`
+	Amount                  int    `json:"amount"`
+	SenderBankAccountNumber string `json:"senderBankAccountNumber"`
+	SenderBankName          string `json:"senderBankName"`
+}
+
+func (p InboundTransferPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.VirtualAccountNumber, validation.Required, is.Digit, validation.Length(2, 20)),
+		validation.Field(&p.Amount, validation.Required, validation.Min(0)),
+		validation.Field(&p.SenderBankAccountNumber, validation.Required, validation.Length(5, 30)),
+		validation.Field(&p.SenderBankName, validation.Required, validation.Length(5, 30)),
+	)
+}
+
+type GenerateQRPayload struct {
+	Currency  string
+	Amount    int
+	Reference string
+	UserID    string
+}
+
+func (p GenerateQRPayload) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Currency, validation.Required, is.CurrencyCode),
+		validation.Field(&p.Amount, validation.Min(0)),
+		validation.Field(&p.Reference, is.Alphanumeric, validation.Length(0, 25)),
+	)
+}
+
+// PayQRPayload pays a QR code; payments at or above the step-up threshold also carry a step-up factor.
+type PayQRPayload struct {
+	StepUpFactor
+	Payload string `json:"payload"`
+	Amount  int    `json:"amount"`
+	PIN     string `json:"pin"`
+	UserID  string
+}
+
+func (p PayQRPayload) Validate() error {
+	err := validation.ValidateStruct(&p,
+		validation.Field(&p.Payload, validation.Required, validation.Length(0, 512)),
+		validation.Field(&p.Amount, validation.Min(0)),
+		validation.Field(&p.PIN, PINRules...),
+	)
+	if err != nil {
+		return err
+	}
+	return p.StepUpFactor.Validate()
+}
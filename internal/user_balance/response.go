@@ -1,6 +1,10 @@
 package userbalance
 
-import "github.com/citadel-corp/paimon-bank/internal/common/response"
+import (
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/response"
+)
 
 type Response struct {
 	Code    int
@@ -12,23 +16,135 @@ type Response struct {
 
 var (
 	SuccessCreateBalance     = Response{Code: 200, Message: "Balance added successfully"}
-	SuccessCreateTransaction = Response{Code: 200, Message: "Transaction successful"}
+	SuccessCreateTransaction = Response{Code: 200, Message: "Transaction accepted"}
+	SuccessSetOverdraftLimit = Response{Code: 200, Message: "Overdraft limit updated successfully"}
+	SuccessExportTransfers   = Response{Code: 201, Message: "Transfers exported successfully"}
+	SuccessUpdateTransfer    = Response{Code: 200, Message: "Transfer status updated successfully"}
+	SuccessCreateDeposit     = Response{Code: 201, Message: "Deposit registered, transfer the amount with the reference"}
+	SuccessImportStatement   = Response{Code: 201, Message: "Statement imported successfully"}
+	SuccessResolveStatement  = Response{Code: 200, Message: "Statement line resolved successfully"}
+	SuccessCreateVA          = Response{Code: 201, Message: "Virtual account created successfully"}
+	SuccessInboundTransfer   = Response{Code: 200, Message: "Inbound transfer credited successfully"}
+	SuccessPayQR             = Response{Code: 200, Message: "QR payment successful"}
+	SuccessStepUpRequired    = Response{Code: 202, Message: "Confirm the transaction with one of the listed factors"}
 	Success                  = Response{Code: 200, Message: "success"}
 )
 
 type UserBalanceResponse struct {
-	Balance  int    `json:"balance"`
-	Currency string `json:"currency"`
+	Balance        int    `json:"balance"`
+	Currency       string `json:"currency"`
+	OverdraftLimit int    `json:"overdraftLimit"`
+	OverdrawnSince *int64 `json:"overdrawnSince"`
+}
+
+type OverdraftResponse struct {
+	Currency        string `json:"currency"`
+	LowestBalance   int    `json:"lowestBalance"`
+	StartedAt       int64  `json:"startedAt"`
+	EndedAt         *int64 `json:"endedAt"`
+	DurationSeconds int64  `json:"durationSeconds"`
 }
 
 type UserTransactionResponse struct {
-	TransactionID    string `json:"transactionId"`
-	Balance          int    `json:"balance"`
-	Currency         string `json:"currency"`
-	TransferProofImg string `json:"transferProofImg"`
-	CreatedAt        int64  `json:"createdAt"`
+	TransactionID    string         `json:"transactionId"`
+	Balance          int            `json:"balance"`
+	Currency         string         `json:"currency"`
+	TransferProofImg string         `json:"transferProofImg"`
+	Status           TransferStatus `json:"status,omitempty"`
+	CreatedAt        int64          `json:"createdAt"`
 	Source           struct {
 		BankAccountNumber string `json:"bankAccountNumber"`
 		BankName          string `json:"bankName"`
 	} `json:"source"`
 }
+
+type TransferResponse struct {
+	TransactionID string         `json:"transactionId"`
+	Status        TransferStatus `json:"status"`
+}
+
+// StepUpChallengeResponse is returned instead of a transfer when the amount needs step-up confirmation.
+type StepUpChallengeResponse struct {
+	ChallengeID string    `json:"challengeId"`
+	Factors     []string  `json:"factors"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+type ChainVerificationResponse struct {
+	UserID     string              `json:"userId"`
+	Checked    int                 `json:"checked"`
+	Valid      bool                `json:"valid"`
+	BrokenLink *BrokenLinkResponse `json:"brokenLink,omitempty"`
+}
+
+type BrokenLinkResponse struct {
+	TransactionID string `json:"transactionId"`
+	ChainSeq      int64  `json:"chainSeq"`
+	Reason        string `json:"reason"`
+}
+
+type DepositResponse struct {
+	DepositID               string        `json:"depositId"`
+	Reference               string        `json:"reference"`
+	Amount                  int           `json:"amount"`
+	Currency                string        `json:"currency"`
+	SenderBankAccountNumber string        `json:"senderBankAccountNumber"`
+	SenderBankName          string        `json:"senderBankName"`
+	Status                  DepositStatus `json:"status"`
+	TransactionID           *string       `json:"transactionId"`
+	CreatedAt               int64         `json:"createdAt"`
+	ConfirmedAt             *int64        `json:"confirmedAt"`
+}
+
+type StatementImportResponse struct {
+	StatementID uint64 `json:"statementId"`
+	Account     string `json:"account"`
+	Credits     int    `json:"credits"`
+	Matched     int    `json:"matched"`
+	Unmatched   int    `json:"unmatched"`
+}
+
+type StatementLineResponse struct {
+	ID             uint64              `json:"id"`
+	StatementID    uint64              `json:"statementId"`
+	EntryReference string              `json:"entryReference"`
+	BookingDate    string              `json:"bookingDate"`
+	Amount         int                 `json:"amount"`
+	AmountValid    bool                `json:"amountValid"`
+	Currency       string              `json:"currency"`
+	Reference      string              `json:"reference"`
+	SenderAccount  string              `json:"senderAccount"`
+	SenderName     string              `json:"senderName"`
+	Status         StatementLineStatus `json:"status"`
+	DepositID      *string             `json:"depositId"`
+	Note           *string             `json:"note"`
+	CreatedAt      int64               `json:"createdAt"`
+}
+
+type VirtualAccountResponse struct {
+	AccountNumber string `json:"accountNumber"`
+	Currency      string `json:"currency"`
+	CreatedAt     int64  `json:"createdAt"`
+}
+
+type InboundTransferResponse struct {
+	VirtualAccountNumber string `json:"virtualAccountNumber"`
+	Amount               int    `json:"amount"`
+	Currency             string `json:"currency"`
+}
+
+type QRResponse struct {
+	Payload  string `json:"payload"`
+	Dynamic  bool   `json:"dynamic"`
+	Amount   *int   `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type QRPaymentResponse struct {
+	TransactionID string `json:"transactionId"`
+	RecipientID   string `json:"recipientId"`
+	RecipientName string `json:"recipientName"`
+	Amount        int    `json:"amount"`
+	Currency      string `json:"currency"`
+	Reference     string `json:"reference,omitempty"`
+}
@@ -4,25 +4,75 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/common/id"
+	"github.com/citadel-corp/paimon-bank/internal/currency"
+	"github.com/citadel-corp/paimon-bank/internal/iso20022"
+	"github.com/citadel-corp/paimon-bank/internal/qris"
+	"github.com/citadel-corp/paimon-bank/internal/statement"
 )
 
 type Service interface {
 	Create(ctx context.Context, req CreateUserBalancePayload) Response
+	// CreateTransaction records a transfer, or returns a step-up challenge when the amount is at or above the threshold.
 	CreateTransaction(ctx context.Context, req CreateTransactionPayload) Response
+	// ConfirmTransaction records a challenged transfer once the step-up factor is verified.
+	ConfirmTransaction(ctx context.Context, req ConfirmTransactionPayload) Response
 	List(ctx context.Context, req ListUserBalancePayload) Response
 	ListTransaction(ctx context.Context, req ListUserTransactionPayload) Response
+	VerifyChain(ctx context.Context, req VerifyChainPayload) Response
+	SetOverdraftLimit(ctx context.Context, req SetOverdraftLimitPayload) Response
+	ListOverdraft(ctx context.Context, req ListOverdraftPayload) Response
+	ExportTransfers(ctx context.Context, req ExportTransfersPayload) Response
+	GetPaymentExport(ctx context.Context, req GetPaymentExportPayload) Response
+	UpdateTransferStatus(ctx context.Context, req UpdateTransferStatusPayload) Response
+	CreateDeposit(ctx context.Context, req CreateDepositPayload) Response
+	ListDeposit(ctx context.Context, req ListDepositPayload) Response
+	ImportStatement(ctx context.Context, req ImportStatementPayload) Response
+	ListStatementLine(ctx context.Context, req ListStatementLinePayload) Response
+	ResolveStatementLine(ctx context.Context, req ResolveStatementLinePayload) Response
+	CreateVirtualAccount(ctx context.Context, req CreateVirtualAccountPayload) Response
+	ListVirtualAccount(ctx context.Context, req ListVirtualAccountPayload) Response
+	ReceiveInboundTransfer(ctx context.Context, req InboundTransferPayload) Response
+	GenerateQR(ctx context.Context, req GenerateQRPayload) Response
+	PayQR(ctx context.Context, req PayQRPayload) Response
 }
 
+var (
+	pain001Debtor = iso20022.Debtor{
+		Name:    os.Getenv("PAIN001_DEBTOR_NAME"),
+		Account: os.Getenv("PAIN001_DEBTOR_ACCOUNT"),
+		BIC:     os.Getenv("PAIN001_DEBTOR_BIC"),
+	}
+	qrisMerchantCity = os.Getenv("QRIS_MERCHANT_CITY")
+)
+
 type userBalanceService struct {
-	repository Repository
+	repository      Repository
+	currencyService currency.Service
+	stepUp          StepUpPolicy
+	pins            PINVerifier
 }
 
-func NewService(repository Repository) Service {
-	return &userBalanceService{repository: repository}
+func NewService(repository Repository, currencyService currency.Service, stepUp StepUpPolicy, pins PINVerifier) Service {
+	return &userBalanceService{repository: repository, currencyService: currencyService, stepUp: stepUp, pins: pins}
 }
 
 func (s *userBalanceService) Create(ctx context.Context, req CreateUserBalancePayload) Response {
+	if resp, ok := s.validateCurrency(ctx, req.Currency, req.AddedBalance); !ok {
+		return resp
+	}
+
 	err := s.repository.RecordBalance(ctx, req)
+	if errors.Is(err, ErrAccountClosed) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
 	if err != nil {
 		resp := ErrorInternal
 		resp.Error = err.Error()
@@ -34,7 +84,123 @@ func (s *userBalanceService) Create(ctx context.Context, req CreateUserBalancePa
 
 // CreateTransaction implements Service.
 func (s *userBalanceService) CreateTransaction(ctx context.Context, req CreateTransactionPayload) Response {
-	err := s.repository.RecordTransaction(ctx, req)
+	if resp, ok := s.validateCurrency(ctx, req.FromCurrency, req.Balances); !ok {
+		return resp
+	}
+	if resp, ok := s.verifyPIN(ctx, req.UserID, req.PIN); !ok {
+		return resp
+	}
+	if s.stepUp.required(req.FromCurrency, req.Balances) {
+		return s.challengeTransaction(ctx, req)
+	}
+
+	transfer, err := s.repository.RecordTransaction(ctx, req)
+	return transferResponse(transfer, err)
+}
+
+// challengeTransaction holds the transfer until the user confirms it with a step-up factor.
+func (s *userBalanceService) challengeTransaction(ctx context.Context, req CreateTransactionPayload) Response {
+	factors, err := s.stepUp.Verifier.StepUpFactors(ctx, req.UserID)
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+	challenge := &TransferChallenge{
+		ID:                         id.GenerateStringID(16),
+		UserID:                     req.UserID,
+		Amount:                     req.Balances,
+		Currency:                   req.FromCurrency,
+		RecipientBankAccountNumber: req.RecipientBankAccountNumber,
+		RecipientBankName:          req.RecipientBankName,
+		ExpiresAt:                  time.Now().Add(stepUpChallengeTTL),
+	}
+	err = s.repository.CreateTransferChallenge(ctx, challenge)
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp := SuccessStepUpRequired
+	resp.Data = StepUpChallengeResponse{
+		ChallengeID: challenge.ID,
+		Factors:     factors,
+		ExpiresAt:   challenge.ExpiresAt,
+	}
+	return resp
+}
+
+// authorizeStepUp returns the error response when a payment made without a challenge needs a step-up
+// factor that is missing or wrong.
+func (s *userBalanceService) authorizeStepUp(ctx context.Context, userID, currency string, amount int, factor StepUpFactor) (Response, bool) {
+	err := s.stepUp.Authorize(ctx, userID, currency, amount, factor)
+	if errors.Is(err, ErrStepUpRequired) || errors.Is(err, ErrStepUpFailed) {
+		resp := ErrorForbidden
+		resp.Error = err.Error()
+		return resp, false
+	}
+	if errors.Is(err, ErrStepUpLocked) {
+		resp := ErrorLocked
+		resp.Error = err.Error()
+		return resp, false
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp, false
+	}
+	return Response{}, true
+}
+
+// ConfirmTransaction implements Service.
+func (s *userBalanceService) ConfirmTransaction(ctx context.Context, req ConfirmTransactionPayload) Response {
+	challenge, err := s.repository.GetTransferChallenge(ctx, req.ChallengeID, req.UserID)
+	if errors.Is(err, ErrTransferChallengeNotFound) {
+		resp := ErrorNotFound
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	err = s.stepUp.Verifier.VerifyStepUp(ctx, req.UserID, req.StepUpFactor)
+	if errors.Is(err, ErrStepUpFailed) {
+		failErr := s.repository.FailTransferChallenge(ctx, challenge.ID)
+		if failErr != nil {
+			resp := ErrorInternal
+			resp.Error = failErr.Error()
+			return resp
+		}
+		resp := ErrorForbidden
+		resp.Error = err.Error()
+		return resp
+	}
+	if errors.Is(err, ErrStepUpLocked) {
+		resp := ErrorLocked
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	transfer, err := s.repository.ConfirmTransferChallenge(ctx, challenge)
+	if errors.Is(err, ErrTransferChallengeNotFound) {
+		resp := ErrorNotFound
+		resp.Error = err.Error()
+		return resp
+	}
+	return transferResponse(transfer, err)
+}
+
+// transferResponse maps the outcome of recording an outgoing transfer.
+func transferResponse(transfer *OutgoingTransfer, err error) Response {
 	if errors.Is(err, ErrNotEnoughBalance) {
 		resp := ErrorBadRequest
 		resp.Error = err.Error()
@@ -51,7 +217,28 @@ func (s *userBalanceService) CreateTransaction(ctx context.Context, req CreateTr
 		return resp
 	}
 
-	return SuccessCreateTransaction
+	resp := SuccessCreateTransaction
+	resp.Data = TransferResponse{
+		TransactionID: transfer.TransactionID,
+		Status:        transfer.Status,
+	}
+	return resp
+}
+
+// validateCurrency checks amount against the currency registry, returning the error response if it fails.
+func (s *userBalanceService) validateCurrency(ctx context.Context, code string, amount int) (Response, bool) {
+	err := s.currencyService.ValidateAmount(ctx, code, amount)
+	if errors.Is(err, currency.ErrCurrencyNotSupported) || errors.Is(err, currency.ErrAmountBelowMinimum) || errors.Is(err, currency.ErrAmountAboveMaximum) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp, false
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp, false
+	}
+	return Response{}, true
 }
 
 func (s *userBalanceService) List(ctx context.Context, req ListUserBalancePayload) Response {
@@ -88,24 +275,7 @@ func (s *userBalanceService) ListTransaction(ctx context.Context, req ListUserTr
 	}
 	utResponse := make([]UserTransactionResponse, len(result))
 	for i, ut := range result {
-		imageURL := ""
-		if ut.ImageURL != nil {
-			imageURL = *ut.ImageURL
-		}
-		utResponse[i] = UserTransactionResponse{
-			TransactionID:    ut.TransactionID,
-			Balance:          ut.Amount,
-			Currency:         ut.Currency,
-			TransferProofImg: imageURL,
-			CreatedAt:        ut.CreatedAt.UnixMilli(),
-			Source: struct {
-				BankAccountNumber string "json:\"bankAccountNumber\""
-				BankName          string "json:\"bankName\""
-			}{
-				BankAccountNumber: ut.BankAccountNumber,
-				BankName:          ut.BankName,
-			},
-		}
+		utResponse[i] = ToTransactionResponse(ut)
 	}
 	resp = Success
 	resp.Data = utResponse
@@ -113,3 +283,644 @@ func (s *userBalanceService) ListTransaction(ctx context.Context, req ListUserTr
 
 	return resp
 }
+
+// ToTransactionResponse renders a transaction the way the history endpoint shows it.
+func ToTransactionResponse(ut UserTransaction) UserTransactionResponse {
+	imageURL := ""
+	if ut.ImageURL != nil {
+		imageURL = *ut.ImageURL
+	}
+	var status TransferStatus
+	if ut.TransferStatus != nil {
+		status = *ut.TransferStatus
+	}
+	resp := UserTransactionResponse{
+		TransactionID:    ut.TransactionID,
+		Status:           status,
+		Balance:          ut.Amount,
+		Currency:         ut.Currency,
+		TransferProofImg: imageURL,
+		CreatedAt:        ut.CreatedAt.UnixMilli(),
+	}
+	resp.Source.BankAccountNumber = ut.BankAccountNumber
+	resp.Source.BankName = ut.BankName
+	return resp
+}
+
+// VerifyChain implements Service.
+func (s *userBalanceService) VerifyChain(ctx context.Context, req VerifyChainPayload) Response {
+	var resp Response
+
+	transactions, head, err := s.repository.ListChain(ctx, req.UserID)
+	if err != nil {
+		resp = ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp = Success
+	resp.Data = VerifyTransactionChain(req.UserID, transactions, head)
+
+	return resp
+}
+
+// SetOverdraftLimit implements Service.
+func (s *userBalanceService) SetOverdraftLimit(ctx context.Context, req SetOverdraftLimitPayload) Response {
+	err := s.repository.SetOverdraftLimit(ctx, req)
+	if errors.Is(err, ErrNoCurrencyOrUserRecorded) {
+		resp := ErrorNotFound
+		resp.Error = err.Error()
+		return resp
+	}
+	if errors.Is(err, ErrOverdraftLimitBelowBalance) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	return SuccessSetOverdraftLimit
+}
+
+// ListOverdraft implements Service.
+func (s *userBalanceService) ListOverdraft(ctx context.Context, req ListOverdraftPayload) Response {
+	var resp Response
+
+	result, pagination, err := s.repository.ListOverdrafts(ctx, req)
+	if err != nil {
+		resp = ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+	now := time.Now()
+	oResponse := make([]OverdraftResponse, len(result))
+	for i, o := range result {
+		end := now
+		var endedAt *int64
+		if o.EndedAt != nil {
+			end = *o.EndedAt
+			ms := o.EndedAt.UnixMilli()
+			endedAt = &ms
+		}
+		oResponse[i] = OverdraftResponse{
+			Currency:        o.Currency,
+			LowestBalance:   o.LowestBalance,
+			StartedAt:       o.StartedAt.UnixMilli(),
+			EndedAt:         endedAt,
+			DurationSeconds: int64(end.Sub(o.StartedAt).Seconds()),
+		}
+	}
+	resp = Success
+	resp.Data = oResponse
+	resp.Meta = pagination
+
+	return resp
+}
+
+// ExportTransfers implements Service.
+func (s *userBalanceService) ExportTransfers(ctx context.Context, req ExportTransfersPayload) Response {
+	now := time.Now()
+	messageID := "PAIMON-" + now.UTC().Format("20060102150405") + "-" + id.GenerateStringID(8)
+
+	export, err := s.repository.ExportTransfers(ctx, req.Limit, func(transfers []OutgoingTransfer) (*iso20022.Pain001, error) {
+		creditTransfers := make([]iso20022.CreditTransfer, len(transfers))
+		for i, t := range transfers {
+			creditTransfers[i] = iso20022.CreditTransfer{
+				EndToEndID:        t.TransactionID,
+				Amount:            t.Amount,
+				Currency:          t.Currency,
+				CreditorAccount:   t.BankAccountNumber,
+				CreditorAgentName: t.BankName,
+				RemittanceInfo:    "Paimon transfer " + t.TransactionID,
+			}
+		}
+		return iso20022.NewPain001(messageID, now, pain001Debtor, creditTransfers)
+	})
+	if errors.Is(err, ErrNoTransfersToExport) {
+		resp := ErrorNoRecords
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp := SuccessExportTransfers
+	resp.Data = export
+	return resp
+}
+
+// GetPaymentExport implements Service.
+func (s *userBalanceService) GetPaymentExport(ctx context.Context, req GetPaymentExportPayload) Response {
+	export, err := s.repository.GetPaymentExport(ctx, req.MessageID)
+	if errors.Is(err, ErrPaymentExportNotFound) {
+		resp := ErrorNotFound
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp := Success
+	resp.Data = export
+	return resp
+}
+
+// UpdateTransferStatus implements Service.
+// It settles or fails a transfer whose payout is confirmed outside of the payout gateway, such as an exported one.
+func (s *userBalanceService) UpdateTransferStatus(ctx context.Context, req UpdateTransferStatusPayload) Response {
+	transfer, err := s.repository.GetTransfer(ctx, req.TransactionID)
+	if errors.Is(err, ErrTransferNotFound) {
+		resp := ErrorNotFound
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	switch req.Status {
+	case TransferStatusSettled:
+		err = s.repository.MarkTransferSettled(ctx, transfer.TransactionID)
+	case TransferStatusFailed:
+		if transfer.Status == TransferStatusSettled {
+			err = ErrInvalidTransferTransition
+			break
+		}
+		err = s.repository.FailUnclaimedTransfer(ctx, *transfer, req.Reason)
+	}
+	if errors.Is(err, ErrTransferInProgress) {
+		resp := ErrorConflict
+		resp.Error = err.Error()
+		return resp
+	}
+	if errors.Is(err, ErrInvalidTransferTransition) {
+		resp := ErrorBadRequest
+		resp.Error = fmt.Sprintf("%v: transfer is %s", err, transfer.Status)
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	return SuccessUpdateTransfer
+}
+
+// CreateDeposit implements Service.
+func (s *userBalanceService) CreateDeposit(ctx context.Context, req CreateDepositPayload) Response {
+	if resp, ok := s.validateCurrency(ctx, req.Currency, req.Amount); !ok {
+		return resp
+	}
+
+	deposit := &Deposit{
+		ID:                      id.GenerateStringID(16),
+		Reference:               "PMN" + id.GenerateReference(9),
+		UserID:                  req.UserID,
+		Amount:                  req.Amount,
+		Currency:                req.Currency,
+		SenderBankAccountNumber: req.SenderBankAccountNumber,
+		SenderBankName:          req.SenderBankName,
+		Status:                  DepositStatusPending,
+	}
+	err := s.repository.CreateDeposit(ctx, deposit)
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp := SuccessCreateDeposit
+	resp.Data = toDepositResponse(*deposit)
+	return resp
+}
+
+// ListDeposit implements Service.
+func (s *userBalanceService) ListDeposit(ctx context.Context, req ListDepositPayload) Response {
+	var resp Response
+
+	result, pagination, err := s.repository.ListDeposits(ctx, req)
+	if err != nil {
+		resp = ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+	dResponse := make([]DepositResponse, len(result))
+	for i, d := range result {
+		dResponse[i] = toDepositResponse(d)
+	}
+	resp = Success
+	resp.Data = dResponse
+	resp.Meta = pagination
+
+	return resp
+}
+
+// ImportStatement implements Service.
+// Every credit line is matched against pending deposits, lines without a match stay in the exceptions queue.
+func (s *userBalanceService) ImportStatement(ctx context.Context, req ImportStatementPayload) Response {
+	parsed, err := statement.Parse(req.Format, req.Data)
+	if err != nil {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
+
+	bankStatement := &BankStatement{
+		Format:      string(req.Format),
+		StatementID: parsed.ID,
+		Account:     parsed.Account,
+	}
+	lines := make([]StatementLine, len(parsed.Credits))
+	for i, c := range parsed.Credits {
+		lines[i] = StatementLine{
+			EntryReference: c.EntryReference,
+			Amount:         c.Amount,
+			AmountValid:    c.Valid,
+			Currency:       c.Currency,
+			Reference:      c.Reference,
+			SenderAccount:  c.SenderAccount,
+			SenderName:     c.SenderName,
+		}
+		if !c.BookingDate.IsZero() {
+			bookingDate := c.BookingDate
+			lines[i].BookingDate = &bookingDate
+		}
+	}
+	lines, err = s.repository.ImportStatement(ctx, bankStatement, lines)
+	if errors.Is(err, ErrStatementAlreadyImported) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	result := StatementImportResponse{
+		StatementID: bankStatement.ID,
+		Account:     bankStatement.Account,
+		Credits:     len(lines),
+	}
+	for _, line := range lines {
+		matched, err := s.repository.MatchStatementLine(ctx, line)
+		if err != nil {
+			// the line stays unmatched and shows up in the exceptions queue
+			slog.Error(fmt.Sprintf("Cannot match statement line %d: %v", line.ID, err))
+		}
+		if matched {
+			result.Matched++
+		} else {
+			result.Unmatched++
+		}
+	}
+
+	resp := SuccessImportStatement
+	resp.Data = result
+	return resp
+}
+
+// ListStatementLine implements Service.
+func (s *userBalanceService) ListStatementLine(ctx context.Context, req ListStatementLinePayload) Response {
+	var resp Response
+
+	result, pagination, err := s.repository.ListStatementLines(ctx, req)
+	if err != nil {
+		resp = ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+	lResponse := make([]StatementLineResponse, len(result))
+	for i, l := range result {
+		bookingDate := ""
+		if l.BookingDate != nil {
+			bookingDate = l.BookingDate.Format(time.DateOnly)
+		}
+		lResponse[i] = StatementLineResponse{
+			ID:             l.ID,
+			StatementID:    l.StatementID,
+			EntryReference: l.EntryReference,
+			BookingDate:    bookingDate,
+			Amount:         l.Amount,
+			AmountValid:    l.AmountValid,
+			Currency:       l.Currency,
+			Reference:      l.Reference,
+			SenderAccount:  l.SenderAccount,
+			SenderName:     l.SenderName,
+			Status:         l.Status,
+			DepositID:      l.DepositID,
+			Note:           l.Note,
+			CreatedAt:      l.CreatedAt.UnixMilli(),
+		}
+	}
+	resp = Success
+	resp.Data = lResponse
+	resp.Meta = pagination
+
+	return resp
+}
+
+// ResolveStatementLine implements Service.
+// Operators either match an exception to a pending deposit, crediting the booked amount, or dismiss it.
+func (s *userBalanceService) ResolveStatementLine(ctx context.Context, req ResolveStatementLinePayload) Response {
+	var err error
+	switch req.Action {
+	case "match":
+		err = s.repository.ConfirmDepositWithLine(ctx, req.LineID, req.DepositID)
+	case "dismiss":
+		err = s.repository.DismissStatementLine(ctx, req.LineID, req.Note)
+	}
+	if errors.Is(err, ErrStatementLineNotFound) || errors.Is(err, ErrDepositNotFound) {
+		resp := ErrorNotFound
+		resp.Error = err.Error()
+		return resp
+	}
+	if errors.Is(err, ErrDepositCurrencyMismatch) || errors.Is(err, ErrAccountClosed) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	return SuccessResolveStatement
+}
+
+func toDepositResponse(d Deposit) DepositResponse {
+	var confirmedAt *int64
+	if d.ConfirmedAt != nil {
+		ms := d.ConfirmedAt.UnixMilli()
+		confirmedAt = &ms
+	}
+	return DepositResponse{
+		DepositID:               d.ID,
+		Reference:               d.Reference,
+		Amount:                  d.Amount,
+		Currency:                d.Currency,
+		SenderBankAccountNumber: d.SenderBankAccountNumber,
+		SenderBankName:          d.SenderBankName,
+		Status:                  d.Status,
+		TransactionID:           d.TransactionID,
+		CreatedAt:               d.CreatedAt.UnixMilli(),
+		ConfirmedAt:             confirmedAt,
+	}
+}
+
+// CreateVirtualAccount implements Service.
+func (s *userBalanceService) CreateVirtualAccount(ctx context.Context, req CreateVirtualAccountPayload) Response {
+	err := s.currencyService.ValidateCode(ctx, req.Currency)
+	if errors.Is(err, currency.ErrCurrencyNotSupported) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	va := &VirtualAccount{
+		UserID:   req.UserID,
+		Currency: req.Currency,
+	}
+	// random numbers can collide with an existing account, draw a new one when they do
+	for attempt := 0; attempt < virtualAccountAttempts; attempt++ {
+		va.AccountNumber, err = generateVirtualAccountNumber()
+		if err != nil {
+			break
+		}
+		err = s.repository.CreateVirtualAccount(ctx, va, maxVirtualAccountsPerCurrency)
+		if !errors.Is(err, ErrVirtualAccountExists) {
+			break
+		}
+	}
+	if errors.Is(err, ErrVirtualAccountLimit) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp := SuccessCreateVA
+	resp.Data = VirtualAccountResponse{
+		AccountNumber: va.AccountNumber,
+		Currency:      va.Currency,
+		CreatedAt:     va.CreatedAt.UnixMilli(),
+	}
+	return resp
+}
+
+// ListVirtualAccount implements Service.
+func (s *userBalanceService) ListVirtualAccount(ctx context.Context, req ListVirtualAccountPayload) Response {
+	var resp Response
+
+	result, err := s.repository.ListVirtualAccounts(ctx, req.UserID)
+	if err != nil {
+		resp = ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+	vaResponse := make([]VirtualAccountResponse, len(result))
+	for i, va := range result {
+		vaResponse[i] = VirtualAccountResponse{
+			AccountNumber: va.AccountNumber,
+			Currency:      va.Currency,
+			CreatedAt:     va.CreatedAt.UnixMilli(),
+		}
+	}
+	resp = Success
+	resp.Data = vaResponse
+
+	return resp
+}
+
+// ReceiveInboundTransfer implements Service.
+// It simulates the partner bank notifying a transfer to a virtual account and credits the owner as a regular deposit.
+func (s *userBalanceService) ReceiveInboundTransfer(ctx context.Context, req InboundTransferPayload) Response {
+	if !validVirtualAccountNumber(req.VirtualAccountNumber) {
+		resp := ErrorBadRequest
+		resp.Error = ErrVirtualAccountInvalid.Error()
+		return resp
+	}
+	va, err := s.repository.GetVirtualAccount(ctx, req.VirtualAccountNumber)
+	if errors.Is(err, ErrVirtualAccountNotFound) {
+		resp := ErrorNotFound
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp := s.Create(ctx, CreateUserBalancePayload{
+		SenderBankAccountNumber: req.SenderBankAccountNumber,
+		SenderBankName:          req.SenderBankName,
+		AddedBalance:            req.Amount,
+		Currency:                va.Currency,
+		UserID:                  va.UserID,
+	})
+	if resp.Error != "" {
+		return resp
+	}
+
+	resp = SuccessInboundTransfer
+	resp.Data = InboundTransferResponse{
+		VirtualAccountNumber: va.AccountNumber,
+		Amount:               req.Amount,
+		Currency:             va.Currency,
+	}
+	return resp
+}
+
+// GenerateQR implements Service.
+// The payload is static when no amount is given, letting the payer choose how much to send.
+func (s *userBalanceService) GenerateQR(ctx context.Context, req GenerateQRPayload) Response {
+	if req.Amount > 0 {
+		if resp, ok := s.validateCurrency(ctx, req.Currency, req.Amount); !ok {
+			return resp
+		}
+	}
+
+	name, err := s.repository.GetAccountName(ctx, req.UserID)
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	city := qrisMerchantCity
+	if city == "" {
+		city = "JAKARTA"
+	}
+	payload := qris.Payload{
+		Dynamic:      req.Amount > 0,
+		AccountID:    req.UserID,
+		MerchantName: name,
+		MerchantCity: city,
+		Currency:     req.Currency,
+		Amount:       req.Amount,
+		Reference:    req.Reference,
+	}
+	encoded, err := qris.Encode(payload)
+	if errors.Is(err, qris.ErrUnsupportedCurrency) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	qrResponse := QRResponse{
+		Payload:  encoded,
+		Dynamic:  payload.Dynamic,
+		Currency: payload.Currency,
+	}
+	if payload.Dynamic {
+		qrResponse.Amount = &payload.Amount
+	}
+	resp := Success
+	resp.Data = qrResponse
+	return resp
+}
+
+// PayQR implements Service.
+// The payload must name a Paimon account; the transfer is recorded like any other transfer between users.
+func (s *userBalanceService) PayQR(ctx context.Context, req PayQRPayload) Response {
+	payload, err := qris.Decode(req.Payload)
+	if err != nil {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
+
+	amount := payload.Amount
+	if !payload.Dynamic {
+		if req.Amount == 0 {
+			resp := ErrorBadRequest
+			resp.Error = ErrQRAmountRequired.Error()
+			return resp
+		}
+		amount = req.Amount
+	} else if req.Amount != 0 && req.Amount != payload.Amount {
+		resp := ErrorBadRequest
+		resp.Error = ErrQRAmountMismatch.Error()
+		return resp
+	}
+	if resp, ok := s.validateCurrency(ctx, payload.Currency, amount); !ok {
+		return resp
+	}
+	if resp, ok := s.verifyPIN(ctx, req.UserID, req.PIN); !ok {
+		return resp
+	}
+	if resp, ok := s.authorizeStepUp(ctx, req.UserID, payload.Currency, amount, req.StepUpFactor); !ok {
+		return resp
+	}
+
+	name, err := s.repository.GetAccountName(ctx, payload.AccountID)
+	if errors.Is(err, ErrNoCurrencyOrUserRecorded) {
+		resp := ErrorNotFound
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	transfer := &InternalTransfer{
+		FromUserID: req.UserID,
+		ToUserID:   payload.AccountID,
+		Amount:     amount,
+		Currency:   payload.Currency,
+	}
+	err = s.repository.RecordInternalTransfer(ctx, transfer)
+	if errors.Is(err, ErrNotEnoughBalance) || errors.Is(err, ErrNoCurrencyOrUserRecorded) || errors.Is(err, ErrSelfTransfer) || errors.Is(err, ErrAccountClosed) {
+		resp := ErrorBadRequest
+		resp.Error = err.Error()
+		return resp
+	}
+	if err != nil {
+		resp := ErrorInternal
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp := SuccessPayQR
+	resp.Data = QRPaymentResponse{
+		TransactionID: transfer.DebitTransactionID,
+		RecipientID:   payload.AccountID,
+		RecipientName: name,
+		Amount:        amount,
+		Currency:      payload.Currency,
+		Reference:     payload.Reference,
+	}
+	return resp
+}
@@ -0,0 +1,212 @@
+package userbalance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// stepUpChallengeTTL is how long the client has to confirm a challenged transfer.
+	stepUpChallengeTTL = 5 * time.Minute
+	stepUpMaxAttempts  = 5
+
+	StepUpFactorPassword = "password"
+	StepUpFactorOTP      = "otp"
+)
+
+// StepUpFactor is the fresh proof of identity confirming a challenged transfer: the account
+// password, or a code from the authenticator app or one of its recovery codes.
+type StepUpFactor struct {
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// given counts the factors filled in.
+func (f StepUpFactor) given() int {
+	given := 0
+	for _, factor := range []string{f.Password, f.Code, f.RecoveryCode} {
+		if factor != "" {
+			given++
+		}
+	}
+	return given
+}
+
+// Validate allows at most one factor; payments below the step-up threshold need none.
+func (f StepUpFactor) Validate() error {
+	if f.given() > 1 {
+		return errors.New("only one of password, code or recoveryCode can be given")
+	}
+	return nil
+}
+
+// StepUpVerifier checks step-up factors. It is implemented by the user domain, which owns the credentials.
+type StepUpVerifier interface {
+	// StepUpFactors lists the factors the user can confirm a challenge with.
+	StepUpFactors(ctx context.Context, userID string) ([]string, error)
+	// VerifyStepUp returns an error wrapping ErrStepUpFailed when the factor is wrong, and one wrapping
+	// ErrStepUpLocked while too many wrong factors keep the user from trying again.
+	VerifyStepUp(ctx context.Context, userID string, factor StepUpFactor) error
+}
+
+// StepUpPolicy holds the amount per currency from which a transfer must be confirmed with a
+// step-up factor. Currencies without a threshold never need one.
+type StepUpPolicy struct {
+	Thresholds map[string]int
+	Verifier   StepUpVerifier
+}
+
+func (p StepUpPolicy) required(currency string, amount int) bool {
+	threshold, ok := p.Thresholds[currency]
+	return ok && amount >= threshold
+}
+
+// Authorize checks the factor sent along with a payment that is not held as a transfer challenge,
+// such as a QR payment or settling a split bill. Below the threshold it accepts anything. At or above
+// it, a missing factor is reported as ErrStepUpRequired naming the factors the user can send, a
+// wrong one as ErrStepUpFailed and one sent while locked out as ErrStepUpLocked.
+func (p StepUpPolicy) Authorize(ctx context.Context, userID, currency string, amount int, factor StepUpFactor) error {
+	if !p.required(currency, amount) {
+		return nil
+	}
+	if factor.given() == 0 {
+		factors, err := p.Verifier.StepUpFactors(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: send one of %s", ErrStepUpRequired, strings.Join(factors, ", "))
+	}
+	return p.Verifier.VerifyStepUp(ctx, userID, factor)
+}
+
+// ParseStepUpThresholds reads thresholds written as "IDR:10000000,USD:1000".
+func ParseStepUpThresholds(s string) (map[string]int, error) {
+	thresholds := map[string]int{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		currency, amount, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("step-up threshold %q is not CURRENCY:AMOUNT", entry)
+		}
+		threshold, err := strconv.Atoi(strings.TrimSpace(amount))
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("step-up threshold %q must be a positive amount", entry)
+		}
+		thresholds[strings.ToUpper(strings.TrimSpace(currency))] = threshold
+	}
+	return thresholds, nil
+}
+
+// TransferChallenge holds a transfer at or above the step-up threshold until it is confirmed.
+// Confirming it records the transfer exactly as CreateTransaction would have.
+type TransferChallenge struct {
+	ID                         string
+	UserID                     string
+	Amount                     int
+	Currency                   string
+	RecipientBankAccountNumber string
+	RecipientBankName          string
+	Attempts                   int
+	ExpiresAt                  time.Time
+}
+
+func (c TransferChallenge) payload() CreateTransactionPayload {
+	return CreateTransactionPayload{
+		RecipientBankAccountNumber: c.RecipientBankAccountNumber,
+		RecipientBankName:          c.RecipientBankName,
+		Balances:                   c.Amount,
+		FromCurrency:               c.Currency,
+		UserID:                     c.UserID,
+	}
+}
+
+// CreateTransferChallenge implements Repository.
+func (d *dbRepository) CreateTransferChallenge(ctx context.Context, c *TransferChallenge) error {
+	createChallengeQuery := `
+		INSERT INTO transfer_challenges (
+			id, user_id, amount, currency, bank_account_number, bank_name, expires_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+	`
+	_, err := d.db.DB().ExecContext(ctx, createChallengeQuery, c.ID, c.UserID, c.Amount, c.Currency, c.RecipientBankAccountNumber, c.RecipientBankName, c.ExpiresAt)
+	return err
+}
+
+// GetTransferChallenge implements Repository.
+// Confirmed, expired and exhausted challenges are reported as ErrTransferChallengeNotFound.
+func (d *dbRepository) GetTransferChallenge(ctx context.Context, id, userID string) (*TransferChallenge, error) {
+	getChallengeQuery := `
+		SELECT id, user_id, amount, currency, bank_account_number, bank_name, attempts, expires_at
+		FROM transfer_challenges
+		WHERE id = $1 AND user_id = $2
+			AND confirmed_at IS NULL AND expires_at > current_timestamp AND attempts < $3
+	`
+	c := &TransferChallenge{}
+	err := d.db.DB().QueryRowContext(ctx, getChallengeQuery, id, userID, stepUpMaxAttempts).Scan(
+		&c.ID, &c.UserID, &c.Amount, &c.Currency, &c.RecipientBankAccountNumber, &c.RecipientBankName, &c.Attempts, &c.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTransferChallengeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// FailTransferChallenge implements Repository.
+func (d *dbRepository) FailTransferChallenge(ctx context.Context, id string) error {
+	failChallengeQuery := `
+		UPDATE transfer_challenges
+		SET attempts = attempts + 1
+		WHERE id = $1
+	`
+	_, err := d.db.DB().ExecContext(ctx, failChallengeQuery, id)
+	return err
+}
+
+// ConfirmTransferChallenge implements Repository.
+// It marks the challenge confirmed and records its transfer in one transaction, so a challenge
+// confirmed twice concurrently moves money once.
+func (d *dbRepository) ConfirmTransferChallenge(ctx context.Context, c *TransferChallenge) (*OutgoingTransfer, error) {
+	var transfer *OutgoingTransfer
+	err := d.db.StartTx(ctx, func(tx *sql.Tx) error {
+		confirmChallengeQuery := `
+			UPDATE transfer_challenges
+			SET confirmed_at = current_timestamp
+			WHERE id = $1 AND confirmed_at IS NULL AND expires_at > current_timestamp
+		`
+		res, err := tx.ExecContext(ctx, confirmChallengeQuery, c.ID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrTransferChallengeNotFound
+		}
+
+		transfer, err = recordTransaction(ctx, tx, c.payload())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE transfer_challenges SET transaction_id = $2 WHERE id = $1`, c.ID, transfer.TransactionID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
@@ -0,0 +1,89 @@
+package userbalance
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseStepUpThresholds(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    map[string]int
+		wantErr bool
+	}{
+		{name: "empty", s: "", want: map[string]int{}},
+		{name: "one currency", s: "IDR:10000000", want: map[string]int{"IDR": 10000000}},
+		{name: "several currencies", s: "IDR:10000000,USD:1000", want: map[string]int{"IDR": 10000000, "USD": 1000}},
+		{name: "spaces and lowercase", s: " idr : 500 , usd:1 ", want: map[string]int{"IDR": 500, "USD": 1}},
+		{name: "empty entries", s: ",IDR:500,,", want: map[string]int{"IDR": 500}},
+		{name: "missing amount", s: "IDR", wantErr: true},
+		{name: "not a number", s: "IDR:ten", wantErr: true},
+		{name: "zero", s: "IDR:0", wantErr: true},
+		{name: "negative", s: "IDR:-5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStepUpThresholds(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStepUpThresholds(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStepUpThresholds(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+// passwordVerifier accepts only the password "correct".
+type passwordVerifier struct {
+	verified int
+}
+
+func (v *passwordVerifier) StepUpFactors(ctx context.Context, userID string) ([]string, error) {
+	return []string{StepUpFactorPassword}, nil
+}
+
+func (v *passwordVerifier) VerifyStepUp(ctx context.Context, userID string, factor StepUpFactor) error {
+	v.verified++
+	if factor.Password != "correct" {
+		return ErrStepUpFailed
+	}
+	return nil
+}
+
+func TestStepUpPolicyAuthorize(t *testing.T) {
+	tests := []struct {
+		name         string
+		currency     string
+		amount       int
+		factor       StepUpFactor
+		wantErr      error
+		wantVerified int
+	}{
+		{name: "below the threshold", currency: "IDR", amount: 999},
+		{name: "below the threshold ignores the factor", currency: "IDR", amount: 999, factor: StepUpFactor{Password: "wrong"}},
+		{name: "currency without threshold", currency: "USD", amount: 1000000},
+		{name: "at the threshold without factor", currency: "IDR", amount: 1000, wantErr: ErrStepUpRequired},
+		{name: "above the threshold with wrong factor", currency: "IDR", amount: 5000, factor: StepUpFactor{Password: "wrong"}, wantErr: ErrStepUpFailed, wantVerified: 1},
+		{name: "above the threshold with factor", currency: "IDR", amount: 5000, factor: StepUpFactor{Password: "correct"}, wantVerified: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := &passwordVerifier{}
+			policy := StepUpPolicy{Thresholds: map[string]int{"IDR": 1000}, Verifier: verifier}
+
+			err := policy.Authorize(context.Background(), "1", tt.currency, tt.amount, tt.factor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authorize() error = %v, want %v", err, tt.wantErr)
+			}
+			if verifier.verified != tt.wantVerified {
+				t.Errorf("VerifyStepUp called %d times, want %d", verifier.verified, tt.wantVerified)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package userbalance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/payout"
+)
+
+const (
+	transferBatchSize = 50
+	transferLease     = time.Minute
+)
+
+// TransferProcessor moves outgoing transfers through their lifecycle:
+// pending transfers are submitted to the payout gateway, submitted ones are polled
+// until they settle or fail, and failed ones are refunded. Only transfers the gateway
+// rejects or reports as failed are refunded, any other error is retried.
+type TransferProcessor struct {
+	repository Repository
+	gateway    payout.PayoutGateway
+	interval   time.Duration
+}
+
+func NewTransferProcessor(repository Repository, gateway payout.PayoutGateway, interval time.Duration) *TransferProcessor {
+	return &TransferProcessor{repository: repository, gateway: gateway, interval: interval}
+}
+
+// Run processes transfers every interval until ctx is cancelled.
+func (p *TransferProcessor) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.submitPending(ctx)
+			p.pollSubmitted(ctx)
+		}
+	}
+}
+
+func (p *TransferProcessor) submitPending(ctx context.Context) {
+	transfers, err := p.repository.ClaimTransfers(ctx, TransferStatusPending, transferBatchSize, transferLease)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot claim pending transfers: %v", err))
+		return
+	}
+	for _, t := range transfers {
+		reference, err := p.gateway.Submit(ctx, payout.Instruction{
+			TransactionID:     t.TransactionID,
+			Amount:            t.Amount,
+			Currency:          t.Currency,
+			BankAccountNumber: t.BankAccountNumber,
+			BankName:          t.BankName,
+		})
+		if errors.Is(err, payout.ErrRejected) {
+			p.fail(ctx, t, fmt.Sprintf("submission rejected: %v", err))
+			continue
+		}
+		if err != nil {
+			// leave it pending, it is submitted again under the same transaction ID once the lease expires
+			slog.Error(fmt.Sprintf("Cannot submit transfer %s: %v", t.TransactionID, err))
+			continue
+		}
+		err = p.repository.MarkTransferSubmitted(ctx, t.TransactionID, reference)
+		if err != nil {
+			// submitting is idempotent, so the retry gets the same reference back
+			slog.Error(fmt.Sprintf("Cannot mark transfer %s as submitted: %v", t.TransactionID, err))
+		}
+	}
+}
+
+func (p *TransferProcessor) pollSubmitted(ctx context.Context) {
+	transfers, err := p.repository.ClaimTransfers(ctx, TransferStatusSubmitted, transferBatchSize, transferLease)
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot claim submitted transfers: %v", err))
+		return
+	}
+	for _, t := range transfers {
+		if t.GatewayReference == nil {
+			p.fail(ctx, t, "missing gateway reference")
+			continue
+		}
+		result, err := p.gateway.Status(ctx, *t.GatewayReference)
+		if err != nil {
+			// leave it submitted, it is polled again once the lease expires
+			slog.Error(fmt.Sprintf("Cannot get payout status of transfer %s: %v", t.TransactionID, err))
+			continue
+		}
+		switch result.Status {
+		case payout.StatusSettled:
+			err = p.repository.MarkTransferSettled(ctx, t.TransactionID)
+			if err != nil {
+				slog.Error(fmt.Sprintf("Cannot mark transfer %s as settled: %v", t.TransactionID, err))
+			}
+		case payout.StatusFailed:
+			p.fail(ctx, t, result.Reason)
+		}
+	}
+}
+
+func (p *TransferProcessor) fail(ctx context.Context, t OutgoingTransfer, reason string) {
+	err := p.repository.FailTransfer(ctx, t, reason)
+	if errors.Is(err, ErrInvalidTransferTransition) {
+		return
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("Cannot refund failed transfer %s: %v", t.TransactionID, err))
+		return
+	}
+	slog.Info(fmt.Sprintf("Transfer %s failed and was refunded: %s", t.TransactionID, reason))
+}
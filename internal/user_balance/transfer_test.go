@@ -0,0 +1,106 @@
+package userbalance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/citadel-corp/paimon-bank/internal/payout"
+)
+
+// transferRepository records the transitions the processor makes.
+// Methods the processor does not call are left to the embedded nil Repository.
+type transferRepository struct {
+	Repository
+	pending   []OutgoingTransfer
+	submitted map[string]string
+	failed    map[string]string
+	markErr   error
+}
+
+func (r *transferRepository) ClaimTransfers(ctx context.Context, status TransferStatus, limit int, lease time.Duration) ([]OutgoingTransfer, error) {
+	if status != TransferStatusPending {
+		return nil, nil
+	}
+	return r.pending, nil
+}
+
+func (r *transferRepository) MarkTransferSubmitted(ctx context.Context, transactionID, gatewayReference string) error {
+	if r.markErr != nil {
+		return r.markErr
+	}
+	r.submitted[transactionID] = gatewayReference
+	return nil
+}
+
+func (r *transferRepository) FailTransfer(ctx context.Context, transfer OutgoingTransfer, reason string) error {
+	r.failed[transfer.TransactionID] = reason
+	return nil
+}
+
+type submitGateway struct {
+	payout.PayoutGateway
+	err error
+}
+
+func (g submitGateway) Submit(ctx context.Context, instruction payout.Instruction) (string, error) {
+	if g.err != nil {
+		return "", g.err
+	}
+	return "REF-" + instruction.TransactionID, nil
+}
+
+func TestTransferProcessorSubmitPending(t *testing.T) {
+	tests := []struct {
+		name          string
+		submitErr     error
+		markErr       error
+		wantSubmitted bool
+		wantFailed    bool
+	}{
+		{
+			name:          "accepted",
+			wantSubmitted: true,
+		},
+		{
+			name:       "rejected",
+			submitErr:  fmt.Errorf("%w: unknown bank", payout.ErrRejected),
+			wantFailed: true,
+		},
+		{
+			name:      "gateway unreachable",
+			submitErr: errors.New("connection refused"),
+		},
+		{
+			name:      "timeout",
+			submitErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "accepted but not marked",
+			markErr: errors.New("database is down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &transferRepository{
+				pending:   []OutgoingTransfer{{TransactionID: "tx1", Status: TransferStatusPending}},
+				submitted: make(map[string]string),
+				failed:    make(map[string]string),
+				markErr:   tt.markErr,
+			}
+			processor := NewTransferProcessor(repository, submitGateway{err: tt.submitErr}, time.Second)
+
+			processor.submitPending(context.Background())
+
+			if _, ok := repository.submitted["tx1"]; ok != tt.wantSubmitted {
+				t.Errorf("submitted = %v, want %v", ok, tt.wantSubmitted)
+			}
+			if _, ok := repository.failed["tx1"]; ok != tt.wantFailed {
+				t.Errorf("failed = %v, want %v", ok, tt.wantFailed)
+			}
+		})
+	}
+}
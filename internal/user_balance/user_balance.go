@@ -3,11 +3,12 @@ package userbalance
 import "time"
 
 type UserBalance struct {
-	ID        uint64     `json:"-"`
-	Balance   int        `json:"balance"`
-	Currency  string     `json:"currency"`
-	UserID    uint64     `json:"user_id"`
-	CreatedAt *time.Time `json:"created_at"`
+	ID             uint64     `json:"-"`
+	Balance        int        `json:"balance"`
+	Currency       string     `json:"currency"`
+	OverdraftLimit int        `json:"overdraft_limit"`
+	UserID         uint64     `json:"user_id"`
+	CreatedAt      *time.Time `json:"created_at"`
 }
 
 type UserTransaction struct {
@@ -19,4 +20,126 @@ type UserTransaction struct {
 	BankName          string
 	ImageURL          *string
 	CreatedAt         time.Time
+	TransferStatus    *TransferStatus
+	ChainSeq          *int64
+	PrevHash          *string
+	Hash              *string
+}
+
+// ChainHead is the latest link of a user's transaction chain, authenticated by Anchor.
+type ChainHead struct {
+	UserID   string
+	ChainSeq int64
+	Hash     string
+	Anchor   string
+}
+
+type Overdraft struct {
+	ID            uint64
+	UserID        string
+	Currency      string
+	LowestBalance int
+	StartedAt     time.Time
+	EndedAt       *time.Time
+}
+
+type TransferStatus string
+
+const (
+	TransferStatusPending   TransferStatus = "pending"
+	TransferStatusSubmitted TransferStatus = "submitted"
+	TransferStatusSettled   TransferStatus = "settled"
+	TransferStatusFailed    TransferStatus = "failed"
+)
+
+// OutgoingTransfer tracks the payout of a debit transaction to an external bank account.
+type OutgoingTransfer struct {
+	TransactionID       string
+	UserID              string
+	Amount              int
+	Currency            string
+	BankAccountNumber   string
+	BankName            string
+	Status              TransferStatus
+	GatewayReference    *string
+	FailureReason       *string
+	RefundTransactionID *string
+	ExportID            *uint64
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// PaymentExport is a pain.001 file of outgoing transfers handed to the partner bank.
+type PaymentExport struct {
+	ID                   uint64
+	MessageID            string
+	NumberOfTransactions int
+	ControlSum           int
+	Document             []byte
+	CreatedAt            time.Time
+}
+
+type DepositStatus string
+
+const (
+	DepositStatusPending   DepositStatus = "pending"
+	DepositStatusConfirmed DepositStatus = "confirmed"
+)
+
+// Deposit is a bank transfer the user announced, credited once it shows up on a bank statement.
+type Deposit struct {
+	ID                      string
+	Reference               string
+	UserID                  string
+	Amount                  int
+	Currency                string
+	SenderBankAccountNumber string
+	SenderBankName          string
+	Status                  DepositStatus
+	TransactionID           *string
+	CreatedAt               time.Time
+	ConfirmedAt             *time.Time
+}
+
+type StatementLineStatus string
+
+const (
+	StatementLineStatusUnmatched StatementLineStatus = "unmatched"
+	StatementLineStatusMatched   StatementLineStatus = "matched"
+	StatementLineStatusDismissed StatementLineStatus = "dismissed"
+)
+
+type BankStatement struct {
+	ID          uint64
+	Format      string
+	StatementID string
+	Account     string
+	ImportedAt  time.Time
+}
+
+// StatementLine is a credit entry of an imported bank statement. Unmatched lines form the exceptions queue.
+type StatementLine struct {
+	ID             uint64
+	StatementID    uint64
+	EntryReference string
+	BookingDate    *time.Time
+	Amount         int
+	AmountValid    bool
+	Currency       string
+	Reference      string
+	SenderAccount  string
+	SenderName     string
+	Status         StatementLineStatus
+	DepositID      *string
+	Note           *string
+	CreatedAt      time.Time
+	ResolvedAt     *time.Time
+}
+
+// VirtualAccount is an account number customers can transfer to, crediting the owning user in its currency.
+type VirtualAccount struct {
+	AccountNumber string
+	UserID        string
+	Currency      string
+	CreatedAt     time.Time
 }
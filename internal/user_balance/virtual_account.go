@@ -0,0 +1,83 @@
+package userbalance
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// maxVirtualAccountsPerCurrency caps how many virtual accounts a user can hold per currency.
+	maxVirtualAccountsPerCurrency = 5
+	virtualAccountRandomDigits    = 9
+	// maxVirtualAccountPrefixDigits keeps generated numbers within the 20 characters of account_number.
+	maxVirtualAccountPrefixDigits = 10
+	// virtualAccountAttempts is how many numbers are generated before giving up on collisions.
+	virtualAccountAttempts = 5
+)
+
+var (
+	virtualAccountPrefix string
+)
+
+// UseVirtualAccountPrefix sets the bank prefix of generated virtual account numbers.
+// It must be empty or up to 10 digits.
+func UseVirtualAccountPrefix(prefix string) error {
+	if len(prefix) > maxVirtualAccountPrefixDigits {
+		return fmt.Errorf("prefix %q is longer than %d digits", prefix, maxVirtualAccountPrefixDigits)
+	}
+	for _, r := range prefix {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("prefix %q must only contain digits", prefix)
+		}
+	}
+	virtualAccountPrefix = prefix
+	return nil
+}
+
+// generateVirtualAccountNumber returns the bank prefix followed by random digits and a Luhn check digit.
+func generateVirtualAccountNumber() (string, error) {
+	var sb strings.Builder
+	sb.WriteString(virtualAccountPrefix)
+	for i := 0; i < virtualAccountRandomDigits; i++ {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(byte('0' + n.Int64()))
+	}
+	payload := sb.String()
+	return payload + string(luhnCheckDigit(payload)), nil
+}
+
+// validVirtualAccountNumber reports whether number is all digits and its last digit is a valid Luhn check digit.
+func validVirtualAccountNumber(number string) bool {
+	if len(number) < 2 {
+		return false
+	}
+	for _, r := range number {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return luhnCheckDigit(number[:len(number)-1]) == number[len(number)-1]
+}
+
+// luhnCheckDigit computes the Luhn check digit for a string of digits.
+func luhnCheckDigit(digits string) byte {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
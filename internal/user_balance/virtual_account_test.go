@@ -0,0 +1,93 @@
+package userbalance
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLuhnCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{digits: "7992739871", want: '3'},
+		{digits: "411111111111111", want: '1'},
+		{digits: "0", want: '0'},
+		{digits: "1", want: '8'},
+		{digits: "", want: '0'},
+		{digits: "8808123456789", want: '4'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.digits, func(t *testing.T) {
+			if got := luhnCheckDigit(tt.digits); got != tt.want {
+				t.Errorf("luhnCheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidVirtualAccountNumber(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{number: "79927398713", want: true},
+		{number: "4111111111111111", want: true},
+		{number: "79927398710", want: false},
+		{number: "97927398713", want: false},
+		{number: "7992739871a", want: false},
+		{number: "7992-7398713", want: false},
+		{number: "0", want: false},
+		{number: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if got := validVirtualAccountNumber(tt.number); got != tt.want {
+				t.Errorf("validVirtualAccountNumber(%q) = %v, want %v", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseVirtualAccountPrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		wantErr bool
+	}{
+		{prefix: ""},
+		{prefix: "8808"},
+		{prefix: "1234567890"},
+		{prefix: "12345678901", wantErr: true},
+		{prefix: "88O8", wantErr: true},
+		{prefix: "-880", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			t.Cleanup(func() { virtualAccountPrefix = "" })
+			err := UseVirtualAccountPrefix(tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UseVirtualAccountPrefix(%q) error = %v, want error %v", tt.prefix, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			number, err := generateVirtualAccountNumber()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(number, tt.prefix) {
+				t.Errorf("generated %q does not start with %q", number, tt.prefix)
+			}
+			if len(number) != len(tt.prefix)+virtualAccountRandomDigits+1 || len(number) > 20 {
+				t.Errorf("generated %q has length %d", number, len(number))
+			}
+			if !validVirtualAccountNumber(number) {
+				t.Errorf("generated %q has an invalid check digit", number)
+			}
+		})
+	}
+}